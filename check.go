@@ -0,0 +1,113 @@
+package main
+
+import (
+	"article-management-service/pkg/authz"
+	db "article-management-service/pkg/db"
+	"article-management-service/pkg/env"
+	"article-management-service/pkg/ratelimit"
+	"article-management-service/pkg/server"
+	"article-management-service/pkg/tenant"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Validates the config like serve does on startup and connects to the db; prints one line per check
+func check(cfg *env.Config, logger *logrus.Logger, args []string) error {
+	if err := flag.NewFlagSet("check", flag.ExitOnError).Parse(args); err != nil {
+		return err
+	}
+
+	var conn *db.Connection
+	checks := []struct {
+		name string
+		run  func() error
+	}{
+		{"expiry policy", func() error {
+			if !db.IsValidExpiryPolicy(cfg.ExpiryPolicy) {
+				return fmt.Errorf("invalid expiry policy %q", cfg.ExpiryPolicy)
+			}
+			return nil
+		}},
+		{"tenant quotas", func() error {
+			_, err := tenant.ParseQuotas(tenant.Quota{MaxArticles: cfg.TenantMaxArticles, MaxImages: cfg.TenantMaxImages}, cfg.TenantQuotas)
+			return err
+		}},
+		{"rate limit", func() error {
+			if cfg.RateLimitStore != "memory" && cfg.RateLimitStore != "mongo" {
+				return fmt.Errorf("unknown store %q", cfg.RateLimitStore)
+			}
+			if cfg.RateLimitKeyBy != ratelimit.KeyByIp && cfg.RateLimitKeyBy != ratelimit.KeyByIdentity && cfg.RateLimitKeyBy != ratelimit.KeyByApiKey {
+				return fmt.Errorf("unknown key %q", cfg.RateLimitKeyBy)
+			}
+			return nil
+		}},
+		{"tls", func() error {
+			if _, err := server.ParseClientAuth(cfg.TLSClientAuth); err != nil {
+				return err
+			}
+			if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+				return nil
+			}
+			if _, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
+				return err
+			}
+			if cfg.TLSClientCAFile != "" {
+				if _, err := os.ReadFile(cfg.TLSClientCAFile); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"db", func() error {
+			if cfg.MongoUri == "" {
+				// serve hosts a memory db
+				_, err := os.Stat(cfg.MongodPath)
+				return err
+			}
+			var err error
+			conn, err = connect(cfg, logger)
+			return err
+		}},
+		{"auth", func() error {
+			if !cfg.AuthEnabled {
+				return nil
+			}
+			for _, role := range cfg.AuthDefaultRoles {
+				if !authz.IsValidRole(role) {
+					return fmt.Errorf("invalid default role %q", role)
+				}
+			}
+			if conn == nil {
+				if cfg.AuthApiKeysEnabled {
+					return errors.New("api keys need a connection to MONGO_URI")
+				}
+				conn = &db.Connection{}
+			}
+			_, err := newAuthenticator(cfg.AuthApiKeysEnabled, cfg.JWTHMACSecret, cfg.JWTRSAPublicKeyFile, cfg.JWTIssuer, cfg.JWTAudience, *conn, logger)
+			return err
+		}},
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if err := c.run(); err != nil {
+			failed++
+			fmt.Printf("%-14s failed: %v\n", c.name, err)
+			continue
+		}
+		fmt.Printf("%-14s ok\n", c.name)
+	}
+	if conn != nil && conn.Close != nil {
+		conn.Close()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
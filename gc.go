@@ -0,0 +1,41 @@
+package main
+
+import (
+	db "article-management-service/pkg/db"
+	"article-management-service/pkg/env"
+	"article-management-service/pkg/jobs"
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Removes the images of the image directory that no article references and prints their paths
+func collectImages(cfg *env.Config, logger *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("gc", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only print the orphaned images")
+	minAge := flags.Duration("min-age", time.Hour, "keep younger images, as uploads save the image before the article references it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	conn, err := connect(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	dbHandler := &db.ArticleDbHandler{Logger: logger}
+	if err := dbHandler.New(conn.Database); err != nil {
+		return err
+	}
+
+	collector := &jobs.ImageCollector{ArticleDbHandler: dbHandler, ImageDirectory: cfg.ImageDirectory, MinAge: *minAge, Logger: logger}
+	orphans, err := collector.Collect(context.Background(), *dryRun)
+	for _, path := range orphans {
+		fmt.Println(path)
+	}
+	return err
+}
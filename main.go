@@ -1,56 +1,122 @@
 package main
 
 import (
-	"article-management-service/pkg/controller"
+	"article-management-service/pkg/auth"
 	db "article-management-service/pkg/db"
 	"article-management-service/pkg/env"
-	"article-management-service/pkg/router"
+	"article-management-service/pkg/logging"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
 )
 
+// A subcommand of the binary; every command gets the config of the environment and its remaining arguments
+type command struct {
+	name    string
+	summary string
+	run     func(cfg *env.Config, logger *logrus.Logger, args []string) error
+}
+
+var commands = []command{
+	{name: "serve", summary: "serve the APIs and run the background jobs (default)", run: serve},
+	{name: "migrate", summary: "apply or revert the versioned schema migrations", run: migrate},
+	{name: "import", summary: "import articles and images from an NDJSON, CSV or ZIP file", run: importArticles},
+	{name: "export", summary: "export articles and images to an NDJSON, CSV or ZIP file", run: exportArticles},
+	{name: "gc", summary: "remove images that no article references", run: collectImages},
+	{name: "check", summary: "validate the config and the connection to the db", run: check},
+}
+
 func main() {
-	cfg, err := env.Load()
-	if err != nil {
-		panic(err)
+	// without a command the binary serves, like before the commands existed
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	mm := db.MockMongo{}
-	uri, err := mm.HostMemoryDb(cfg.MongodPath)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		usage()
+		if name != "help" {
+			os.Exit(2)
+		}
+		return
+	}
+
+	cfg, err := env.Load()
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	defer mm.Close()
 
-	conn := db.Connection{}
-	err = conn.Connect(uri)
+	logger, err := logging.New(cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	defer conn.Close()
 
-	engine := gin.Default()
+	if err := cmd.run(cfg, logger, args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+		os.Exit(1)
+	}
+}
 
-	engine.SetTrustedProxies(nil)
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags]\n\ncommands:\n", filepath.Base(os.Args[0]))
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nthe commands are configured by the environment, see the README; run <command> -h for the flags")
+}
 
-	dbHandler := &db.ArticleDbHandler{}
-	err = dbHandler.New(conn.Database)
-	if err != nil {
-		panic(err)
+// Connects to MONGO_URI; unlike serve, the commands do not host a memory db, as its data is gone when they finish
+func connect(cfg *env.Config, logger *logrus.Logger) (*db.Connection, error) {
+	if cfg.MongoUri == "" {
+		return nil, errors.New("MONGO_URI is required")
 	}
+	conn := &db.Connection{Logger: logger}
+	if err := conn.Connect(cfg.MongoUri); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func newAuthenticator(apiKeysEnabled bool, hmacSecret string, rsaPublicKeyFile string, issuer string, audience string, conn db.Connection, logger *logrus.Logger) (*auth.Authenticator, error) {
+	authenticator := &auth.Authenticator{Logger: logger}
 
-	validate := validator.New(validator.WithRequiredStructEnabled())
-	articleController := &controller.ArticleController{
-		ArticleDbHandler:   dbHandler,
-		ImageDirectory:     "images",
-		GenerateIdentifier: func() string { return uuid.New().String() },
-		Validate:           validate,
+	if apiKeysEnabled {
+		apiKeyDbHandler := &db.ApiKeyDbHandler{Logger: logger}
+		if err := apiKeyDbHandler.New(conn.Database); err != nil {
+			return nil, err
+		}
+		authenticator.ApiKeyDbHandler = apiKeyDbHandler
 	}
 
-	router := router.NewRouter(articleController, engine)
+	if hmacSecret != "" || rsaPublicKeyFile != "" {
+		authenticator.JWT = &auth.JWTConfig{HMACSecret: []byte(hmacSecret), Issuer: issuer, Audience: audience}
+		if rsaPublicKeyFile != "" {
+			pem, err := os.ReadFile(rsaPublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			authenticator.JWT.RSAPublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 
-	router.Init()
-	router.Run(":5000")
+	if authenticator.ApiKeyDbHandler == nil && authenticator.JWT == nil {
+		return nil, errors.New("authentication is enabled, but neither api keys nor jwt are configured")
+	}
+	return authenticator, nil
 }
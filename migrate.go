@@ -0,0 +1,89 @@
+package main
+
+import (
+	db "article-management-service/pkg/db"
+	"article-management-service/pkg/env"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Applies or reverts the migrations of the db; serve applies the pending ones on start unless MIGRATE_ON_START is off
+func migrate(cfg *env.Config, logger *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	target := flags.Int("to", 0, "the version to migrate to; 0 is the latest")
+	down := flags.Bool("down", false, "revert the migrations above the -to version")
+	status := flags.Bool("status", false, "only print the applied and pending migrations")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *down && *target == 0 && !*status {
+		// reverting everything is rarely meant
+		return errors.New("-down needs the version to revert to with -to")
+	}
+	if !db.IsValidExpiryPolicy(cfg.ExpiryPolicy) {
+		return fmt.Errorf("invalid expiry policy %q", cfg.ExpiryPolicy)
+	}
+
+	conn, err := connect(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	migrator := &db.Migrator{Logger: logger, Migrations: db.Migrations(cfg.ExpiryPolicy)}
+	if err := migrator.New(conn.Database); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if *status {
+		applied, err := migrator.Applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, record := range applied {
+			fmt.Printf("%4d  applied %s  %s\n", record.Version, record.AppliedAt.Format(time.RFC3339), record.Description)
+		}
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range pending {
+			fmt.Printf("%4d  pending  %s\n", migration.Version, migration.Description)
+		}
+		return nil
+	}
+
+	migrateTo := migrator.Up
+	if *down {
+		migrateTo = migrator.Down
+	}
+	versions, err := migrateTo(ctx, *target)
+	for _, version := range versions {
+		if *down {
+			fmt.Printf("reverted %d\n", version)
+		} else {
+			fmt.Printf("applied %d\n", version)
+		}
+	}
+	if err != nil || *down {
+		return err
+	}
+
+	// depends on the deployment instead of the version, as change streams need a replica set
+	if cfg.EventsEnabled {
+		dbHandler := &db.ArticleDbHandler{Logger: logger}
+		if err := dbHandler.New(conn.Database); err != nil {
+			return err
+		}
+		if err := dbHandler.EnableChangeEvents(ctx); err != nil {
+			return fmt.Errorf("failed to record articles before deletion: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,4 @@
+// Package articlepb holds the messages and the gRPC service generated from proto/article/v1/article.proto
+package articlepb
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=article-management-service --go-grpc_out=../.. --go-grpc_opt=module=article-management-service article/v1/article.proto
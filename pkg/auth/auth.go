@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderApiKey        = "X-API-Key"
+	HeaderAuthorization = "Authorization"
+
+	MethodApiKey = "api-key"
+	MethodJWT    = "jwt"
+)
+
+// ErrInvalidCredentials is returned for credentials that are malformed, unknown or expired
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	Subject string
+	Method  string
+	Tenant  string // empty when the credentials are not bound to a tenant
+	Roles   []string
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the authenticated identity; nil for anonymous requests
+func FromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+type disabledKey struct{}
+
+// WithAuthenticationDisabled marks a request of a deployment without authentication; only then may anonymous
+// callers act, authz denies them otherwise
+func WithAuthenticationDisabled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, disabledKey{}, true)
+}
+
+// AuthenticationDisabled reports whether the request belongs to a deployment without authentication
+func AuthenticationDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(disabledKey{}).(bool)
+	return disabled
+}
+
+// Disabled marks every request as one of a deployment without authentication, see WithAuthenticationDisabled
+func Disabled() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithAuthenticationDisabled(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// Claims are the registered claims plus the custom claims the service understands
+type Claims struct {
+	jwt.RegisteredClaims
+	Tenant string   `json:"tenant,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+// JWTConfig holds the keys bearer tokens can be signed with; at least one of them has to be set.
+type JWTConfig struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	Issuer       string
+	Audience     string
+}
+
+type Authenticator struct {
+	ApiKeyDbHandler db.ApiKeyDbHandlerInterface // nil disables api keys
+	JWT             *JWTConfig                  // nil disables bearer tokens
+	DefaultRoles    []string                    // roles of credentials that do not carry any
+	// runs before a request with invalid credentials is rejected, e.g. to charge the rate limit of the
+	// client ip; may abort the request itself
+	OnInvalidCredentials func(c *gin.Context)
+	Logger               *logrus.Logger
+}
+
+// Authenticate resolves the identity from the X-API-Key header or an Authorization bearer token and stores it
+// in the request context. Requests without credentials stay anonymous; use Required on routes that need an
+// identity. Invalid credentials are always rejected with 401.
+func (a *Authenticator) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, err := a.identify(c)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidCredentials) {
+				logging.FromContext(c.Request.Context(), a.Logger).WithError(err).Error("failed to authenticate")
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			logging.FromContext(c.Request.Context(), a.Logger).Info("rejected invalid credentials")
+			if a.OnInvalidCredentials != nil {
+				if a.OnInvalidCredentials(c); c.IsAborted() {
+					return
+				}
+			}
+			abortUnauthorized(c)
+			return
+		}
+
+		if identity != nil {
+			c.Request = c.Request.WithContext(WithIdentity(c.Request.Context(), identity))
+		}
+		c.Next()
+	}
+}
+
+// Required rejects anonymous requests with 401
+func Required() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if FromContext(c.Request.Context()) == nil {
+			abortUnauthorized(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+func abortUnauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Bearer realm="article-management-service"`)
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
+
+func (a *Authenticator) identify(c *gin.Context) (*Identity, error) {
+	return a.Identify(c.Request.Context(), c.GetHeader(HeaderApiKey), c.GetHeader(HeaderAuthorization))
+}
+
+// Identify resolves the identity of an api key or, without one, of an Authorization header; nil without
+// credentials. Also used by the gRPC api, which passes its metadata.
+func (a *Authenticator) Identify(ctx context.Context, apiKey string, authorization string) (*Identity, error) {
+	identity, err := a.identifyCredentials(ctx, apiKey, authorization)
+	if identity != nil && len(identity.Roles) == 0 {
+		identity.Roles = a.DefaultRoles
+	}
+	return identity, err
+}
+
+func (a *Authenticator) identifyCredentials(ctx context.Context, apiKey string, authorization string) (*Identity, error) {
+	if apiKey != "" {
+		return a.identifyApiKey(ctx, apiKey)
+	}
+
+	if authorization != "" {
+		scheme, token, found := strings.Cut(authorization, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return nil, ErrInvalidCredentials
+		}
+		return a.identifyBearer(token)
+	}
+
+	return nil, nil
+}
+
+func (a *Authenticator) identifyApiKey(ctx context.Context, apiKey string) (*Identity, error) {
+	if a.ApiKeyDbHandler == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	found, err := a.ApiKeyDbHandler.FindOneByHash(ctx, HashApiKey(apiKey))
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{Subject: found.Subject, Method: MethodApiKey, Tenant: found.Tenant, Roles: found.Roles}, nil
+}
+
+func (a *Authenticator) identifyBearer(tokenString string) (*Identity, error) {
+	if a.JWT == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	methods := make([]string, 0)
+	if len(a.JWT.HMACSecret) > 0 {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+	if a.JWT.RSAPublicKey != nil {
+		methods = append(methods, "RS256", "RS384", "RS512")
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if a.JWT.Issuer != "" {
+		options = append(options, jwt.WithIssuer(a.JWT.Issuer))
+	}
+	if a.JWT.Audience != "" {
+		options = append(options, jwt.WithAudience(a.JWT.Audience))
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, a.keyFunc, options...)
+	if err != nil || claims.Subject == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{Subject: claims.Subject, Method: MethodJWT, Tenant: claims.Tenant, Roles: claims.Roles}, nil
+}
+
+// Picks the verification key by the signing method of the token; the methods are already restricted by the parser
+func (a *Authenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return a.JWT.HMACSecret, nil
+	case *jwt.SigningMethodRSA:
+		return a.JWT.RSAPublicKey, nil
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// HashApiKey returns the hex sha256 of the key, the form in which api keys are stored
+func HashApiKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+// GenerateApiKey returns a new random api key and its hash
+func GenerateApiKey() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	apiKey := base64.RawURLEncoding.EncodeToString(buf)
+	return apiKey, HashApiKey(apiKey), nil
+}
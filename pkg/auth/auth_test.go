@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func createToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.RegisteredClaims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestAuthenticator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate rsa key: %v", err)
+	}
+	hmacSecret := []byte("test-secret")
+
+	validApiKey, validHash, err := GenerateApiKey()
+	if err != nil {
+		t.Fatalf("GenerateApiKey() error = %v", err)
+	}
+
+	a := &Authenticator{
+		ApiKeyDbHandler: &mocks.MockApiKeyDbHandler{FindOneByHashFunc: func(ctx context.Context, hash string) (*db.ApiKeyDb, error) {
+			if hash == validHash {
+				return &db.ApiKeyDb{Hash: hash, Subject: "importer"}, nil
+			}
+			return nil, nil
+		}},
+		JWT: &JWTConfig{HMACSecret: hmacSecret, RSAPublicKey: &rsaKey.PublicKey, Issuer: "gateway", Audience: "articles"},
+	}
+
+	validClaims := jwt.RegisteredClaims{
+		Subject:   "editor-1",
+		Issuer:    "gateway",
+		Audience:  jwt.ClaimStrings{"articles"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	wrongAudience := validClaims
+	wrongAudience.Audience = jwt.ClaimStrings{"other"}
+	expired := validClaims
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name            string
+		headers         map[string]string
+		expectedStatus  int
+		expectedSubject string
+	}{
+		{name: "Reject anonymous", headers: map[string]string{}, expectedStatus: http.StatusUnauthorized},
+		{name: "Accept valid api key", headers: map[string]string{HeaderApiKey: validApiKey}, expectedStatus: http.StatusOK, expectedSubject: "importer"},
+		{name: "Reject unknown api key", headers: map[string]string{HeaderApiKey: "unknown"}, expectedStatus: http.StatusUnauthorized},
+		{name: "Accept HMAC signed token", headers: map[string]string{HeaderAuthorization: "Bearer " + createToken(t, jwt.SigningMethodHS256, hmacSecret, validClaims)}, expectedStatus: http.StatusOK, expectedSubject: "editor-1"},
+		{name: "Accept RSA signed token", headers: map[string]string{HeaderAuthorization: "Bearer " + createToken(t, jwt.SigningMethodRS256, rsaKey, validClaims)}, expectedStatus: http.StatusOK, expectedSubject: "editor-1"},
+		{name: "Reject token with other secret", headers: map[string]string{HeaderAuthorization: "Bearer " + createToken(t, jwt.SigningMethodHS256, []byte("other"), validClaims)}, expectedStatus: http.StatusUnauthorized},
+		{name: "Reject token for other audience", headers: map[string]string{HeaderAuthorization: "Bearer " + createToken(t, jwt.SigningMethodHS256, hmacSecret, wrongAudience)}, expectedStatus: http.StatusUnauthorized},
+		{name: "Reject expired token", headers: map[string]string{HeaderAuthorization: "Bearer " + createToken(t, jwt.SigningMethodHS256, hmacSecret, expired)}, expectedStatus: http.StatusUnauthorized},
+		{name: "Reject other scheme", headers: map[string]string{HeaderAuthorization: "Basic dXNlcjpwYXNz"}, expectedStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject string
+			engine := gin.New()
+			engine.Use(a.Authenticate())
+			engine.POST("/article", Required(), func(c *gin.Context) {
+				subject = FromContext(c.Request.Context()).Subject
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest("POST", "/article", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			response := httptest.NewRecorder()
+			engine.ServeHTTP(response, req)
+
+			if response.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, response.Code)
+				return
+			}
+
+			if subject != tt.expectedSubject {
+				t.Errorf("Authenticate() subject = %v, want %v", subject, tt.expectedSubject)
+			}
+		})
+	}
+
+	t.Run("Keep public routes open for anonymous requests", func(t *testing.T) {
+		engine := gin.New()
+		engine.Use(a.Authenticate())
+		engine.GET("/article", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req, _ := http.NewRequest("GET", "/article", nil)
+		response := httptest.NewRecorder()
+		engine.ServeHTTP(response, req)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("Expected status %d; got %d", http.StatusOK, response.Code)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package authz
+
+import "article-management-service/pkg/auth"
+
+type Role = string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+type Action string
+
+const (
+	ActionRead           Action = "read"
+	ActionCreate         Action = "create"
+	ActionUpdate         Action = "update"
+	ActionDelete         Action = "delete"
+	ActionAttachImage    Action = "attach-image"
+	ActionReadAudit      Action = "read-audit"
+	ActionManageWebhooks Action = "manage-webhooks"
+	ActionSwitchTenant   Action = "switch-tenant" // select the tenant with the X-Tenant-ID header
+)
+
+// Scope is on which articles a role may perform an action
+type Scope int
+
+const (
+	ScopeNone Scope = iota
+	ScopeOwn        // only articles the identity is the author of
+	ScopeAny
+)
+
+// Matrix is the permission matrix of the roles
+var Matrix = map[Role]map[Action]Scope{
+	RoleViewer: {
+		ActionRead: ScopeAny,
+	},
+	RoleEditor: {
+		ActionRead:        ScopeAny,
+		ActionCreate:      ScopeAny,
+		ActionUpdate:      ScopeOwn,
+		ActionDelete:      ScopeOwn,
+		ActionAttachImage: ScopeOwn,
+	},
+	RoleAdmin: {
+		ActionRead:           ScopeAny,
+		ActionCreate:         ScopeAny,
+		ActionUpdate:         ScopeAny,
+		ActionDelete:         ScopeAny,
+		ActionAttachImage:    ScopeAny,
+		ActionReadAudit:      ScopeAny,
+		ActionManageWebhooks: ScopeAny,
+		ActionSwitchTenant:   ScopeAny,
+	},
+}
+
+// Anonymous is what callers without identity may do when authentication is enabled; reads are public
+var Anonymous = map[Action]Scope{
+	ActionRead: ScopeAny,
+}
+
+// Privileged actions need credentials even when authentication is disabled; the audit log exposes client ips,
+// webhooks make the service post to other hosts and switching tenants reaches the articles of every tenant
+var Privileged = map[Action]bool{
+	ActionReadAudit:      true,
+	ActionManageWebhooks: true,
+	ActionSwitchTenant:   true,
+}
+
+func IsValidRole(role string) bool {
+	_, ok := Matrix[role]
+	return ok
+}
+
+// Allowed reports whether the identity may perform the action on an article of the given author;
+// for actions that do not target an existing article, e.g. create, the author is empty.
+// Anonymous callers (a nil identity) may do everything but the Privileged actions when authentication is
+// disabled and only what Anonymous permits when it is enabled.
+func Allowed(authEnabled bool, identity *auth.Identity, action Action, authorId string) bool {
+	if identity == nil {
+		return !Privileged[action] && (!authEnabled || Anonymous[action] == ScopeAny)
+	}
+
+	for _, role := range identity.Roles {
+		switch Matrix[role][action] {
+		case ScopeAny:
+			return true
+		case ScopeOwn:
+			if authorId != "" && authorId == identity.Subject {
+				return true
+			}
+		}
+	}
+	return false
+}
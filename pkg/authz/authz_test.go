@@ -0,0 +1,47 @@
+package authz
+
+import (
+	"article-management-service/pkg/auth"
+	"testing"
+)
+
+func TestAllowed(t *testing.T) {
+	viewer := &auth.Identity{Subject: "viewer-1", Roles: []string{RoleViewer}}
+	editor := &auth.Identity{Subject: "editor-1", Roles: []string{RoleEditor}}
+	admin := &auth.Identity{Subject: "admin-1", Roles: []string{RoleAdmin}}
+	noRoles := &auth.Identity{Subject: "nobody"}
+
+	tests := []struct {
+		name     string
+		disabled bool
+		identity *auth.Identity
+		action   Action
+		authorId string
+		want     bool
+	}{
+		{name: "Anonymous without authentication", disabled: true, identity: nil, action: ActionAttachImage, authorId: "editor-1", want: true},
+		{name: "Anonymous reads with authentication", identity: nil, action: ActionRead, want: true},
+		{name: "Anonymous can not create with authentication", identity: nil, action: ActionCreate, want: false},
+		{name: "Roles apply also without authentication", disabled: true, identity: viewer, action: ActionCreate, want: false},
+		{name: "Prevent anonymous reading the audit log without authentication", disabled: true, identity: nil, action: ActionReadAudit, want: false},
+		{name: "Prevent anonymous managing webhooks without authentication", disabled: true, identity: nil, action: ActionManageWebhooks, want: false},
+		{name: "Viewer reads", identity: viewer, action: ActionRead, want: true},
+		{name: "Viewer can not create", identity: viewer, action: ActionCreate, want: false},
+		{name: "Editor creates", identity: editor, action: ActionCreate, want: true},
+		{name: "Editor attaches image to own article", identity: editor, action: ActionAttachImage, authorId: "editor-1", want: true},
+		{name: "Editor can not attach image to article of someone else", identity: editor, action: ActionAttachImage, authorId: "editor-2", want: false},
+		{name: "Editor can not modify article without author", identity: editor, action: ActionUpdate, authorId: "", want: false},
+		{name: "Admin attaches image to article of someone else", identity: admin, action: ActionAttachImage, authorId: "editor-2", want: true},
+		{name: "Admin deletes any article", identity: admin, action: ActionDelete, authorId: "", want: true},
+		{name: "Editor can not manage webhooks", identity: editor, action: ActionManageWebhooks, want: false},
+		{name: "Admin manages webhooks", identity: admin, action: ActionManageWebhooks, want: true},
+		{name: "Identity without roles", identity: noRoles, action: ActionRead, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allowed(!tt.disabled, tt.identity, tt.action, tt.authorId); got != tt.want {
+				t.Errorf("Allowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
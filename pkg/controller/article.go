@@ -1,18 +1,32 @@
 package controller
 
 import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
 	"article-management-service/pkg/db"
-	"log"
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"article-management-service/pkg/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+var tracer = tracing.Tracer("article-management-service/pkg/controller")
+
 const MAX_IMAGE_SIZE = 5 * 1024 * 1024
 const MAX_IMAGE_AMOUNT = 3
 
@@ -20,13 +34,29 @@ type ArticleController struct {
 	ImageDirectory     string
 	GenerateIdentifier func() string
 	ArticleDbHandler   db.ArticleDbHandlerInterface
+	AuditDbHandler     db.AuditDbHandlerInterface    // nil disables the audit log
+	RevisionDbHandler  db.RevisionDbHandlerInterface // nil disables recording revisions
 	Validate           *validator.Validate
+	Logger             *logrus.Logger
+	Quotas             tenant.Quotas
+	ExpiryPolicy       string                 // of articles created without one; defaults to db.ExpiryPolicyDelete
+	RenewMaxHorizon    time.Duration          // how far in the future an article can be renewed to; 0 is unlimited
+	RenewMaxRenewals   int                    // how often an article can be renewed; 0 is unlimited
+	ArticleEvents      db.ArticleEventWatcher // serves the event streams; defaults to a change stream per client
+	EventsDone         <-chan struct{}        // closes the event streams, e.g. on shutdown
+	Limit              CallLimiter            // optional; charges the imported articles to the create budget
 }
 
 type NewArticleBody struct {
 	Title          string    `json:"title" validate:"required"`
 	ExpirationDate time.Time `json:"expirationDate" validate:"required"`
 	Description    string    `json:"description" validate:"required,max=4000"`
+	ExpiryPolicy   string    `json:"expiryPolicy,omitempty" validate:"omitempty,oneof=delete archive hide"`
+}
+
+// CreatedResponse is the id of a created article or webhook
+type CreatedResponse struct {
+	Id string `json:"id"`
 }
 
 // Create controller inserts the article based on json body; return the id hex.
@@ -36,97 +66,413 @@ type NewArticleBody struct {
 func (c *ArticleController) Create(context *gin.Context) {
 	article := &NewArticleBody{}
 	if err := context.BindJSON(article); err != nil {
-		handleError(context, err, http.StatusBadRequest)
+		c.handleError(context, err, http.StatusBadRequest)
 		return
 	}
 
-	if err := c.Validate.Struct(article); err != nil {
-		handleError(context, err, http.StatusBadRequest)
+	created, err := c.create(requestContext(context), *article)
+	if err != nil {
+		c.handleRequestError(context, err)
 		return
 	}
 
-	id, err := c.ArticleDbHandler.InsertOne(db.ArticleDb{
+	context.JSON(http.StatusCreated, CreatedResponse{Id: created.Id.Hex()})
+}
+
+// Validates, inserts and returns the article; shared by the REST, JSON-RPC, gRPC and GraphQL apis
+func (c *ArticleController) create(ctx context.Context, article NewArticleBody) (*db.ArticleDb, error) {
+	if err := c.Validate.Struct(article); err != nil {
+		return nil, fail(http.StatusBadRequest, err)
+	}
+
+	if err := c.permit(ctx, authz.ActionCreate, ""); err != nil {
+		return nil, err
+	}
+
+	// the quota is checked before inserting; concurrent creates can overshoot it slightly
+	quota := c.Quotas.For(tenant.FromContext(ctx))
+	if quota.MaxArticles > 0 {
+		count, err := c.ArticleDbHandler.CountArticles(ctx)
+		if err != nil {
+			return nil, fail(http.StatusInternalServerError, err)
+		}
+		if count >= quota.MaxArticles {
+			return nil, fail(http.StatusForbidden, errQuotaExhausted)
+		}
+	}
+
+	created := db.ArticleDb{
+		AuthorId:       authorId(ctx),
 		Title:          article.Title,
 		Description:    article.Description,
 		ExpirationDate: article.ExpirationDate,
-	})
+		ExpiryPolicy:   c.expiryPolicy(article.ExpiryPolicy),
+	}
+	id, err := c.ArticleDbHandler.InsertOne(ctx, created)
 
 	if err != nil {
-		handleError(context, err, http.StatusInternalServerError)
-		return
+		return nil, fail(http.StatusInternalServerError, err)
 	}
 
-	context.JSON(http.StatusCreated, gin.H{"id": id.Hex()})
+	created.Id = id
+	created.TenantId = tenant.FromContext(ctx)
+	c.audit(ctx, db.AuditActionCreate, id, nil, &created)
+	c.revise(ctx, nil, &created)
+	return &created, nil
 }
 
 // TODO: check mime type
 func (c *ArticleController) AttachImage(context *gin.Context) {
 	articleId, err := primitive.ObjectIDFromHex(context.Param("articleId"))
 	if err != nil {
-		handleError(context, err, http.StatusBadRequest)
+		c.handleError(context, err, http.StatusBadRequest)
 		return
 	}
 
-	article, err := c.ArticleDbHandler.FindOneById(articleId)
-	if err != nil {
-		handleError(context, nil, http.StatusInternalServerError)
+	// the form is only read once the request is authorized
+	load := func() (*uploadedImage, error) {
+		file, err := context.FormFile("file")
+		if err != nil {
+			return nil, fail(http.StatusInternalServerError, err)
+		}
+		return &uploadedImage{size: file.Size, save: func(path string) error {
+			return saveUploadedFile(context, file, path)
+		}}, nil
+	}
+
+	if err := c.attachImage(requestContext(context), articleId, load); err != nil {
+		c.handleRequestError(context, err)
 		return
 	}
 
+	context.Status(http.StatusOK)
+}
+
+var errTooManyImages = errors.New("the article has the maximum amount of images")
+
+// uploadedImage is the image of an attach request, saved once it passed the checks
+type uploadedImage struct {
+	size int64
+	save func(path string) error
+}
+
+// Stores the image and appends it to the article; shared by the REST, JSON-RPC and gRPC apis
+func (c *ArticleController) attachImage(ctx context.Context, articleId primitive.ObjectID, load func() (*uploadedImage, error)) error {
+	article, err := c.ArticleDbHandler.FindOneById(ctx, articleId)
+	if err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
+
 	if article == nil {
-		handleError(context, nil, http.StatusNotFound)
-		return
+		return fail(http.StatusNotFound, nil)
+	}
+
+	if err := c.permit(ctx, authz.ActionAttachImage, article.AuthorId); err != nil {
+		return err
 	}
 
 	if len(article.ImageFilePaths) >= MAX_IMAGE_AMOUNT {
-		handleError(context, nil, http.StatusForbidden)
-		return
+		return fail(http.StatusForbidden, errTooManyImages)
 	}
 
-	file, err := context.FormFile("file")
+	image, err := load()
 	if err != nil {
-		handleError(context, err, http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	if file.Size > MAX_IMAGE_SIZE {
-		handleError(context, nil, http.StatusBadRequest)
-		return
+	if image.size > MAX_IMAGE_SIZE {
+		return fail(http.StatusBadRequest, errors.New("the image is too large"))
+	}
+
+	tenantId := tenant.FromContext(ctx)
+	quota := c.Quotas.For(tenantId)
+	if quota.MaxImages > 0 {
+		count, err := c.ArticleDbHandler.CountImages(ctx)
+		if err != nil {
+			return fail(http.StatusInternalServerError, err)
+		}
+		if count >= quota.MaxImages {
+			return fail(http.StatusForbidden, errors.New("the image quota of the tenant is exhausted"))
+		}
+	}
+
+	// every tenant has its own image directory
+	directory := filepath.Join(c.ImageDirectory, tenantId)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return fail(http.StatusInternalServerError, err)
 	}
 
 	id := c.GenerateIdentifier()
-	path := filepath.Join(c.ImageDirectory, id)
-	context.SaveUploadedFile(file, path)
-	c.ArticleDbHandler.AppendImage(articleId, path)
+	path := filepath.Join(directory, id)
+	if err := image.save(path); err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
 
-	context.Status(http.StatusOK)
+	found, err := c.ArticleDbHandler.AppendImage(ctx, articleId, path)
+	if err != nil {
+		os.Remove(path)
+		return fail(http.StatusInternalServerError, err)
+	}
+
+	// deleted or given the last image concurrently
+	if !found {
+		os.Remove(path)
+		return fail(http.StatusForbidden, errTooManyImages)
+	}
+
+	updated := *article
+	updated.ImageFilePaths = append(append([]string{}, article.ImageFilePaths...), path)
+	c.audit(ctx, db.AuditActionAttachImage, articleId, article, &updated)
+	c.revise(ctx, article, &updated)
+	return nil
 }
 
 func (c *ArticleController) Find(context *gin.Context) {
-	withImagesStr := context.Request.URL.Query().Get("withImages")
 	var withImages *bool
-	tmp, err := strconv.ParseBool(withImagesStr)
-	if err == nil {
+	if withImagesStr := context.Query("withImages"); withImagesStr != "" {
+		tmp, err := strconv.ParseBool(withImagesStr)
+		if err != nil {
+			c.handleError(context, err, http.StatusBadRequest)
+			return
+		}
 		withImages = &tmp
 	}
 
+	titles, err := c.find(requestContext(context), withImages)
+	if err != nil {
+		c.handleRequestError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, titles)
+}
+
+// Finds the titles of all articles, or of the ones with or without images; shared by the REST, JSON-RPC and gRPC apis
+func (c *ArticleController) find(ctx context.Context, withImages *bool) ([]string, error) {
+	if err := c.permit(ctx, authz.ActionRead, ""); err != nil {
+		return nil, err
+	}
+
 	var titles []string
+	var err error
 	if withImages == nil {
-		titles, err = c.ArticleDbHandler.FindAllTitles()
+		titles, err = c.ArticleDbHandler.FindAllTitles(ctx)
 	} else {
-		titles, err = c.ArticleDbHandler.FindTitlesByHasImage(*withImages)
+		titles, err = c.ArticleDbHandler.FindTitlesByHasImage(ctx, *withImages)
+	}
+	if err != nil {
+		return nil, fail(http.StatusInternalServerError, err)
+	}
+	return titles, nil
+}
 
+// Finds one article; not found while it is in the trash
+func (c *ArticleController) get(ctx context.Context, articleId primitive.ObjectID) (*db.ArticleDb, error) {
+	if err := c.permit(ctx, authz.ActionRead, ""); err != nil {
+		return nil, err
 	}
+
+	article, err := c.ArticleDbHandler.FindOneById(ctx, articleId)
 	if err != nil {
-		handleError(context, nil, http.StatusBadRequest)
+		return nil, fail(http.StatusInternalServerError, err)
+	}
+	if article == nil {
+		return nil, fail(http.StatusNotFound, nil)
+	}
+	return article, nil
+}
+
+// The expiry policy of a new article; the one of the deployment when the request names none
+func (c *ArticleController) expiryPolicy(requested string) string {
+	switch {
+	case requested != "":
+		return requested
+	case c.ExpiryPolicy != "":
+		return c.ExpiryPolicy
 	}
+	return db.ExpiryPolicyDelete
+}
 
-	context.JSON(http.StatusOK, titles)
+// Checks the permission of the identity of the request; aborts with 403 when not allowed
+func (c *ArticleController) authorize(context *gin.Context, action authz.Action, authorId string) bool {
+	return authorize(context, c.Logger, action, authorId)
+}
+
+func authorize(context *gin.Context, logger *logrus.Logger, action authz.Action, authorId string) bool {
+	if err := permit(context.Request.Context(), logger, action, authorId); err != nil {
+		context.AbortWithStatus(http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// Checks the permission of the identity of the request; a 403 request error when not allowed
+func (c *ArticleController) permit(ctx context.Context, action authz.Action, authorId string) error {
+	return permit(ctx, c.Logger, action, authorId)
+}
+
+func permit(ctx context.Context, logger *logrus.Logger, action authz.Action, authorId string) error {
+	identity := auth.FromContext(ctx)
+	if !authz.Allowed(!auth.AuthenticationDisabled(ctx), identity, action, authorId) {
+		subject := ""
+		if identity != nil {
+			subject = identity.Subject
+		}
+		logging.FromContext(ctx, logger).WithField("action", action).WithField("subject", subject).Info("forbidden")
+		return fail(http.StatusForbidden, nil)
+	}
+	return nil
+}
+
+// The budgets a CallLimiter charges; named like the budgets of the routes
+const (
+	BudgetRead   = "read"
+	BudgetCreate = "create"
+	BudgetUpload = "upload"
+)
+
+// CallLimiter takes a token of the budget of the client; false and the seconds to wait when it is spent.
+// Endpoints that run several calls per request, i.e. /rpc and /graphql, charge each call with it.
+type CallLimiter func(ctx context.Context, budget string, clientIp string) (bool, int)
+
+// Charges a call to the budget; a 429 request error when it is spent. Without limiter every call is allowed.
+func charge(ctx context.Context, limit CallLimiter, budget string) error {
+	if limit == nil {
+		return nil
+	}
+	if allowed, retryAfter := limit(ctx, budget, clientIp(ctx)); !allowed {
+		return fail(http.StatusTooManyRequests, fmt.Errorf("the %s budget is spent; retry after %d seconds", budget, retryAfter))
+	}
+	return nil
+}
+
+// Appends the mutation to the audit log. The mutation already happened, so a failing audit log
+// does not fail the request; the error is logged by the db handler.
+func (c *ArticleController) audit(ctx context.Context, action string, articleId primitive.ObjectID, before *db.ArticleDb, after *db.ArticleDb) {
+	if c.AuditDbHandler == nil {
+		return
+	}
+
+	_ = c.AuditDbHandler.InsertOne(ctx, db.AuditEntryDb{
+		Time:      time.Now(),
+		ActorId:   authorId(ctx),
+		Action:    action,
+		ArticleId: articleId,
+		Before:    before,
+		After:     after,
+		ClientIp:  clientIp(ctx),
+		RequestId: logging.RequestId(ctx),
+	})
+}
+
+type clientIpKey struct{}
+
+// The context of the request with the client ip; the logic shared with the gRPC api takes it instead of the gin context
+func requestContext(context *gin.Context) context.Context {
+	return withClientIp(context.Request.Context(), context.ClientIP())
+}
+
+func withClientIp(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIpKey{}, ip)
+}
+
+// The ip of the client of the request; empty when unknown
+func clientIp(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIpKey{}).(string)
+	return ip
+}
+
+// The subject of the identity of the request; empty for anonymous requests
+func authorId(ctx context.Context) string {
+	if identity := auth.FromContext(ctx); identity != nil {
+		return identity.Subject
+	}
+	return ""
+}
+
+// Saves the uploaded file to disk within its own span, so disk time can be told apart from db time
+func saveUploadedFile(context *gin.Context, file *multipart.FileHeader, path string) error {
+	_, span := tracer.Start(context.Request.Context(), "SaveUploadedFile")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("file.path", path), attribute.Int64("file.size", file.Size))
+
+	if err := context.SaveUploadedFile(file, path); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// Saves the decoded image of a JSON-RPC or gRPC call to disk within its own span, like saveUploadedFile
+func saveImage(ctx context.Context, image []byte, path string) error {
+	_, span := tracer.Start(ctx, "SaveImage")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("file.path", path), attribute.Int("file.size", len(image)))
+
+	if err := os.WriteFile(path, image, 0644); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// requestError is a failed request with the status it is answered with; the error is logged, not answered
+type requestError struct {
+	status int
+	err    error
+}
+
+func fail(status int, err error) *requestError {
+	return &requestError{status: status, err: err}
+}
+
+func (e *requestError) Error() string {
+	if e.err == nil {
+		return http.StatusText(e.status)
+	}
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// The status of a request error; 500 for other errors
+func statusOf(err error) int {
+	var requestErr *requestError
+	if errors.As(err, &requestErr) {
+		return requestErr.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Aborts the request with the status of the request error
+func (c *ArticleController) handleRequestError(context *gin.Context, err error) {
+	var requestErr *requestError
+	if errors.As(err, &requestErr) {
+		c.handleError(context, requestErr.err, requestErr.status)
+		return
+	}
+	c.handleError(context, err, http.StatusInternalServerError)
+}
+
+// Logs the error with the request id of the context and aborts the request with the given status
+func (c *ArticleController) handleError(context *gin.Context, err error, status int) {
+	handleError(context, c.Logger, err, status)
 }
 
-func handleError(context *gin.Context, err error, status int) {
+func handleError(context *gin.Context, logger *logrus.Logger, err error, status int) {
 	if err != nil {
-		log.Println("Error:", err)
+		entry := logging.FromContext(context.Request.Context(), logger).WithError(err).WithField("status", status)
+		if status >= http.StatusInternalServerError {
+			entry.Error("request failed")
+		} else {
+			entry.Info("request rejected")
+		}
 	}
 	context.AbortWithStatus(status)
 }
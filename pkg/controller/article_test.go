@@ -1,14 +1,20 @@
 package controller
 
 import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
 	"article-management-service/pkg/db"
 	"article-management-service/pkg/mocks"
+	"article-management-service/pkg/tenant"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -31,14 +37,19 @@ func createJSONBodyContext(t *testing.T, body NewArticleBody) *gin.Context {
 	requestBody := bytes.NewBuffer(jsonRequest)
 
 	// Set the request body and content type in the context
-	context.Request = &http.Request{
+	context.Request = withoutAuthentication(&http.Request{
 		Body:   io.NopCloser(requestBody),
 		Header: http.Header{"Content-Type": []string{"application/json"}},
-	}
+	})
 
 	return context
 }
 
+// Marks the request as one of a deployment without authentication, where anonymous callers may act
+func withoutAuthentication(request *http.Request) *http.Request {
+	return request.WithContext(auth.WithAuthenticationDisabled(request.Context()))
+}
+
 func TestArticleController_Create(t *testing.T) {
 	validate := validator.New(validator.WithRequiredStructEnabled())
 
@@ -47,6 +58,7 @@ func TestArticleController_Create(t *testing.T) {
 		GenerateIdentifier func() string
 		ArticleDbHandler   db.ArticleDbHandlerInterface
 		Validate           *validator.Validate
+		Quotas             tenant.Quotas
 	}
 	type args struct {
 		context *gin.Context
@@ -83,15 +95,23 @@ func TestArticleController_Create(t *testing.T) {
 		},
 		{
 			name: "internal error - insertOne failure",
-			fields: fields{ArticleDbHandler: &mocks.MockArticleDbHandler{InsertOneFunc: func(new db.ArticleDb) (primitive.ObjectID, error) {
+			fields: fields{ArticleDbHandler: &mocks.MockArticleDbHandler{InsertOneFunc: func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
 				return primitive.NilObjectID, fmt.Errorf("test failure")
 			}}, Validate: validate},
 			args:           args{context: createJSONBodyContext(t, NewArticleBody{Title: "Test_Title", ExpirationDate: time.Now(), Description: "Test_Description"})},
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "Prevent exceeding the article quota of the tenant",
+			fields: fields{ArticleDbHandler: &mocks.MockArticleDbHandler{CountArticlesFunc: func(ctx context.Context) (int64, error) {
+				return 2, nil
+			}}, Validate: validate, Quotas: tenant.Quotas{Default: tenant.Quota{MaxArticles: 2}}},
+			args:           args{context: createJSONBodyContext(t, NewArticleBody{Title: "Test_Title", ExpirationDate: time.Now(), Description: "Test_Description"})},
+			expectedStatus: http.StatusForbidden,
+		},
 		{
 			name: "success",
-			fields: fields{ArticleDbHandler: &mocks.MockArticleDbHandler{InsertOneFunc: func(new db.ArticleDb) (primitive.ObjectID, error) {
+			fields: fields{ArticleDbHandler: &mocks.MockArticleDbHandler{InsertOneFunc: func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
 				return primitive.ObjectIDFromHex("6547986414e33ec8c072c2d3")
 			}}, Validate: validate},
 			args:           args{context: createJSONBodyContext(t, NewArticleBody{Title: "Test_Title", ExpirationDate: time.Now(), Description: "Test_Description"})},
@@ -105,6 +125,7 @@ func TestArticleController_Create(t *testing.T) {
 				GenerateIdentifier: tt.fields.GenerateIdentifier,
 				ArticleDbHandler:   tt.fields.ArticleDbHandler,
 				Validate:           tt.fields.Validate,
+				Quotas:             tt.fields.Quotas,
 			}
 			c.Create(tt.args.context)
 
@@ -117,6 +138,72 @@ func TestArticleController_Create(t *testing.T) {
 	}
 }
 
+func TestArticleController_AttachImage_Authorization(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	handler := &mocks.MockArticleDbHandler{FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+		return &db.ArticleDb{Id: id, Title: "Test_Title", AuthorId: "editor-1"}, nil
+	}}
+
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		expectedStatus int
+	}{
+		{name: "Prevent editor attaching to article of someone else", identity: &auth.Identity{Subject: "editor-2", Roles: []string{authz.RoleEditor}}, expectedStatus: http.StatusForbidden},
+		{name: "Prevent anonymous attaching with authentication", identity: nil, expectedStatus: http.StatusForbidden},
+		{name: "Prevent viewer attaching", identity: &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleViewer}}, expectedStatus: http.StatusForbidden},
+		// passes authorization; fails afterwards on the missing file
+		{name: "Allow author", identity: &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}, expectedStatus: http.StatusInternalServerError},
+		{name: "Allow admin", identity: &auth.Identity{Subject: "admin-1", Roles: []string{authz.RoleAdmin}}, expectedStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context, _ := gin.CreateTestContext(httptest.NewRecorder())
+			context.Params = gin.Params{{Key: "articleId", Value: articleId.Hex()}}
+			context.Request = httptest.NewRequest("POST", "/image/"+articleId.Hex(), nil)
+			context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), tt.identity))
+
+			c := &ArticleController{ArticleDbHandler: handler}
+			c.AttachImage(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_AttachImage() = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestArticleController_AttachImage_Limit(t *testing.T) {
+	t.Run("Prevent exceeding the limit with a concurrent upload", func(t *testing.T) {
+		directory := t.TempDir()
+		handler := &mocks.MockArticleDbHandler{
+			FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+				return &db.ArticleDb{Id: id, Title: "Test_Title", ImageFilePaths: []string{"first", "second"}}, nil
+			},
+			// the other upload took the last place since the article was found
+			AppendImageFunc: func(ctx context.Context, id primitive.ObjectID, path string) (bool, error) {
+				return false, nil
+			},
+		}
+		c := &ArticleController{
+			ImageDirectory:     directory,
+			GenerateIdentifier: func() string { return "image" },
+			ArticleDbHandler:   handler,
+		}
+
+		load := func() (*uploadedImage, error) {
+			return &uploadedImage{size: 5, save: func(path string) error { return os.WriteFile(path, []byte("image"), 0644) }}, nil
+		}
+		err := c.attachImage(auth.WithAuthenticationDisabled(context.Background()), primitive.NewObjectID(), load)
+		if status := statusOf(err); status != http.StatusForbidden {
+			t.Errorf("ArticleController.attachImage() status = %v, want %v", status, http.StatusForbidden)
+		}
+		if _, err := os.Stat(filepath.Join(directory, tenant.Default, "image")); !os.IsNotExist(err) {
+			t.Errorf("the image was kept, stat error = %v", err)
+		}
+	})
+}
+
 func TestArticleController_AttachImage(t *testing.T) {
 	t.Skip("TODO: no param; bad request")
 	t.Skip("TODO: internal error - findOneById failure")
@@ -127,10 +214,101 @@ func TestArticleController_AttachImage(t *testing.T) {
 }
 
 func TestArticleController_Find(t *testing.T) {
-	t.Skip("TODO: success - no param")
-	t.Skip("TODO: success - invalid param")
-	t.Skip("TODO: success - withImages:true")
-	t.Skip("TODO: success - withImages:false")
-	t.Skip("TODO: internal error - findAllTitles failure")
-	t.Skip("TODO: internal error - findTitlesByHasImage failure")
+	handler := &mocks.MockArticleDbHandler{
+		FindAllTitlesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"with image", "without image"}, nil
+		},
+		FindTitlesByHasImageFunc: func(ctx context.Context, withImage bool) ([]string, error) {
+			if withImage {
+				return []string{"with image"}, nil
+			}
+			return []string{"without image"}, nil
+		},
+	}
+	failing := &mocks.MockArticleDbHandler{
+		FindAllTitlesFunc: func(ctx context.Context) ([]string, error) {
+			return nil, fmt.Errorf("failed")
+		},
+		FindTitlesByHasImageFunc: func(ctx context.Context, withImage bool) ([]string, error) {
+			return nil, fmt.Errorf("failed")
+		},
+	}
+
+	tests := []struct {
+		name           string
+		handler        *mocks.MockArticleDbHandler
+		query          string
+		expectedStatus int
+		expectedTitles []string
+	}{
+		{name: "Successfully find all titles", handler: handler, expectedStatus: http.StatusOK, expectedTitles: []string{"with image", "without image"}},
+		{name: "Successfully find titles with images", handler: handler, query: "?withImages=true", expectedStatus: http.StatusOK, expectedTitles: []string{"with image"}},
+		{name: "Successfully find titles without images", handler: handler, query: "?withImages=false", expectedStatus: http.StatusOK, expectedTitles: []string{"without image"}},
+		{name: "Prevent invalid param", handler: handler, query: "?withImages=maybe", expectedStatus: http.StatusBadRequest},
+		{name: "Internal error - findAllTitles failure", handler: failing, expectedStatus: http.StatusInternalServerError},
+		{name: "Internal error - findTitlesByHasImage failure", handler: failing, query: "?withImages=true", expectedStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Request = httptest.NewRequest("GET", "/article"+tt.query, nil)
+
+			c := &ArticleController{ArticleDbHandler: tt.handler}
+			c.Find(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_Find() = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+			if tt.expectedTitles == nil {
+				return
+			}
+			var titles []string
+			if err := json.Unmarshal(recorder.Body.Bytes(), &titles); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if strings.Join(titles, ",") != strings.Join(tt.expectedTitles, ",") {
+				t.Errorf("ArticleController_Find() titles = %v, want %v", titles, tt.expectedTitles)
+			}
+		})
+	}
+}
+
+func TestArticleController_Create_ExpiryPolicy(t *testing.T) {
+	tests := []struct {
+		name             string
+		deploymentPolicy string
+		requestedPolicy  string
+		expectedStatus   int
+		expectedPolicy   string
+	}{
+		{name: "Successfully default to delete", expectedStatus: http.StatusCreated, expectedPolicy: db.ExpiryPolicyDelete},
+		{name: "Successfully use the policy of the deployment", deploymentPolicy: db.ExpiryPolicyArchive, expectedStatus: http.StatusCreated, expectedPolicy: db.ExpiryPolicyArchive},
+		{name: "Successfully use the policy of the article", deploymentPolicy: db.ExpiryPolicyArchive, requestedPolicy: db.ExpiryPolicyHide, expectedStatus: http.StatusCreated, expectedPolicy: db.ExpiryPolicyHide},
+		{name: "Prevent unknown policy", requestedPolicy: "keep", expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var foundPolicy string
+			c := &ArticleController{
+				ArticleDbHandler: &mocks.MockArticleDbHandler{InsertOneFunc: func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
+					foundPolicy = new.ExpiryPolicy
+					return primitive.NewObjectID(), nil
+				}},
+				Validate:     validator.New(validator.WithRequiredStructEnabled()),
+				ExpiryPolicy: tt.deploymentPolicy,
+			}
+
+			context := createJSONBodyContext(t, NewArticleBody{Title: "Test_Title", ExpirationDate: time.Now(), Description: "Test_Description", ExpiryPolicy: tt.requestedPolicy})
+			c.Create(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_Create() = %v, want %v", foundStatus, tt.expectedStatus)
+				return
+			}
+			if foundPolicy != tt.expectedPolicy {
+				t.Errorf("ArticleController_Create() policy = %v, want %v", foundPolicy, tt.expectedPolicy)
+			}
+		})
+	}
 }
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditController struct {
+	AuditDbHandler db.AuditDbHandlerInterface
+	Logger         *logrus.Logger
+}
+
+// Find returns the audit entries of the tenant, newest first. The query parameters articleId, actor,
+// from and to (RFC 3339) and limit narrow the entries down.
+func (c *AuditController) Find(context *gin.Context) {
+	if !authorize(context, c.Logger, authz.ActionReadAudit, "") {
+		return
+	}
+
+	filter, err := parseAuditFilter(context)
+	if err != nil {
+		logging.FromContext(context.Request.Context(), c.Logger).WithError(err).Info("request rejected")
+		context.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	entries, err := c.AuditDbHandler.Find(context.Request.Context(), filter)
+	if err != nil {
+		logging.FromContext(context.Request.Context(), c.Logger).WithError(err).Error("request failed")
+		context.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	context.JSON(http.StatusOK, entries)
+}
+
+func parseAuditFilter(context *gin.Context) (db.AuditFilter, error) {
+	filter := db.AuditFilter{ActorId: context.Query("actor")}
+	var err error
+
+	if articleId := context.Query("articleId"); articleId != "" {
+		if filter.ArticleId, err = primitive.ObjectIDFromHex(articleId); err != nil {
+			return filter, err
+		}
+	}
+	if from := context.Query("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return filter, err
+		}
+	}
+	if to := context.Query("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return filter, err
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, errors.New("from is not before to")
+	}
+	if limit := context.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.ParseInt(limit, 10, 64); err != nil {
+			return filter, err
+		}
+		if filter.Limit <= 0 {
+			return filter, errors.New("limit is not positive")
+		}
+	}
+	return filter, nil
+}
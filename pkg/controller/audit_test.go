@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArticleController_Create_Audit(t *testing.T) {
+	insertedId, _ := primitive.ObjectIDFromHex("6547986414e33ec8c072c2d3")
+	var entries []db.AuditEntryDb
+
+	c := &ArticleController{
+		ArticleDbHandler: &mocks.MockArticleDbHandler{InsertOneFunc: func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
+			return insertedId, nil
+		}},
+		AuditDbHandler: &mocks.MockAuditDbHandler{InsertOneFunc: func(ctx context.Context, new db.AuditEntryDb) error {
+			entries = append(entries, new)
+			return nil
+		}},
+		Validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+
+	context := createJSONBodyContext(t, NewArticleBody{Title: "Test_Title", ExpirationDate: time.Now(), Description: "Test_Description"})
+	context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}))
+	c.Create(context)
+
+	if len(entries) != 1 {
+		t.Fatalf("ArticleController_Create() audit entries = %v, want %v", len(entries), 1)
+	}
+	entry := entries[0]
+	if entry.Action != db.AuditActionCreate || entry.ActorId != "editor-1" || entry.ArticleId != insertedId || entry.Before != nil || entry.After == nil || entry.After.Title != "Test_Title" {
+		t.Errorf("ArticleController_Create() audit entry = %+v", entry)
+	}
+}
+
+func TestAuditController_Find(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	admin := &auth.Identity{Subject: "admin-1", Roles: []string{authz.RoleAdmin}}
+	editor := &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}
+
+	tests := []struct {
+		name           string
+		query          string
+		identity       *auth.Identity
+		expectedFilter db.AuditFilter
+		expectedStatus int
+	}{
+		{name: "Successfully find all", query: "", identity: admin, expectedStatus: http.StatusOK},
+		{
+			name:     "Successfully filter",
+			query:    "?articleId=" + articleId.Hex() + "&actor=editor-1&from=2023-11-01T00:00:00Z&to=2023-12-01T00:00:00Z&limit=10",
+			identity: admin,
+			expectedFilter: db.AuditFilter{
+				ArticleId: articleId,
+				ActorId:   "editor-1",
+				From:      time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC),
+				To:        time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+				Limit:     10,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{name: "Prevent editors reading the audit log", query: "", identity: editor, expectedStatus: http.StatusForbidden},
+		{name: "Prevent anonymous reading the audit log without authentication", query: "", identity: nil, expectedStatus: http.StatusForbidden},
+		{name: "Prevent invalid article id", query: "?articleId=invalid", identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent invalid time", query: "?from=yesterday", identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent empty time range", query: "?from=2023-12-01T00:00:00Z&to=2023-11-01T00:00:00Z", identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent negative limit", query: "?limit=-1", identity: admin, expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var foundFilter db.AuditFilter
+			c := &AuditController{AuditDbHandler: &mocks.MockAuditDbHandler{FindFunc: func(ctx context.Context, filter db.AuditFilter) ([]db.AuditEntryDb, error) {
+				foundFilter = filter
+				return []db.AuditEntryDb{}, nil
+			}}}
+
+			context, _ := gin.CreateTestContext(httptest.NewRecorder())
+			context.Request = withoutAuthentication(httptest.NewRequest("GET", "/audit"+tt.query, nil))
+			context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), tt.identity))
+			c.Find(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("AuditController_Find() = %v, want %v", foundStatus, tt.expectedStatus)
+				return
+			}
+			if foundFilter != tt.expectedFilter {
+				t.Errorf("AuditController_Find() filter = %+v, want %+v", foundFilter, tt.expectedFilter)
+			}
+		})
+	}
+}
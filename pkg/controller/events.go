@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const HeaderLastEventId = "Last-Event-ID"
+
+// how often a comment is sent on idle streams, so proxies keep the connection open
+const eventsHeartbeatInterval = 15 * time.Second
+
+// how long clients wait before they resume again when every stream for resuming clients is taken
+const eventsRetryAfter = 5 * time.Second
+
+// Events streams the changes of the articles of the tenant as server-sent events. Clients resume after
+// a reconnect by sending the id of the last received event in the Last-Event-ID header.
+func (c *ArticleController) Events(context *gin.Context) {
+	if !c.authorize(context, authz.ActionRead, "") {
+		return
+	}
+
+	var watcher db.ArticleEventWatcher = c.ArticleDbHandler
+	if c.ArticleEvents != nil {
+		watcher = c.ArticleEvents
+	}
+	events, err := watcher.Watch(context.Request.Context(), context.GetHeader(HeaderLastEventId))
+	if err != nil {
+		if errors.Is(err, db.ErrResumeFailed) {
+			c.handleError(context, err, http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, db.ErrTooManyStreams) {
+			context.Header("Retry-After", strconv.Itoa(int(eventsRetryAfter.Seconds())))
+			c.handleError(context, err, http.StatusServiceUnavailable)
+			return
+		}
+		c.handleError(context, err, http.StatusInternalServerError)
+		return
+	}
+
+	context.Header("Content-Type", "text/event-stream")
+	context.Header("Cache-Control", "no-cache")
+	context.Header("Connection", "keep-alive")
+	context.Header("X-Accel-Buffering", "no") // disables response buffering of nginx
+	context.Status(http.StatusOK)
+	context.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			context.Render(-1, sse.Event{Id: event.Id, Event: event.Type, Data: event})
+		case <-heartbeat.C:
+			fmt.Fprint(context.Writer, ": heartbeat\n\n")
+		case <-context.Request.Context().Done():
+			return
+		case <-c.EventsDone:
+			return
+		}
+		context.Writer.Flush()
+	}
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArticleController_Events(t *testing.T) {
+	tests := []struct {
+		name           string
+		lastEventId    string
+		watchErr       error
+		expectedStatus int
+		expectedBody   []string
+	}{
+		{name: "success", expectedStatus: http.StatusOK, expectedBody: []string{"id:token-2", "event:created", `"type":"created"`}},
+		{name: "Successfully resume after the last event", lastEventId: "token-1", expectedStatus: http.StatusOK, expectedBody: []string{"id:token-2"}},
+		{name: "Prevent resuming from lost events", lastEventId: "lost", watchErr: db.ErrResumeFailed, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent more resuming clients than change streams", lastEventId: "old", watchErr: db.ErrTooManyStreams, expectedStatus: http.StatusServiceUnavailable},
+		{name: "internal error - watch failure", watchErr: errors.New("test failure"), expectedStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var foundResumeAfter string
+			c := &ArticleController{ArticleDbHandler: &mocks.MockArticleDbHandler{WatchFunc: func(ctx context.Context, resumeAfter string) (<-chan db.ArticleEvent, error) {
+				foundResumeAfter = resumeAfter
+				if tt.watchErr != nil {
+					return nil, tt.watchErr
+				}
+				events := make(chan db.ArticleEvent, 1)
+				events <- db.ArticleEvent{Id: "token-2", Type: db.ArticleEventCreated, ArticleId: primitive.NewObjectID(), Title: "Test_Title"}
+				close(events)
+				return events, nil
+			}}}
+
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Request = httptest.NewRequest("GET", "/article/events", nil)
+			if tt.lastEventId != "" {
+				context.Request.Header.Set(HeaderLastEventId, tt.lastEventId)
+			}
+			c.Events(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_Events() = %v, want %v", foundStatus, tt.expectedStatus)
+				return
+			}
+			if foundResumeAfter != tt.lastEventId {
+				t.Errorf("ArticleController_Events() resumed after %v, want %v", foundResumeAfter, tt.lastEventId)
+			}
+			for _, expected := range tt.expectedBody {
+				if !strings.Contains(recorder.Body.String(), expected) {
+					t.Errorf("ArticleController_Events() body = %q, want to contain %q", recorder.Body.String(), expected)
+				}
+			}
+		})
+	}
+}
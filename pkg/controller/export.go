@@ -0,0 +1,373 @@
+package controller
+
+import (
+	"archive/zip"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+	ExportFormatZIP    = "zip" // the articles as NDJSON manifest with their images
+)
+
+// the name of the manifest in ZIP exports
+const exportManifest = "articles.ndjson"
+
+// the columns of CSV exports; the import ignores the ones after expiryPolicy
+var exportColumns = []string{"title", "expirationDate", "description", "expiryPolicy", "id", "authorId", "renewals", "images"}
+
+// ExportedArticle is one article of an export; the images are the paths of the image files in the ZIP export
+type ExportedArticle struct {
+	Id             string    `json:"id"`
+	AuthorId       string    `json:"authorId,omitempty"`
+	Title          string    `json:"title"`
+	ExpirationDate time.Time `json:"expirationDate"`
+	Description    string    `json:"description"`
+	ExpiryPolicy   string    `json:"expiryPolicy,omitempty"`
+	Renewals       int       `json:"renewals,omitempty"`
+	Images         []string  `json:"images,omitempty"`
+}
+
+// Export streams the articles of the tenant as NDJSON, CSV or ZIP (format query parameter; NDJSON by default).
+// The query parameters withImages, includeExpired and expiresFrom and expiresTo (RFC 3339) narrow them down.
+// Once streaming started errors can not be reported anymore, so the response is cut off instead.
+func (c *ArticleController) Export(context *gin.Context) {
+	if !c.authorize(context, authz.ActionRead, "") {
+		return
+	}
+
+	filter, err := parseExportFilter(context)
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	format := context.DefaultQuery("format", ExportFormatNDJSON)
+	exporter, err := c.newExporter(context.Request.Context(), format)
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+	defer exporter.release()
+
+	// the response starts with the first article, so failing queries are still reported
+	started := false
+	start := func() error {
+		if err := exporter.start(context.Writer); err != nil {
+			return err
+		}
+		context.Header("Content-Type", exporter.contentType())
+		context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="articles.%s"`, format))
+		context.Status(http.StatusOK)
+		started = true
+		return nil
+	}
+
+	err = c.ArticleDbHandler.Export(context.Request.Context(), filter, func(article db.ArticleDb) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return exporter.write(article)
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = exporter.close()
+	}
+
+	if err != nil {
+		if !started {
+			c.handleError(context, err, http.StatusInternalServerError)
+			return
+		}
+		logging.FromContext(context.Request.Context(), c.Logger).WithError(err).Error("export cut off")
+		context.Abort()
+		return
+	}
+	context.Writer.Flush()
+}
+
+// ExportTo writes the articles of the tenant of the context to w in the format, like Export; used by the export command
+func (c *ArticleController) ExportTo(ctx context.Context, w io.Writer, format string, filter db.ExportFilter) error {
+	exporter, err := c.newExporter(ctx, format)
+	if err != nil {
+		return err
+	}
+	defer exporter.release()
+
+	if err := exporter.start(w); err != nil {
+		return err
+	}
+	if err := c.ArticleDbHandler.Export(ctx, filter, exporter.write); err != nil {
+		return err
+	}
+	return exporter.close()
+}
+
+func (c *ArticleController) newExporter(ctx context.Context, format string) (articleExporter, error) {
+	switch format {
+	case ExportFormatNDJSON:
+		return &ndjsonExporter{}, nil
+	case ExportFormatCSV:
+		return &csvExporter{}, nil
+	case ExportFormatZIP:
+		return &zipExporter{imageDirectory: c.ImageDirectory, logger: logging.FromContext(ctx, c.Logger)}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+func parseExportFilter(context *gin.Context) (db.ExportFilter, error) {
+	filter := db.ExportFilter{}
+	var err error
+
+	if withImages := context.Query("withImages"); withImages != "" {
+		value, err := strconv.ParseBool(withImages)
+		if err != nil {
+			return filter, err
+		}
+		filter.WithImage = &value
+	}
+	if includeExpired := context.Query("includeExpired"); includeExpired != "" {
+		if filter.IncludeExpired, err = strconv.ParseBool(includeExpired); err != nil {
+			return filter, err
+		}
+	}
+	if from := context.Query("expiresFrom"); from != "" {
+		if filter.ExpiresFrom, err = time.Parse(time.RFC3339, from); err != nil {
+			return filter, err
+		}
+	}
+	if to := context.Query("expiresTo"); to != "" {
+		if filter.ExpiresTo, err = time.Parse(time.RFC3339, to); err != nil {
+			return filter, err
+		}
+	}
+	if !filter.ExpiresFrom.IsZero() && !filter.ExpiresTo.IsZero() && !filter.ExpiresFrom.Before(filter.ExpiresTo) {
+		return filter, errors.New("expiresFrom is not before expiresTo")
+	}
+	return filter, nil
+}
+
+func exportedArticleOf(article db.ArticleDb) ExportedArticle {
+	exported := ExportedArticle{
+		Id:             article.Id.Hex(),
+		AuthorId:       article.AuthorId,
+		Title:          article.Title,
+		ExpirationDate: article.ExpirationDate,
+		Description:    article.Description,
+		ExpiryPolicy:   article.ExpiryPolicy,
+		Renewals:       article.Renewals,
+	}
+	for _, imagePath := range article.ImageFilePaths {
+		exported.Images = append(exported.Images, exportedImagePath(article, imagePath))
+	}
+	return exported
+}
+
+// The path of an image in the ZIP export
+func exportedImagePath(article db.ArticleDb, imagePath string) string {
+	return path.Join("images", article.Id.Hex(), filepath.Base(imagePath))
+}
+
+type articleExporter interface {
+	contentType() string
+	start(w io.Writer) error // must not write yet, so failures can still be reported
+	write(article db.ArticleDb) error
+	close() error // completes the export
+	release()     // frees the resources of the export, whether it completed or not
+}
+
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExporter) contentType() string {
+	return "application/x-ndjson"
+}
+
+func (e *ndjsonExporter) start(w io.Writer) error {
+	e.encoder = json.NewEncoder(w)
+	return nil
+}
+
+func (e *ndjsonExporter) write(article db.ArticleDb) error {
+	return e.encoder.Encode(exportedArticleOf(article))
+}
+
+func (e *ndjsonExporter) close() error {
+	return nil
+}
+
+func (e *ndjsonExporter) release() {}
+
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func (e *csvExporter) contentType() string {
+	return "text/csv"
+}
+
+func (e *csvExporter) start(w io.Writer) error {
+	e.writer = csv.NewWriter(w)
+	return e.writer.Write(exportColumns)
+}
+
+func (e *csvExporter) write(article db.ArticleDb) error {
+	exported := exportedArticleOf(article)
+	return e.writer.Write([]string{
+		escapeCell(exported.Title),
+		exported.ExpirationDate.Format(time.RFC3339),
+		escapeCell(exported.Description),
+		exported.ExpiryPolicy,
+		exported.Id,
+		escapeCell(exported.AuthorId),
+		strconv.Itoa(exported.Renewals),
+		strings.Join(exported.Images, " "),
+	})
+}
+
+// Whether spreadsheets would evaluate the cell as a formula; quoted formulas count too, so unescapeCell
+// only strips the quotes escapeCell added
+func isFormula(cell string) bool {
+	if cell == "" {
+		return false
+	}
+	switch cell[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return true
+	case '\'':
+		return isFormula(cell[1:])
+	}
+	return false
+}
+
+// Prefixes formulas with a quote, so spreadsheets show them as text
+func escapeCell(cell string) string {
+	if isFormula(cell) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// Reverts escapeCell
+func unescapeCell(cell string) string {
+	if strings.HasPrefix(cell, "'") && isFormula(cell[1:]) {
+		return cell[1:]
+	}
+	return cell
+}
+
+func (e *csvExporter) close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvExporter) release() {}
+
+// zipExporter writes the images while it goes; the manifest is collected in a temporary file and
+// written last, so neither the articles nor the images are held in memory
+type zipExporter struct {
+	imageDirectory string
+	logger         *logrus.Entry
+	writer         *zip.Writer
+	manifest       *os.File
+	encoder        *json.Encoder
+}
+
+func (e *zipExporter) contentType() string {
+	return "application/zip"
+}
+
+func (e *zipExporter) start(w io.Writer) error {
+	manifest, err := os.CreateTemp("", "export-*.ndjson")
+	if err != nil {
+		return err
+	}
+	e.manifest = manifest
+	e.encoder = json.NewEncoder(manifest)
+	e.writer = zip.NewWriter(w)
+	return nil
+}
+
+func (e *zipExporter) write(article db.ArticleDb) error {
+	exported := exportedArticleOf(article)
+	exported.Images = nil
+
+	for _, imagePath := range article.ImageFilePaths {
+		archivePath := exportedImagePath(article, imagePath)
+		if err := e.writeImage(imagePath, archivePath); err != nil {
+			// the article is still exported, only without the image
+			e.logger.WithError(err).WithField("path", imagePath).Warn("image not exported")
+			continue
+		}
+		exported.Images = append(exported.Images, archivePath)
+	}
+	return e.encoder.Encode(exported)
+}
+
+func (e *zipExporter) writeImage(imagePath string, archivePath string) error {
+	// only files of the image directory are exported
+	relative, err := filepath.Rel(e.imageDirectory, imagePath)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return errors.New("the image is not in the image directory")
+	}
+
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// images are compressed already
+	w, err := e.writer.CreateHeader(&zip.FileHeader{Name: archivePath, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// Appends the manifest; a ZIP export that is cut off before lacks the central directory, so it can not be opened
+func (e *zipExporter) close() error {
+	if _, err := e.manifest.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w, err := e.writer.Create(exportManifest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, e.manifest); err != nil {
+		return err
+	}
+	return e.writer.Close()
+}
+
+func (e *zipExporter) release() {
+	if e.manifest != nil {
+		e.manifest.Close()
+		os.Remove(e.manifest.Name())
+	}
+}
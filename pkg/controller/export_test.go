@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"archive/zip"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func createExportContext(query string) (*gin.Context, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request = httptest.NewRequest(http.MethodGet, "/article/export"+query, nil)
+	return context, recorder
+}
+
+func TestArticleController_Export(t *testing.T) {
+	expirationDate := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	articles := []db.ArticleDb{
+		{Id: primitive.NewObjectID(), Title: "First", Description: "First, with comma", ExpirationDate: expirationDate, ExpiryPolicy: db.ExpiryPolicyDelete},
+		{Id: primitive.NewObjectID(), Title: "Second", Description: "Second_Description", ExpirationDate: expirationDate, AuthorId: "editor-1"},
+	}
+	export := func(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error {
+		for _, article := range articles {
+			if err := handle(article); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t.Run("Successfully export NDJSON", func(t *testing.T) {
+		c := &ArticleController{ArticleDbHandler: &mocks.MockArticleDbHandler{ExportFunc: export}}
+		context, recorder := createExportContext("")
+		c.Export(context)
+
+		if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("ArticleController_Export() = %v %v, want %v", recorder.Code, recorder.Header().Get("Content-Type"), http.StatusOK)
+		}
+		lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("ArticleController_Export() lines = %v, want %v", len(lines), 2)
+		}
+		var exported ExportedArticle
+		if err := json.Unmarshal([]byte(lines[1]), &exported); err != nil || exported.Id != articles[1].Id.Hex() || exported.AuthorId != "editor-1" {
+			t.Errorf("ArticleController_Export() article = %+v, %v", exported, err)
+		}
+	})
+
+	t.Run("Successfully export CSV that can be imported", func(t *testing.T) {
+		c := &ArticleController{ArticleDbHandler: &mocks.MockArticleDbHandler{ExportFunc: export}}
+		context, recorder := createExportContext("?format=csv")
+		c.Export(context)
+
+		body := recorder.Body.String()
+		records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+		if err != nil || len(records) != 3 || records[1][2] != "First, with comma" {
+			t.Fatalf("ArticleController_Export() = %v, %v", records, err)
+		}
+
+		var imported []NewArticleBody
+		err = readCSV(strings.NewReader(body), func(line int, article NewArticleBody, err error) error {
+			imported = append(imported, article)
+			return err
+		})
+		if err != nil || len(imported) != 2 || !imported[1].ExpirationDate.Equal(expirationDate) {
+			t.Errorf("readCSV() of the export = %+v, %v", imported, err)
+		}
+	})
+
+	t.Run("Successfully escape formulas in CSV", func(t *testing.T) {
+		formulas := []db.ArticleDb{{Id: primitive.NewObjectID(), Title: "=HYPERLINK(\"http://evil\")", Description: "'-1", ExpirationDate: expirationDate}}
+		c := &ArticleController{ArticleDbHandler: &mocks.MockArticleDbHandler{ExportFunc: func(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error {
+			return handle(formulas[0])
+		}}}
+		context, recorder := createExportContext("?format=csv")
+		c.Export(context)
+
+		body := recorder.Body.String()
+		records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+		if err != nil || len(records) != 2 || records[1][0] != "'=HYPERLINK(\"http://evil\")" || records[1][2] != "''-1" {
+			t.Fatalf("ArticleController_Export() = %v, %v", records, err)
+		}
+
+		var imported []NewArticleBody
+		err = readCSV(strings.NewReader(body), func(line int, article NewArticleBody, err error) error {
+			imported = append(imported, article)
+			return err
+		})
+		if err != nil || len(imported) != 1 || imported[0].Title != formulas[0].Title || imported[0].Description != formulas[0].Description {
+			t.Errorf("readCSV() of the export = %+v, %v", imported, err)
+		}
+	})
+
+	t.Run("Successfully filter", func(t *testing.T) {
+		var found db.ExportFilter
+		c := &ArticleController{ArticleDbHandler: &mocks.MockArticleDbHandler{ExportFunc: func(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error {
+			found = filter
+			return nil
+		}}}
+		context, recorder := createExportContext("?withImages=false&includeExpired=true&expiresFrom=2023-11-01T00:00:00Z&expiresTo=2023-12-01T00:00:00Z")
+		c.Export(context)
+
+		if recorder.Code != http.StatusOK || found.WithImage == nil || *found.WithImage || !found.IncludeExpired ||
+			!found.ExpiresFrom.Equal(time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)) || !found.ExpiresTo.Equal(time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("ArticleController_Export() = %v, filter %+v", recorder.Code, found)
+		}
+	})
+
+	for _, tt := range []struct {
+		name     string
+		query    string
+		err      error
+		expected int
+	}{
+		{name: "Prevent unknown formats", query: "?format=xml", expected: http.StatusBadRequest},
+		{name: "Prevent invalid filters", query: "?withImages=maybe", expected: http.StatusBadRequest},
+		{name: "Prevent empty expiration range", query: "?expiresFrom=2023-12-01T00:00:00Z&expiresTo=2023-11-01T00:00:00Z", expected: http.StatusBadRequest},
+		{name: "Prevent hiding failing queries", query: "", err: errors.New("db down"), expected: http.StatusInternalServerError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ArticleController{ArticleDbHandler: &mocks.MockArticleDbHandler{ExportFunc: func(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error {
+				return tt.err
+			}}}
+			context, _ := createExportContext(tt.query)
+			c.Export(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expected {
+				t.Errorf("ArticleController_Export() status = %v, want %v", foundStatus, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArticleController_Export_ZIP(t *testing.T) {
+	imageDirectory := filepath.Join(t.TempDir(), "images")
+	if err := os.MkdirAll(filepath.Join(imageDirectory, "default"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	imagePath := filepath.Join(imageDirectory, "default", "image-1")
+	if err := os.WriteFile(imagePath, []byte("image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(outside, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	article := db.ArticleDb{Id: primitive.NewObjectID(), Title: "Title", ImageFilePaths: []string{imagePath, outside, filepath.Join(imageDirectory, "default", "missing")}}
+	c := &ArticleController{
+		ImageDirectory: imageDirectory,
+		ArticleDbHandler: &mocks.MockArticleDbHandler{ExportFunc: func(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error {
+			return handle(article)
+		}},
+	}
+	context, recorder := createExportContext("?format=zip")
+	c.Export(context)
+
+	archive, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+	if err != nil {
+		t.Fatalf("ArticleController_Export() is no zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range archive.File {
+		r, _ := file.Open()
+		content, _ := io.ReadAll(r)
+		r.Close()
+		files[file.Name] = string(content)
+	}
+
+	exportedImage := "images/" + article.Id.Hex() + "/image-1"
+	if len(files) != 2 || files[exportedImage] != "image" {
+		t.Errorf("ArticleController_Export() files = %v, want the manifest and %v", files, exportedImage)
+	}
+	var exported ExportedArticle
+	if err := json.Unmarshal([]byte(files[exportManifest]), &exported); err != nil || len(exported.Images) != 1 || exported.Images[0] != exportedImage {
+		t.Errorf("ArticleController_Export() manifest = %+v, %v", exported, err)
+	}
+}
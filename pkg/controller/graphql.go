@@ -0,0 +1,566 @@
+package controller
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// the largest body; queries are small
+	maxGraphqlBodySize = 1024 * 1024
+	// the page size of articles without first, and the largest one
+	defaultGraphqlPageSize = 20
+	maxGraphqlPageSize     = 100
+	// the limits of queries when the controller names none
+	defaultGraphqlMaxDepth      = 10
+	defaultGraphqlMaxComplexity = 2000
+	defaultGraphqlMaxMutations  = 10
+)
+
+// The codes of the extensions of GraphQL errors
+const (
+	GraphqlBadUserInput        = "BAD_USER_INPUT"
+	GraphqlUnauthenticated     = "UNAUTHENTICATED"
+	GraphqlForbidden           = "FORBIDDEN"
+	GraphqlNotFound            = "NOT_FOUND"
+	GraphqlQueryTooComplex     = "QUERY_TOO_COMPLEX"
+	GraphqlTooManyRequests     = "TOO_MANY_REQUESTS"
+	GraphqlInternalServerError = "INTERNAL_SERVER_ERROR"
+)
+
+// GraphqlController serves the articles over GraphQL with the logic of the ArticleController
+type GraphqlController struct {
+	ArticleController   *ArticleController
+	Logger              *logrus.Logger
+	MaxDepth            int  // the deepest nesting of fields; 0 is defaultGraphqlMaxDepth
+	MaxComplexity       int  // the most fields, multiplied by the page sizes; 0 is defaultGraphqlMaxComplexity
+	MaxMutations        int  // the most mutation fields of an operation, aliases included; 0 is defaultGraphqlMaxMutations
+	CredentialsRequired bool // mutations reject anonymous requests, like the create and modify routes with auth.Required
+	// optional; charges each mutation to the create budget, as the route only charges the request to the read budget
+	Limit CallLimiter
+
+	once      sync.Once
+	schema    graphql.Schema
+	schemaErr error
+}
+
+// GraphqlRequest is a query or mutation with its variables
+type GraphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Handle runs the operation of the request. Requests that can not be read are answered with 400; every
+// operation that was read is answered with 200 and the data and errors of GraphQL.
+func (c *GraphqlController) Handle(context *gin.Context) {
+	schema, err := c.getSchema()
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusInternalServerError)
+		return
+	}
+
+	request := &GraphqlRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(context.Writer, context.Request.Body, maxGraphqlBodySize)).Decode(request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			handleError(context, c.Logger, err, http.StatusRequestEntityTooLarge)
+			return
+		}
+		handleError(context, c.Logger, err, http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(request.Query) == "" {
+		handleError(context, c.Logger, errors.New("the query is empty"), http.StatusBadRequest)
+		return
+	}
+
+	document, err := parseGraphql(request.Query)
+	if err != nil {
+		context.JSON(http.StatusOK, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	if validation := graphql.ValidateDocument(&schema, document, nil); !validation.IsValid {
+		context.JSON(http.StatusOK, &graphql.Result{Errors: validation.Errors})
+		return
+	}
+
+	// without the operation, e.g. for an unknown operation name, Execute answers the error
+	if operation := operationOf(document, request.OperationName); operation != nil {
+		if operation.Operation == ast.OperationTypeMutation && c.CredentialsRequired && auth.FromContext(context.Request.Context()) == nil {
+			handleError(context, c.Logger, nil, http.StatusUnauthorized)
+			return
+		}
+		if err := c.checkLimits(document, operation, request.Variables); err != nil {
+			logging.FromContext(context.Request.Context(), c.Logger).WithError(err).Info("query rejected")
+			located := gqlerrors.NewError(err.Error(), []ast.Node{operation}, "", nil, nil, err)
+			context.JSON(http.StatusOK, &graphql.Result{Errors: gqlerrors.FormatErrors(located)})
+			return
+		}
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       requestContext(context),
+	})
+	context.JSON(http.StatusOK, result)
+}
+
+func parseGraphql(query string) (*ast.Document, error) {
+	return parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"})})
+}
+
+// The operation the request runs; nil when there is none of the name, or several without a name
+func operationOf(document *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = operation
+		} else if operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return found
+}
+
+// Rejects operations that nest too deep, select too many fields or run too many mutations, before anything is resolved
+func (c *GraphqlController) checkLimits(document *ast.Document, operation *ast.OperationDefinition, variables map[string]interface{}) error {
+	maxDepth, maxComplexity, maxMutations := c.MaxDepth, c.MaxComplexity, c.MaxMutations
+	if maxDepth <= 0 {
+		maxDepth = defaultGraphqlMaxDepth
+	}
+	if maxComplexity <= 0 {
+		maxComplexity = defaultGraphqlMaxComplexity
+	}
+	if maxMutations <= 0 {
+		maxMutations = defaultGraphqlMaxMutations
+	}
+
+	m := &measurer{fragments: map[string]*ast.FragmentDefinition{}, measured: map[string][2]int{}, counted: map[string]int{}, variables: variables}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	depth, complexity := m.measure(operation.SelectionSet)
+	if depth > maxDepth {
+		return &graphqlError{message: fmt.Sprintf("the query has a depth of %d, at most %d is allowed", depth, maxDepth), code: GraphqlQueryTooComplex, status: http.StatusBadRequest}
+	}
+	if complexity > maxComplexity {
+		return &graphqlError{message: fmt.Sprintf("the query has a complexity of %d, at most %d is allowed", complexity, maxComplexity), code: GraphqlQueryTooComplex, status: http.StatusBadRequest}
+	}
+	if operation.Operation == ast.OperationTypeMutation {
+		if mutations := m.count(operation.SelectionSet); mutations > maxMutations {
+			return &graphqlError{message: fmt.Sprintf("the mutation has %d fields, at most %d are allowed", mutations, maxMutations), code: GraphqlQueryTooComplex, status: http.StatusBadRequest}
+		}
+	}
+	return nil
+}
+
+// measurer computes the depth and the complexity of selections. Every field costs 1 plus the cost of its
+// selections, which count once per article of a page. Introspection is not counted, as it is bounded by the schema.
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition
+	measured  map[string][2]int // the fragments are measured once, so spreading them repeatedly stays cheap
+	counted   map[string]int    // the fields of the fragments, counted once
+	variables map[string]interface{}
+}
+
+func (m *measurer) measure(selections *ast.SelectionSet) (int, int) {
+	if selections == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+	for _, selection := range selections.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			d, c = m.measure(selection.SelectionSet)
+			d, c = d+1, 1+m.pageSize(selection)*c
+		case *ast.InlineFragment:
+			d, c = m.measure(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			d, c = m.measureFragment(selection.Name.Value)
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity += c
+	}
+	return depth, complexity
+}
+
+func (m *measurer) measureFragment(name string) (int, int) {
+	if measured, ok := m.measured[name]; ok {
+		return measured[0], measured[1]
+	}
+	fragment := m.fragments[name]
+	if fragment == nil {
+		return 0, 0
+	}
+	// validation rejects cycles of fragments, so the fragment is measured before it is spread again
+	depth, complexity := m.measure(fragment.SelectionSet)
+	m.measured[name] = [2]int{depth, complexity}
+	return depth, complexity
+}
+
+// The fields of the selections, without nested ones; every alias and every spread of a fragment counts, as each
+// of them may run the mutation again
+func (m *measurer) count(selections *ast.SelectionSet) int {
+	if selections == nil {
+		return 0
+	}
+
+	fields := 0
+	for _, selection := range selections.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if !strings.HasPrefix(selection.Name.Value, "__") {
+				fields++
+			}
+		case *ast.InlineFragment:
+			fields += m.count(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			counted, ok := m.counted[name]
+			if !ok {
+				if fragment := m.fragments[name]; fragment != nil {
+					counted = m.count(fragment.SelectionSet)
+				}
+				m.counted[name] = counted
+			}
+			fields += counted
+		}
+	}
+	return fields
+}
+
+// The articles a field selects at most: the first argument of the articles, 1 for other fields
+func (m *measurer) pageSize(field *ast.Field) int {
+	if field.Name.Value != "articles" {
+		return 1
+	}
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		var value interface{}
+		switch argument := argument.Value.(type) {
+		case *ast.IntValue:
+			value = argument.Value
+		case *ast.Variable:
+			value = m.variables[argument.Name.Value]
+		}
+		// larger pages are rejected by the resolver
+		if size, err := strconv.Atoi(fmt.Sprint(value)); err == nil && size > 0 {
+			if size > maxGraphqlPageSize {
+				return maxGraphqlPageSize
+			}
+			return size
+		}
+	}
+	return defaultGraphqlPageSize
+}
+
+// graphqlError is a failed field with the code and the HTTP status of its extensions
+type graphqlError struct {
+	message string
+	code    string
+	status  int
+}
+
+func (e *graphqlError) Error() string {
+	return e.message
+}
+
+func (e *graphqlError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code, "status": e.status}
+}
+
+// Maps the error of a resolver to the GraphQL error with the HTTP status of the REST endpoints
+func (c *GraphqlController) graphqlError(ctx context.Context, field string, err error) error {
+	var gqlErr *graphqlError
+	if errors.As(err, &gqlErr) {
+		return err
+	}
+
+	status := statusOf(err)
+	entry := logging.FromContext(ctx, c.Logger).WithError(err).WithFields(logrus.Fields{"field": field, "status": status})
+	if status >= http.StatusInternalServerError {
+		entry.Error("field failed")
+		return &graphqlError{message: http.StatusText(http.StatusInternalServerError), code: GraphqlInternalServerError, status: http.StatusInternalServerError}
+	}
+	entry.Info("field rejected")
+
+	code := GraphqlBadUserInput
+	switch status {
+	case http.StatusUnauthorized:
+		code = GraphqlUnauthenticated
+	case http.StatusForbidden:
+		code = GraphqlForbidden
+	case http.StatusNotFound:
+		code = GraphqlNotFound
+	case http.StatusTooManyRequests:
+		code = GraphqlTooManyRequests
+	}
+
+	// like the JSON-RPC errors, client errors tell the reason
+	message := http.StatusText(status)
+	var requestErr *requestError
+	if errors.As(err, &requestErr) && requestErr.err != nil {
+		message = requestErr.err.Error()
+	}
+	return &graphqlError{message: message, code: code, status: status}
+}
+
+func badUserInput(message string) error {
+	return &graphqlError{message: message, code: GraphqlBadUserInput, status: http.StatusBadRequest}
+}
+
+// The schema is built once, as the resolvers use the controller
+func (c *GraphqlController) getSchema() (graphql.Schema, error) {
+	c.once.Do(func() {
+		c.schema, c.schemaErr = c.newSchema()
+	})
+	return c.schema, c.schemaErr
+}
+
+func (c *GraphqlController) newSchema() (graphql.Schema, error) {
+	articleType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Article",
+		Fields: graphql.Fields{
+			"id":             articleField(graphql.NewNonNull(graphql.ID), func(a *db.ArticleDb) interface{} { return a.Id.Hex() }),
+			"title":          articleField(graphql.NewNonNull(graphql.String), func(a *db.ArticleDb) interface{} { return a.Title }),
+			"description":    articleField(graphql.NewNonNull(graphql.String), func(a *db.ArticleDb) interface{} { return a.Description }),
+			"expirationDate": articleField(graphql.NewNonNull(graphql.DateTime), func(a *db.ArticleDb) interface{} { return a.ExpirationDate }),
+			"expiryPolicy":   articleField(graphql.String, func(a *db.ArticleDb) interface{} { return a.ExpiryPolicy }),
+			"authorId":       articleField(graphql.String, func(a *db.ArticleDb) interface{} { return a.AuthorId }),
+			"renewals":       articleField(graphql.NewNonNull(graphql.Int), func(a *db.ArticleDb) interface{} { return a.Renewals }),
+			"images": articleField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), func(a *db.ArticleDb) interface{} {
+				if a.ImageFilePaths == nil {
+					return []string{}
+				}
+				return a.ImageFilePaths
+			}),
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ArticleConnection",
+		Description: "A page of articles in the order of their creation; endCursor is the after of the next page",
+		Fields: graphql.Fields{
+			"items":       &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(articleType)))},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	inputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ArticleInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":          &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"expirationDate": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+			"expiryPolicy":   &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "delete, archive or hide"},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"article": &graphql.Field{
+				Type:        articleType,
+				Description: "The article; null when there is none",
+				Args:        graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     c.resolveArticle,
+			},
+			"articles": &graphql.Field{
+				Type:        graphql.NewNonNull(connectionType),
+				Description: "The articles that pass the filters, a page at a time",
+				Args: graphql.FieldConfigArgument{
+					"withImages":     &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"expiresFrom":    &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"expiresTo":      &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"includeExpired": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+					"first":          &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphqlPageSize},
+					"after":          &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: c.resolveArticles,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createArticle": &graphql.Field{
+				Type:    graphql.NewNonNull(articleType),
+				Args:    graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)}},
+				Resolve: c.resolveCreateArticle,
+			},
+			"updateArticle": &graphql.Field{
+				Type:        graphql.NewNonNull(articleType),
+				Description: "Replaces the content of the article; the previous version stays available as a revision",
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)},
+				},
+				Resolve: c.resolveUpdateArticle,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// A field of the Article type; the source of articles is a *db.ArticleDb
+func articleField(fieldType graphql.Output, value func(article *db.ArticleDb) interface{}) *graphql.Field {
+	return &graphql.Field{Type: fieldType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		article, ok := p.Source.(*db.ArticleDb)
+		if !ok {
+			return nil, nil
+		}
+		return value(article), nil
+	}}
+}
+
+func (c *GraphqlController) resolveArticle(p graphql.ResolveParams) (interface{}, error) {
+	articleId, err := primitive.ObjectIDFromHex(p.Args["id"].(string))
+	if err != nil {
+		return nil, badUserInput(err.Error())
+	}
+
+	article, err := c.ArticleController.get(p.Context, articleId)
+	if statusOf(err) == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, c.graphqlError(p.Context, "article", err)
+	}
+	return article, nil
+}
+
+func (c *GraphqlController) resolveArticles(p graphql.ResolveParams) (interface{}, error) {
+	first, _ := p.Args["first"].(int)
+	if first < 1 || first > maxGraphqlPageSize {
+		return nil, badUserInput(fmt.Sprintf("first is 1 to %d", maxGraphqlPageSize))
+	}
+
+	var after primitive.ObjectID
+	if cursor, ok := p.Args["after"].(string); ok {
+		var err error
+		if after, err = primitive.ObjectIDFromHex(cursor); err != nil {
+			return nil, badUserInput("after is the endCursor of a page")
+		}
+	}
+
+	filter := db.ExportFilter{}
+	if withImages, ok := p.Args["withImages"].(bool); ok {
+		filter.WithImage = &withImages
+	}
+	filter.ExpiresFrom, _ = p.Args["expiresFrom"].(time.Time)
+	filter.ExpiresTo, _ = p.Args["expiresTo"].(time.Time)
+	filter.IncludeExpired, _ = p.Args["includeExpired"].(bool)
+
+	if err := c.ArticleController.permit(p.Context, authz.ActionRead, ""); err != nil {
+		return nil, c.graphqlError(p.Context, "articles", err)
+	}
+
+	// one more than the page tells whether there is a next page
+	articles, err := c.ArticleController.ArticleDbHandler.FindPage(p.Context, filter, after, int64(first)+1)
+	if err != nil {
+		return nil, c.graphqlError(p.Context, "articles", fail(http.StatusInternalServerError, err))
+	}
+
+	hasNextPage := len(articles) > first
+	if hasNextPage {
+		articles = articles[:first]
+	}
+	items := make([]*db.ArticleDb, 0, len(articles))
+	for i := range articles {
+		items = append(items, &articles[i])
+	}
+
+	var endCursor interface{}
+	if len(items) > 0 {
+		endCursor = items[len(items)-1].Id.Hex()
+	}
+	return map[string]interface{}{"items": items, "endCursor": endCursor, "hasNextPage": hasNextPage}, nil
+}
+
+func (c *GraphqlController) resolveCreateArticle(p graphql.ResolveParams) (interface{}, error) {
+	if err := charge(p.Context, c.Limit, BudgetCreate); err != nil {
+		return nil, c.graphqlError(p.Context, "createArticle", err)
+	}
+
+	created, err := c.ArticleController.create(p.Context, articleInput(p.Args["input"]))
+	if err != nil {
+		return nil, c.graphqlError(p.Context, "createArticle", err)
+	}
+	return created, nil
+}
+
+func (c *GraphqlController) resolveUpdateArticle(p graphql.ResolveParams) (interface{}, error) {
+	articleId, err := primitive.ObjectIDFromHex(p.Args["id"].(string))
+	if err != nil {
+		return nil, badUserInput(err.Error())
+	}
+
+	// changes are charged like the modify routes
+	if err := charge(p.Context, c.Limit, BudgetCreate); err != nil {
+		return nil, c.graphqlError(p.Context, "updateArticle", err)
+	}
+
+	updated, err := c.ArticleController.edit(p.Context, articleId, articleInput(p.Args["input"]))
+	if err != nil {
+		return nil, c.graphqlError(p.Context, "updateArticle", err)
+	}
+	return updated, nil
+}
+
+// The body of the ArticleInput argument; it is validated like the body of POST /article
+func articleInput(input interface{}) NewArticleBody {
+	fields, _ := input.(map[string]interface{})
+	body := NewArticleBody{}
+	body.Title, _ = fields["title"].(string)
+	body.Description, _ = fields["description"].(string)
+	body.ExpirationDate, _ = fields["expirationDate"].(time.Time)
+	body.ExpiryPolicy, _ = fields["expiryPolicy"].(string)
+	return body
+}
@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGraphqlController_Handle(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	missingId := primitive.NewObjectID()
+	expirationDate := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := &mocks.MockArticleDbHandler{
+		InsertOneFunc: func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
+			return articleId, nil
+		},
+		FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+			if id == missingId {
+				return nil, nil
+			}
+			return &db.ArticleDb{Id: id, Title: "Test_Title", Description: "Test_Description", ExpirationDate: expirationDate, AuthorId: "editor-1", ImageFilePaths: []string{"images/default/1"}}, nil
+		},
+		UpdateOneFunc: func(ctx context.Context, id primitive.ObjectID, article db.ArticleDb) (bool, error) {
+			return true, nil
+		},
+		FindPageFunc: func(ctx context.Context, filter db.ExportFilter, after primitive.ObjectID, limit int64) ([]db.ArticleDb, error) {
+			if filter.WithImage != nil {
+				return nil, errors.New("failed")
+			}
+			articles := []db.ArticleDb{{Id: articleId, Title: "First"}, {Id: missingId, Title: "Second"}}
+			if int64(len(articles)) > limit {
+				articles = articles[:limit]
+			}
+			return articles, nil
+		},
+	}
+	viewer := &auth.Identity{Subject: "viewer-1", Roles: []string{authz.RoleViewer}}
+	editor := &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}
+	// allows as many calls as there are tokens
+	tokens := func(n int) CallLimiter {
+		return func(ctx context.Context, budget string, clientIp string) (bool, int) {
+			n--
+			return n >= 0, 30
+		}
+	}
+
+	tests := []struct {
+		name                string
+		identity            *auth.Identity
+		credentialsRequired bool
+		limit               CallLimiter
+		body                string
+		expectedStatus      int
+		expectedBody        string // compared as JSON
+	}{
+		{
+			name:           "Successfully get an article",
+			body:           `{"query": "{ article(id: \"` + articleId.Hex() + `\") { id title expirationDate images } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": {"article": {"id": "` + articleId.Hex() + `", "title": "Test_Title", "expirationDate": "2030-01-01T00:00:00Z", "images": ["images/default/1"]}}}`,
+		},
+		{
+			name:           "Successfully get null for a missing article",
+			body:           `{"query": "query Get($id: ID!) { article(id: $id) { title } }", "variables": {"id": "` + missingId.Hex() + `"}}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": {"article": null}}`,
+		},
+		{
+			name:           "Successfully find a page of articles",
+			body:           `{"query": "{ articles(first: 1) { items { title } endCursor hasNextPage } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": {"articles": {"items": [{"title": "First"}], "endCursor": "` + articleId.Hex() + `", "hasNextPage": true}}}`,
+		},
+		{
+			name:           "Successfully find the last page of articles",
+			body:           `{"query": "query Find($first: Int) { articles(first: $first) { items { title } hasNextPage } }", "variables": {"first": 2}}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": {"articles": {"items": [{"title": "First"}, {"title": "Second"}], "hasNextPage": false}}}`,
+		},
+		{
+			name:           "Successfully create an article",
+			body:           `{"query": "mutation { createArticle(input: {title: \"Test_Title\", description: \"Test_Description\", expirationDate: \"2030-01-01T00:00:00Z\"}) { id title expiryPolicy } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": {"createArticle": {"id": "` + articleId.Hex() + `", "title": "Test_Title", "expiryPolicy": "delete"}}}`,
+		},
+		{
+			name:           "Successfully update an article",
+			identity:       editor,
+			body:           `{"query": "mutation { updateArticle(id: \"` + articleId.Hex() + `\", input: {title: \"New_Title\", description: \"Test_Description\", expirationDate: \"2030-01-01T00:00:00Z\"}) { title images } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": {"updateArticle": {"title": "New_Title", "images": ["images/default/1"]}}}`,
+		},
+		{
+			name:           "Prevent invalid JSON",
+			body:           `{"query": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Prevent empty query",
+			body:           `{"query": " "}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Prevent unknown fields",
+			body:           `{"query": "{ article(id: \"` + articleId.Hex() + `\") { unknown } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": null, "errors": [{"message": "Cannot query field \"unknown\" on type \"Article\".", "locations": [{"line": 1, "column": 45}]}]}`,
+		},
+		{
+			name:           "Prevent too large pages",
+			body:           `{"query": "{ articles(first: 101) { hasNextPage } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": null, "errors": [{"message": "first is 1 to 100", "locations": [{"line": 1, "column": 3}], "path": ["articles"], "extensions": {"code": "BAD_USER_INPUT", "status": 400}}]}`,
+		},
+		{
+			name:           "Successfully resolve fragments",
+			body:           `{"query": "{ articles { items { ...a } } } fragment a on Article { ...b } fragment b on Article { title }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": {"articles": {"items": [{"title": "First"}, {"title": "Second"}]}}}`,
+		},
+		{
+			name:           "Prevent too complex queries",
+			body:           `{"query": "{ a: articles(first: 100) { items { id title description expirationDate images } } b: articles(first: 100) { items { id title description expirationDate images } } c: articles(first: 100) { items { id title description expirationDate images } } d: articles(first: 100) { items { id title description expirationDate images } } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": null, "errors": [{"message": "the query has a complexity of 2404, at most 2000 is allowed", "locations": [{"line": 1, "column": 1}], "extensions": {"code": "QUERY_TOO_COMPLEX", "status": 400}}]}`,
+		},
+		{
+			name:           "Prevent invalid article",
+			body:           `{"query": "mutation { createArticle(input: {title: \"\", description: \"Test_Description\", expirationDate: \"2030-01-01T00:00:00Z\"}) { id } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": null, "errors": [{"message": "Key: 'NewArticleBody.Title' Error:Field validation for 'Title' failed on the 'required' tag", "locations": [{"line": 1, "column": 12}], "path": ["createArticle"], "extensions": {"code": "BAD_USER_INPUT", "status": 400}}]}`,
+		},
+		{
+			name:           "Prevent viewer creating",
+			identity:       viewer,
+			body:           `{"query": "mutation { createArticle(input: {title: \"Test_Title\", description: \"Test_Description\", expirationDate: \"2030-01-01T00:00:00Z\"}) { id } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": null, "errors": [{"message": "Forbidden", "locations": [{"line": 1, "column": 12}], "path": ["createArticle"], "extensions": {"code": "FORBIDDEN", "status": 403}}]}`,
+		},
+		{
+			name:           "Prevent updating missing article",
+			identity:       editor,
+			body:           `{"query": "mutation { updateArticle(id: \"` + missingId.Hex() + `\", input: {title: \"New_Title\", description: \"Test_Description\", expirationDate: \"2030-01-01T00:00:00Z\"}) { id } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": null, "errors": [{"message": "Not Found", "locations": [{"line": 1, "column": 12}], "path": ["updateArticle"], "extensions": {"code": "NOT_FOUND", "status": 404}}]}`,
+		},
+		{
+			name:           "Prevent mutations over the create budget",
+			limit:          tokens(1),
+			body:           `{"query": "mutation { a: createArticle(input: {title: \"Test_Title\", description: \"Test_Description\", expirationDate: \"2030-01-01T00:00:00Z\"}) { id } b: createArticle(input: {title: \"Test_Title\", description: \"Test_Description\", expirationDate: \"2030-01-01T00:00:00Z\"}) { id } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": null, "errors": [{"message": "the create budget is spent; retry after 30 seconds", "locations": [{"line": 1, "column": 139}], "path": ["b"], "extensions": {"code": "TOO_MANY_REQUESTS", "status": 429}}]}`,
+		},
+		{
+			name:                "Prevent anonymous mutations when credentials are required",
+			credentialsRequired: true,
+			body:                `{"query": "mutation { createArticle(input: {title: \"Test_Title\", description: \"Test_Description\", expirationDate: \"2030-01-01T00:00:00Z\"}) { id } }"}`,
+			expectedStatus:      http.StatusUnauthorized,
+		},
+		{
+			name:                "Successfully query anonymously when credentials are required",
+			credentialsRequired: true,
+			body:                `{"query": "{ article(id: \"` + articleId.Hex() + `\") { title } }"}`,
+			expectedStatus:      http.StatusOK,
+			expectedBody:        `{"data": {"article": {"title": "Test_Title"}}}`,
+		},
+		{
+			name:           "Internal error - findPage failure",
+			body:           `{"query": "{ articles(withImages: true) { hasNextPage } }"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data": null, "errors": [{"message": "Internal Server Error", "locations": [{"line": 1, "column": 3}], "path": ["articles"], "extensions": {"code": "INTERNAL_SERVER_ERROR", "status": 500}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Request = withoutAuthentication(httptest.NewRequest("POST", "/graphql", strings.NewReader(tt.body)))
+			context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), tt.identity))
+
+			c := &GraphqlController{
+				ArticleController: &ArticleController{
+					ArticleDbHandler: handler,
+					Validate:         validator.New(validator.WithRequiredStructEnabled()),
+				},
+				CredentialsRequired: tt.credentialsRequired,
+				Limit:               tt.limit,
+			}
+			c.Handle(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("GraphqlController_Handle() = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+			if tt.expectedBody == "" {
+				return
+			}
+			var found, expected interface{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &found); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.expectedBody), &expected); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			foundJSON, _ := json.Marshal(found)
+			expectedJSON, _ := json.Marshal(expected)
+			if string(foundJSON) != string(expectedJSON) {
+				t.Errorf("GraphqlController_Handle() body = %s, want %s", foundJSON, expectedJSON)
+			}
+		})
+	}
+}
+
+func TestGraphqlController_checkLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		variables     map[string]interface{}
+		maxDepth      int
+		maxComplexity int
+		maxMutations  int
+		wantErr       bool
+	}{
+		{name: "Successfully allow a page", query: `{ articles(first: 10) { items { id title } } }`, maxComplexity: 31},
+		{name: "Prevent a page of too many fields", query: `{ articles(first: 10) { items { id title } } }`, maxComplexity: 30, wantErr: true},
+		{name: "Prevent a page size of a variable", query: `query Find($first: Int) { articles(first: $first) { items { id } } }`, variables: map[string]interface{}{"first": float64(50)}, maxComplexity: 100, wantErr: true},
+		{name: "Successfully count fragments once per spread", query: `{ article(id: "1") { ...a ...a } } fragment a on Article { title }`, maxComplexity: 3},
+		{name: "Prevent too deep fragments", query: `{ articles { items { ...a } } } fragment a on Article { ... on Article { title } }`, maxDepth: 2, wantErr: true},
+		{name: "Successfully allow mutations up to the limit", query: `mutation { a: createArticle(input: {}) { id } b: updateArticle(id: "1", input: {}) { id } }`, maxMutations: 2},
+		{name: "Prevent aliased mutations over the limit", query: `mutation { a: createArticle(input: {}) { id } b: createArticle(input: {}) { id } c: createArticle(input: {}) { id } }`, maxMutations: 2, wantErr: true},
+		{name: "Prevent mutations spread over the limit", query: `mutation { ...m ...m } fragment m on Mutation { a: createArticle(input: {}) { id } b: createArticle(input: {}) { id } }`, maxMutations: 3, wantErr: true},
+		{name: "Successfully skip introspection", query: `{ __schema { types { fields { type { ofType { ofType { name } } } } } } }`, maxDepth: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &GraphqlController{MaxDepth: tt.maxDepth, MaxComplexity: tt.maxComplexity, MaxMutations: tt.maxMutations}
+			document, err := parseGraphql(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			err = c.checkLimits(document, operationOf(document, ""), tt.variables)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
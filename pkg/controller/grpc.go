@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"article-management-service/pkg/articlepb"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GrpcController serves the articles and images over gRPC with the logic of the ArticleController
+type GrpcController struct {
+	articlepb.UnimplementedArticleServiceServer
+	ArticleController *ArticleController
+	Logger            *logrus.Logger
+}
+
+func (c *GrpcController) CreateArticle(ctx context.Context, request *articlepb.CreateArticleRequest) (*articlepb.CreateArticleResponse, error) {
+	article := NewArticleBody{
+		Title:        request.GetTitle(),
+		Description:  request.GetDescription(),
+		ExpiryPolicy: request.GetExpiryPolicy(),
+	}
+	// a missing date stays zero, so it fails the validation like a missing date of POST /article
+	if request.GetExpirationDate() != nil {
+		article.ExpirationDate = request.GetExpirationDate().AsTime()
+	}
+
+	ctx = grpcContext(ctx)
+	created, err := c.ArticleController.create(ctx, article)
+	if err != nil {
+		return nil, c.grpcError(ctx, err)
+	}
+	return &articlepb.CreateArticleResponse{Id: created.Id.Hex()}, nil
+}
+
+func (c *GrpcController) GetArticle(ctx context.Context, request *articlepb.GetArticleRequest) (*articlepb.Article, error) {
+	articleId, err := primitive.ObjectIDFromHex(request.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx = grpcContext(ctx)
+	article, err := c.ArticleController.get(ctx, articleId)
+	if err != nil {
+		return nil, c.grpcError(ctx, err)
+	}
+	return articleOf(*article), nil
+}
+
+// ListArticles streams the articles like the export, one message per article, so they are never all in memory
+func (c *GrpcController) ListArticles(request *articlepb.ListArticlesRequest, stream articlepb.ArticleService_ListArticlesServer) error {
+	ctx := grpcContext(stream.Context())
+	if err := c.ArticleController.permit(ctx, authz.ActionRead, ""); err != nil {
+		return c.grpcError(ctx, err)
+	}
+
+	filter := db.ExportFilter{WithImage: request.WithImages, IncludeExpired: request.GetIncludeExpired()}
+	err := c.ArticleController.ArticleDbHandler.Export(ctx, filter, func(article db.ArticleDb) error {
+		return stream.Send(articleOf(article))
+	})
+	if err != nil {
+		return c.grpcError(ctx, err)
+	}
+	return nil
+}
+
+// UploadImage attaches the streamed image; the chunks are only read once the call is authorized
+func (c *GrpcController) UploadImage(stream articlepb.ArticleService_UploadImageServer) error {
+	ctx := grpcContext(stream.Context())
+
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "the first message names the article")
+	}
+	if err != nil {
+		return c.grpcError(ctx, err)
+	}
+	articleId, err := primitive.ObjectIDFromHex(first.GetArticleId())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "the first message names the article: "+err.Error())
+	}
+
+	var image bytes.Buffer
+	load := func() (*uploadedImage, error) {
+		for {
+			request, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := request.GetData().(*articlepb.UploadImageRequest_Chunk); !ok {
+				return nil, fail(http.StatusBadRequest, errors.New("only the first message names the article"))
+			}
+			// the rest of a too large image is not read
+			if image.Len()+len(request.GetChunk()) > MAX_IMAGE_SIZE {
+				return nil, fail(http.StatusBadRequest, errors.New("the image is too large"))
+			}
+			image.Write(request.GetChunk())
+		}
+		if image.Len() == 0 {
+			return nil, fail(http.StatusBadRequest, errors.New("the image is empty"))
+		}
+		return &uploadedImage{size: int64(image.Len()), save: func(path string) error {
+			return saveImage(ctx, image.Bytes(), path)
+		}}, nil
+	}
+
+	if err := c.ArticleController.attachImage(ctx, articleId, load); err != nil {
+		return c.grpcError(ctx, err)
+	}
+	return stream.SendAndClose(&articlepb.UploadImageResponse{Size: int64(image.Len())})
+}
+
+// The context of the call with the client ip, like requestContext
+func grpcContext(ctx context.Context) context.Context {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			ip = p.Addr.String()
+		}
+		return withClientIp(ctx, ip)
+	}
+	return ctx
+}
+
+// Maps the error of a call to the status with the code of its HTTP status; errors that are already
+// a status, e.g. of a canceled stream, are passed on
+func (c *GrpcController) grpcError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	httpStatus := statusOf(err)
+	method, _ := grpc.Method(ctx)
+	entry := logging.FromContext(ctx, c.Logger).WithError(err).WithFields(logrus.Fields{"method": method, "status": httpStatus})
+	if httpStatus >= http.StatusInternalServerError {
+		entry.Error("call failed")
+		return status.Error(codes.Internal, http.StatusText(http.StatusInternalServerError))
+	}
+	entry.Info("call rejected")
+
+	code := codes.Unknown
+	switch httpStatus {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	}
+
+	// like the JSON-RPC errors, client errors tell the reason
+	message := http.StatusText(httpStatus)
+	var requestErr *requestError
+	if errors.As(err, &requestErr) && requestErr.err != nil {
+		message = requestErr.err.Error()
+	}
+	return status.Error(code, message)
+}
+
+func articleOf(article db.ArticleDb) *articlepb.Article {
+	return &articlepb.Article{
+		Id:             article.Id.Hex(),
+		AuthorId:       article.AuthorId,
+		Title:          article.Title,
+		ExpirationDate: timestamppb.New(article.ExpirationDate),
+		Description:    article.Description,
+		ExpiryPolicy:   article.ExpiryPolicy,
+		Renewals:       int32(article.Renewals),
+		ImagePaths:     article.ImageFilePaths,
+	}
+}
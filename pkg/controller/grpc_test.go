@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"article-management-service/pkg/articlepb"
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Serves the controller over an in-memory connection of a deployment without authentication; every call has the identity
+func newGrpcClient(t *testing.T, c *GrpcController, identity *auth.Identity) articlepb.ArticleServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(auth.WithAuthenticationDisabled(auth.WithIdentity(ctx, identity)), req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &identityStream{ServerStream: stream, identity: identity})
+		}),
+	)
+	articlepb.RegisterArticleServiceServer(server, c)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return articlepb.NewArticleServiceClient(conn)
+}
+
+type identityStream struct {
+	grpc.ServerStream
+	identity *auth.Identity
+}
+
+func (s *identityStream) Context() context.Context {
+	return auth.WithAuthenticationDisabled(auth.WithIdentity(s.ServerStream.Context(), s.identity))
+}
+
+func TestGrpcController_CreateArticle(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	handler := &mocks.MockArticleDbHandler{InsertOneFunc: func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
+		return articleId, nil
+	}}
+	failing := &mocks.MockArticleDbHandler{InsertOneFunc: func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
+		return primitive.NilObjectID, errors.New("failed")
+	}}
+	expirationDate := timestamppb.New(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name         string
+		handler      *mocks.MockArticleDbHandler
+		identity     *auth.Identity
+		request      *articlepb.CreateArticleRequest
+		expectedCode codes.Code
+	}{
+		{name: "Successfully create an article", handler: handler, request: &articlepb.CreateArticleRequest{Title: "Test_Title", Description: "Test_Description", ExpirationDate: expirationDate}, expectedCode: codes.OK},
+		{name: "Prevent missing expiration date", handler: handler, request: &articlepb.CreateArticleRequest{Title: "Test_Title", Description: "Test_Description"}, expectedCode: codes.InvalidArgument},
+		{name: "Prevent unknown expiry policy", handler: handler, request: &articlepb.CreateArticleRequest{Title: "Test_Title", Description: "Test_Description", ExpirationDate: expirationDate, ExpiryPolicy: "keep"}, expectedCode: codes.InvalidArgument},
+		{name: "Prevent viewer creating", handler: handler, identity: &auth.Identity{Subject: "viewer-1", Roles: []string{authz.RoleViewer}}, request: &articlepb.CreateArticleRequest{Title: "Test_Title", Description: "Test_Description", ExpirationDate: expirationDate}, expectedCode: codes.PermissionDenied},
+		{name: "Internal error - insertOne failure", handler: failing, request: &articlepb.CreateArticleRequest{Title: "Test_Title", Description: "Test_Description", ExpirationDate: expirationDate}, expectedCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newGrpcClient(t, &GrpcController{ArticleController: &ArticleController{
+				ArticleDbHandler: tt.handler,
+				Validate:         validator.New(validator.WithRequiredStructEnabled()),
+			}}, tt.identity)
+
+			response, err := client.CreateArticle(context.Background(), tt.request)
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("GrpcController_CreateArticle() code = %v, want %v (%v)", code, tt.expectedCode, err)
+			}
+			if err == nil && response.GetId() != articleId.Hex() {
+				t.Errorf("GrpcController_CreateArticle() id = %v, want %v", response.GetId(), articleId.Hex())
+			}
+		})
+	}
+}
+
+func TestGrpcController_GetArticle(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	handler := &mocks.MockArticleDbHandler{FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+		if id != articleId {
+			return nil, nil
+		}
+		return &db.ArticleDb{Id: id, Title: "Test_Title", ImageFilePaths: []string{"images/default/1"}}, nil
+	}}
+
+	tests := []struct {
+		name         string
+		id           string
+		expectedCode codes.Code
+	}{
+		{name: "Successfully get an article", id: articleId.Hex(), expectedCode: codes.OK},
+		{name: "Prevent invalid id", id: "1", expectedCode: codes.InvalidArgument},
+		{name: "Prevent missing article", id: primitive.NewObjectID().Hex(), expectedCode: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newGrpcClient(t, &GrpcController{ArticleController: &ArticleController{ArticleDbHandler: handler}}, nil)
+
+			article, err := client.GetArticle(context.Background(), &articlepb.GetArticleRequest{Id: tt.id})
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("GrpcController_GetArticle() code = %v, want %v (%v)", code, tt.expectedCode, err)
+			}
+			if err == nil && (article.GetTitle() != "Test_Title" || len(article.GetImagePaths()) != 1) {
+				t.Errorf("GrpcController_GetArticle() = %v", article)
+			}
+		})
+	}
+}
+
+func TestGrpcController_ListArticles(t *testing.T) {
+	withImages := true
+	var filters []db.ExportFilter
+	handler := &mocks.MockArticleDbHandler{ExportFunc: func(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error {
+		filters = append(filters, filter)
+		for _, title := range []string{"first", "second"} {
+			if err := handle(db.ArticleDb{Id: primitive.NewObjectID(), Title: title}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+	client := newGrpcClient(t, &GrpcController{ArticleController: &ArticleController{ArticleDbHandler: handler}}, nil)
+
+	stream, err := client.ListArticles(context.Background(), &articlepb.ListArticlesRequest{WithImages: &withImages})
+	if err != nil {
+		t.Fatalf("ListArticles() error = %v", err)
+	}
+	var titles []string
+	for {
+		article, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		titles = append(titles, article.GetTitle())
+	}
+
+	if len(titles) != 2 || titles[0] != "first" || titles[1] != "second" {
+		t.Errorf("GrpcController_ListArticles() titles = %v, want [first second]", titles)
+	}
+	if len(filters) != 1 || filters[0].WithImage == nil || !*filters[0].WithImage {
+		t.Errorf("GrpcController_ListArticles() filters = %v, want one with images", filters)
+	}
+}
+
+func TestGrpcController_UploadImage(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	editor := &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}
+	// messages are limited to 4MB, so images are sent in chunks
+	var tooLarge [][]byte
+	for size := 0; size <= MAX_IMAGE_SIZE; size += 1024 * 1024 {
+		tooLarge = append(tooLarge, make([]byte, 1024*1024))
+	}
+
+	tests := []struct {
+		name         string
+		identity     *auth.Identity
+		articleId    string
+		chunks       [][]byte
+		expectedCode codes.Code
+	}{
+		{name: "Successfully upload an image in chunks", identity: editor, articleId: articleId.Hex(), chunks: [][]byte{[]byte("ima"), []byte("ge")}, expectedCode: codes.OK},
+		{name: "Prevent invalid article id", identity: editor, articleId: "1", chunks: [][]byte{[]byte("image")}, expectedCode: codes.InvalidArgument},
+		{name: "Prevent missing article", identity: editor, articleId: primitive.NewObjectID().Hex(), chunks: [][]byte{[]byte("image")}, expectedCode: codes.NotFound},
+		{name: "Prevent empty image", identity: editor, articleId: articleId.Hex(), expectedCode: codes.InvalidArgument},
+		{name: "Prevent too large image", identity: editor, articleId: articleId.Hex(), chunks: tooLarge, expectedCode: codes.InvalidArgument},
+		{name: "Prevent editor uploading to article of someone else", identity: &auth.Identity{Subject: "editor-2", Roles: []string{authz.RoleEditor}}, articleId: articleId.Hex(), chunks: [][]byte{[]byte("image")}, expectedCode: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var savedPath string
+			handler := &mocks.MockArticleDbHandler{
+				FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+					if id != articleId {
+						return nil, nil
+					}
+					return &db.ArticleDb{Id: id, Title: "Test_Title", AuthorId: "editor-1"}, nil
+				},
+				AppendImageFunc: func(ctx context.Context, id primitive.ObjectID, path string) (bool, error) {
+					savedPath = path
+					return true, nil
+				},
+			}
+			client := newGrpcClient(t, &GrpcController{ArticleController: &ArticleController{
+				ImageDirectory:     t.TempDir(),
+				GenerateIdentifier: func() string { return "image" },
+				ArticleDbHandler:   handler,
+			}}, tt.identity)
+
+			stream, err := client.UploadImage(context.Background())
+			if err != nil {
+				t.Fatalf("UploadImage() error = %v", err)
+			}
+			// the server may stop reading early, e.g. when unauthorized, which fails the later sends with io.EOF
+			messages := []*articlepb.UploadImageRequest{{Data: &articlepb.UploadImageRequest_ArticleId{ArticleId: tt.articleId}}}
+			for _, chunk := range tt.chunks {
+				messages = append(messages, &articlepb.UploadImageRequest{Data: &articlepb.UploadImageRequest_Chunk{Chunk: chunk}})
+			}
+			for _, message := range messages {
+				if err := stream.Send(message); err != nil {
+					break
+				}
+			}
+			response, err := stream.CloseAndRecv()
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("GrpcController_UploadImage() code = %v, want %v (%v)", code, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+
+			image, err := os.ReadFile(savedPath)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			expected := bytes.Join(tt.chunks, nil)
+			if !bytes.Equal(image, expected) || response.GetSize() != int64(len(expected)) {
+				t.Errorf("GrpcController_UploadImage() saved %q of size %d, want %q", image, response.GetSize(), expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,523 @@
+package controller
+
+import (
+	"archive/zip"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// how many articles are inserted at once
+	importBatchSize = 500
+	// the longest line of an NDJSON import
+	maxImportLineSize = 1 << 20
+)
+
+var (
+	errQuotaExhausted      = errors.New("the article quota of the tenant is exhausted")
+	errImageQuotaExhausted = errors.New("the image quota of the tenant is exhausted")
+	errImageTooLarge       = errors.New("the image is too large")
+	// the error of the report and the rows that could not be stored; the cause is only logged
+	errImportStore = errors.New("the article could not be stored")
+)
+
+// ImportReport is the outcome of an import; the rows are in the order of the body
+type ImportReport struct {
+	DryRun  bool        `json:"dryRun"`
+	Valid   int         `json:"valid"`
+	Created int         `json:"created"` // 0 in a dry run
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+	Error   string      `json:"error,omitempty"` // why the import stopped before the end of the body
+}
+
+// ImportRow is the id of the created article or the error of one row; the line is the line of the row in the body
+type ImportRow struct {
+	Line  int    `json:"line"`
+	Id    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Import creates the articles of an NDJSON (application/x-ndjson) or CSV (text/csv) body. Every row is validated
+// like the body of Create; valid rows are inserted in batches, invalid rows are reported and skipped. With
+// dryRun=true nothing is inserted. Imported articles get no initial revision; it is recorded on their first change.
+func (c *ArticleController) Import(context *gin.Context) {
+	if !c.authorize(context, authz.ActionCreate, "") {
+		return
+	}
+
+	var read func(io.Reader, func(line int, article NewArticleBody, err error) error) error
+	switch context.ContentType() {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		read = readNDJSON
+	case "text/csv":
+		read = readCSV
+	default:
+		c.handleError(context, fmt.Errorf("can not import %q", context.ContentType()), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	ctx := requestContext(context)
+	report, err := c.importArticles(ctx, context.Query("dryRun") == "true", func(importer *articleImporter) error {
+		return read(context.Request.Body, importer.add)
+	})
+
+	var storeErr *importStoreError
+	if errors.As(err, &storeErr) {
+		// the report tells the articles created before and the row that failed; the cause is only logged
+		logging.FromContext(ctx, c.Logger).WithError(storeErr.err).Error("request failed")
+		context.AbortWithStatusJSON(http.StatusInternalServerError, report)
+		return
+	}
+	if statusOf(err) == http.StatusTooManyRequests {
+		logging.FromContext(ctx, c.Logger).WithError(err).Info("request rejected")
+		context.AbortWithStatusJSON(http.StatusTooManyRequests, report)
+		return
+	}
+	if err != nil {
+		// the rows before the error are imported, so the report is returned as well
+		logging.FromContext(ctx, c.Logger).WithError(err).Info("request rejected")
+		context.AbortWithStatusJSON(http.StatusBadRequest, report)
+		return
+	}
+
+	context.JSON(http.StatusOK, report)
+}
+
+// ImportFile creates the articles of an NDJSON (.ndjson, .jsonl), CSV (.csv) or ZIP (.zip) export like Import; the
+// images of ZIP exports are copied to the image directory. Used by the import command, so no permission is checked.
+func (c *ArticleController) ImportFile(ctx context.Context, path string, dryRun bool) (*ImportReport, error) {
+	var read func(importer *articleImporter) error
+	switch extension := strings.ToLower(filepath.Ext(path)); extension {
+	case ".ndjson", ".jsonl", ".csv":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		readRows := readNDJSON
+		if extension == ".csv" {
+			readRows = readCSV
+		}
+		read = func(importer *articleImporter) error { return readRows(file, importer.add) }
+	case ".zip":
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, err
+		}
+		defer archive.Close()
+
+		read = func(importer *articleImporter) error { return readZIP(&archive.Reader, importer.addWithImages) }
+	default:
+		return nil, fmt.Errorf("can not import %q; the formats are .ndjson, .jsonl, .csv and .zip", filepath.Base(path))
+	}
+
+	return c.importArticles(ctx, dryRun, read)
+}
+
+// Imports the rows that read passes to the importer. When read fails or the create budget is spent, the rows
+// before are imported and the report tells the error; an importStoreError stops the import, and the report tells
+// the articles created before and the row that could not be stored.
+func (c *ArticleController) importArticles(ctx context.Context, dryRun bool, read func(importer *articleImporter) error) (*ImportReport, error) {
+	importer := &articleImporter{
+		controller: c,
+		ctx:        ctx,
+		report:     &ImportReport{DryRun: dryRun, Rows: make([]ImportRow, 0)},
+		remaining:  -1,
+		images:     -1,
+	}
+
+	// the quota is checked before inserting; concurrent creates can overshoot it slightly
+	quota := c.Quotas.For(tenant.FromContext(ctx))
+	if quota.MaxArticles > 0 {
+		count, err := c.ArticleDbHandler.CountArticles(ctx)
+		if err != nil {
+			return nil, &importStoreError{err: err}
+		}
+		importer.remaining = quota.MaxArticles - count
+	}
+	if quota.MaxImages > 0 {
+		count, err := c.ArticleDbHandler.CountImages(ctx)
+		if err != nil {
+			return nil, &importStoreError{err: err}
+		}
+		importer.images = quota.MaxImages - count
+	}
+
+	// the queued rows are stored as well when the import stops early
+	err := read(importer)
+	var storeErr *importStoreError
+	if flushErr := importer.flush(); flushErr != nil && !errors.As(err, &storeErr) {
+		err = flushErr
+	}
+
+	if errors.As(err, &storeErr) {
+		importer.report.Error = errImportStore.Error()
+	} else if err != nil {
+		importer.report.Error = err.Error()
+	}
+	return importer.report, err
+}
+
+// importStoreError stops the import when the articles or their images can not be counted or stored
+type importStoreError struct {
+	err error
+}
+
+func (e *importStoreError) Error() string {
+	return e.err.Error()
+}
+
+type articleImporter struct {
+	controller *ArticleController
+	ctx        context.Context
+	report     *ImportReport
+	remaining  int64 // how many articles the quota still allows; -1 is unlimited
+	images     int64 // how many images the quota still allows; -1 is unlimited
+	charged    bool  // whether an article was charged; the first one is paid by the request
+	batch      []db.ArticleDb
+	rows       []int // the index of the report row of each article of the batch
+}
+
+// Validates one row and queues it for the next batch
+func (i *articleImporter) add(line int, article NewArticleBody, err error) error {
+	return i.addWithImages(line, article, nil, err)
+}
+
+// Validates one row of a ZIP export and queues it with its images for the next batch
+func (i *articleImporter) addWithImages(line int, article NewArticleBody, images []*zip.File, err error) error {
+	if err == nil {
+		err = i.controller.Validate.Struct(article)
+	}
+	if err == nil {
+		err = checkImportedImages(images)
+	}
+	if err == nil && i.remaining == 0 {
+		err = errQuotaExhausted
+	}
+	if err == nil && i.images >= 0 && int64(len(images)) > i.images {
+		err = errImageQuotaExhausted
+	}
+	if err != nil {
+		i.report.Failed++
+		i.report.Rows = append(i.report.Rows, ImportRow{Line: line, Error: err.Error()})
+		return nil
+	}
+
+	i.report.Valid++
+	i.take(1, len(images))
+	i.report.Rows = append(i.report.Rows, ImportRow{Line: line})
+	if i.report.DryRun {
+		return nil
+	}
+
+	// the request is charged once, like a create, and every further article on its own
+	if i.charged {
+		if err := charge(i.ctx, i.controller.Limit, BudgetCreate); err != nil {
+			i.fail(len(i.report.Rows)-1, err)
+			return err
+		}
+	}
+	i.charged = true
+
+	// the images of a batch that fails to insert are left to the gc command
+	imagePaths, err := i.saveImages(images)
+	if errors.Is(err, errImageTooLarge) {
+		// the size in the header of the archive was wrong
+		i.fail(len(i.report.Rows)-1, err)
+		i.take(-1, -len(images))
+		return nil
+	}
+	if err != nil {
+		i.fail(len(i.report.Rows)-1, errImportStore)
+		return &importStoreError{err: err}
+	}
+
+	i.batch = append(i.batch, db.ArticleDb{
+		AuthorId:       authorId(i.ctx),
+		Title:          article.Title,
+		Description:    article.Description,
+		ExpirationDate: article.ExpirationDate,
+		ExpiryPolicy:   i.controller.expiryPolicy(article.ExpiryPolicy),
+		ImageFilePaths: imagePaths,
+	})
+	i.rows = append(i.rows, len(i.report.Rows)-1)
+	if len(i.batch) >= importBatchSize {
+		return i.flush()
+	}
+	return nil
+}
+
+// Takes articles and images from the quotas; negative amounts give them back
+func (i *articleImporter) take(articles int, images int) {
+	if i.remaining >= 0 {
+		i.remaining -= int64(articles)
+	}
+	if i.images >= 0 {
+		i.images -= int64(images)
+	}
+}
+
+// Turns a valid row into a failed one
+func (i *articleImporter) fail(row int, err error) {
+	i.report.Valid--
+	i.report.Failed++
+	i.report.Rows[row].Error = err.Error()
+}
+
+// Inserts the queued articles; the batch is emptied even when it fails, so it is not inserted twice
+func (i *articleImporter) flush() error {
+	if len(i.batch) == 0 {
+		return nil
+	}
+	defer func() {
+		i.batch = i.batch[:0]
+		i.rows = i.rows[:0]
+	}()
+
+	// on failure, the ids are those of the articles stored before the failed one
+	ids, err := i.controller.ArticleDbHandler.InsertMany(i.ctx, i.batch)
+
+	tenantId := tenant.FromContext(i.ctx)
+	for n, id := range ids {
+		created := i.batch[n]
+		created.Id = id
+		created.TenantId = tenantId
+		i.controller.audit(i.ctx, db.AuditActionCreate, id, nil, &created)
+		i.report.Rows[i.rows[n]].Id = id.Hex()
+	}
+	i.report.Created += len(ids)
+
+	if err != nil {
+		for _, row := range i.rows[len(ids):] {
+			i.fail(row, errImportStore)
+		}
+		return &importStoreError{err: err}
+	}
+	return nil
+}
+
+// The images of a row pass the limits of AttachImage
+func checkImportedImages(images []*zip.File) error {
+	if len(images) > MAX_IMAGE_AMOUNT {
+		return errors.New("the article has more than the maximum amount of images")
+	}
+	for _, image := range images {
+		if image.UncompressedSize64 > MAX_IMAGE_SIZE {
+			return fmt.Errorf("%w: %q", errImageTooLarge, image.Name)
+		}
+	}
+	return nil
+}
+
+// Copies the images to the image directory of the tenant of the import and returns their paths; the images of
+// the row are removed again when one fails
+func (i *articleImporter) saveImages(images []*zip.File) ([]string, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+
+	directory := filepath.Join(i.controller.ImageDirectory, tenant.FromContext(i.ctx))
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(images))
+	for _, image := range images {
+		path := filepath.Join(directory, i.controller.GenerateIdentifier())
+		if err := copyImage(image, path); err != nil {
+			for _, saved := range paths {
+				os.Remove(saved)
+			}
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func copyImage(image *zip.File, path string) error {
+	r, err := image.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	// the size of the header can not be trusted; one byte more than allowed tells the image is too large
+	n, err := io.Copy(file, io.LimitReader(r, MAX_IMAGE_SIZE+1))
+	if err == nil && n > MAX_IMAGE_SIZE {
+		err = fmt.Errorf("%w: %q", errImageTooLarge, image.Name)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// Reads one article per line; blank lines are skipped
+func readNDJSON(body io.Reader, handle func(line int, article NewArticleBody, err error) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var article NewArticleBody
+		err := json.Unmarshal(scanner.Bytes(), &article)
+		if err := handle(line, article, err); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return nil
+}
+
+// Reads one article per record; the header names the columns title, expirationDate (RFC 3339), description and,
+// optionally, expiryPolicy in any order. The other columns of CSV exports are ignored; the formulas the export
+// escaped are restored.
+func readCSV(body io.Reader, handle func(line int, article NewArticleBody, err error) error) error {
+	reader := csv.NewReader(body)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "title", "expirationDate", "description", "expiryPolicy":
+			columns[name] = i
+		case "id", "authorId", "renewals", "images":
+			// written by the export; imported articles get new ones
+		default:
+			return fmt.Errorf("unknown column %q", name)
+		}
+	}
+	for _, name := range []string{"title", "expirationDate", "description"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// the reader continues with the next record
+			if err := handle(parseErr.StartLine, NewArticleBody{}, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		article := NewArticleBody{
+			Title:       unescapeCell(record[columns["title"]]),
+			Description: unescapeCell(record[columns["description"]]),
+		}
+		if i, ok := columns["expiryPolicy"]; ok {
+			article.ExpiryPolicy = record[i]
+		}
+		article.ExpirationDate, err = time.Parse(time.RFC3339, record[columns["expirationDate"]])
+		if err := handle(line, article, err); err != nil {
+			return err
+		}
+	}
+}
+
+// Reads the manifest of a ZIP export line by line like readNDJSON; the images of a row are the files of the
+// archive its images name
+func readZIP(archive *zip.Reader, handle func(line int, article NewArticleBody, images []*zip.File, err error) error) error {
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	manifest, ok := files[exportManifest]
+	if !ok {
+		return fmt.Errorf("the archive has no %s", exportManifest)
+	}
+	r, err := manifest.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var exported ExportedArticle
+		err := json.Unmarshal(scanner.Bytes(), &exported)
+		article := NewArticleBody{
+			Title:          exported.Title,
+			ExpirationDate: exported.ExpirationDate,
+			Description:    exported.Description,
+			ExpiryPolicy:   exported.ExpiryPolicy,
+		}
+
+		var images []*zip.File
+		for _, name := range exported.Images {
+			image, ok := files[name]
+			if !ok && err == nil {
+				err = fmt.Errorf("the archive has no image %q", name)
+			}
+			images = append(images, image)
+		}
+		if err := handle(line, article, images, err); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return nil
+}
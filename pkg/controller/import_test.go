@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"archive/zip"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"article-management-service/pkg/tenant"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArticleController_Import(t *testing.T) {
+	ndjson := strings.Join([]string{
+		`{"title": "First", "expirationDate": "2030-01-01T00:00:00Z", "description": "First_Description"}`,
+		`{"title": "", "expirationDate": "2030-01-01T00:00:00Z", "description": "No title"}`,
+		``,
+		`{"title": "Second", "expirationDate": "2030-01-01T00:00:00Z", "description": "Second_Description", "expiryPolicy": "archive"}`,
+		`not json`,
+	}, "\n")
+	csv := "title,description,expirationDate\n" +
+		"First,\"First, with comma\",2030-01-01T00:00:00Z\n" +
+		"Second,Second_Description,tomorrow\n"
+
+	tests := []struct {
+		name           string
+		contentType    string
+		query          string
+		body           string
+		maxArticles    int64
+		insertErr      error // the batch fails after the first article
+		limit          CallLimiter
+		expectedStatus int
+		expectedReport ImportReport
+		expectedErrors []int // the lines of the rows that failed
+	}{
+		{name: "Successfully import NDJSON", contentType: "application/x-ndjson", body: ndjson, expectedStatus: http.StatusOK, expectedReport: ImportReport{Valid: 2, Created: 2, Failed: 2}, expectedErrors: []int{2, 5}},
+		{name: "Successfully import CSV", contentType: "text/csv", body: csv, expectedStatus: http.StatusOK, expectedReport: ImportReport{Valid: 1, Created: 1, Failed: 1}, expectedErrors: []int{3}},
+		{name: "Successfully validate in a dry run", contentType: "application/x-ndjson", query: "?dryRun=true", body: ndjson, expectedStatus: http.StatusOK, expectedReport: ImportReport{DryRun: true, Valid: 2, Failed: 2}, expectedErrors: []int{2, 5}},
+		{name: "Successfully stop at the quota", contentType: "application/x-ndjson", body: ndjson, maxArticles: 1, expectedStatus: http.StatusOK, expectedReport: ImportReport{Valid: 1, Created: 1, Failed: 3}, expectedErrors: []int{2, 4, 5}},
+		{
+			name: "Successfully report the created articles when storing fails", contentType: "application/x-ndjson", body: ndjson, insertErr: errors.New("failed"),
+			expectedStatus: http.StatusInternalServerError, expectedReport: ImportReport{Valid: 1, Created: 1, Failed: 3, Error: "the article could not be stored"}, expectedErrors: []int{2, 4, 5},
+		},
+		{
+			name: "Prevent importing over the create budget", contentType: "application/x-ndjson", body: ndjson, limit: func(ctx context.Context, budget string, clientIp string) (bool, int) { return false, 30 },
+			expectedStatus: http.StatusTooManyRequests, expectedReport: ImportReport{Valid: 1, Created: 1, Failed: 2, Error: "the create budget is spent; retry after 30 seconds"}, expectedErrors: []int{2, 4},
+		},
+		{name: "Prevent unknown CSV columns", contentType: "text/csv", body: "title,author\n", expectedStatus: http.StatusBadRequest, expectedReport: ImportReport{Error: `unknown column "author"`}},
+		{name: "Prevent missing CSV columns", contentType: "text/csv", body: "title,description\n", expectedStatus: http.StatusBadRequest, expectedReport: ImportReport{Error: `missing column "expirationDate"`}},
+		{name: "Prevent other content types", contentType: "application/json", body: "[]", expectedStatus: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inserted []db.ArticleDb
+			c := &ArticleController{
+				ArticleDbHandler: &mocks.MockArticleDbHandler{
+					InsertManyFunc: func(ctx context.Context, new []db.ArticleDb) ([]primitive.ObjectID, error) {
+						if tt.insertErr != nil {
+							new = new[:1]
+						}
+						inserted = append(inserted, new...)
+						ids := make([]primitive.ObjectID, len(new))
+						for i := range ids {
+							ids[i] = primitive.NewObjectID()
+						}
+						return ids, tt.insertErr
+					},
+				},
+				Validate: validator.New(validator.WithRequiredStructEnabled()),
+				Quotas:   tenant.Quotas{Default: tenant.Quota{MaxArticles: tt.maxArticles}},
+				Limit:    tt.limit,
+			}
+
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Request = withoutAuthentication(httptest.NewRequest(http.MethodPost, "/article/import"+tt.query, strings.NewReader(tt.body)))
+			context.Request.Header.Set("Content-Type", tt.contentType)
+			c.Import(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Fatalf("ArticleController_Import() status = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+			if tt.expectedStatus == http.StatusUnsupportedMediaType {
+				return
+			}
+
+			var report ImportReport
+			if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+				t.Fatalf("ArticleController_Import() body error = %v", err)
+			}
+			if report.DryRun != tt.expectedReport.DryRun || report.Valid != tt.expectedReport.Valid || report.Created != tt.expectedReport.Created || report.Failed != tt.expectedReport.Failed || report.Error != tt.expectedReport.Error {
+				t.Errorf("ArticleController_Import() report = %+v, want %+v", report, tt.expectedReport)
+			}
+			if len(inserted) != tt.expectedReport.Created {
+				t.Errorf("ArticleController_Import() inserted = %v, want %v", len(inserted), tt.expectedReport.Created)
+			}
+
+			var foundErrors []int
+			for _, row := range report.Rows {
+				if row.Error != "" {
+					foundErrors = append(foundErrors, row.Line)
+				} else if !tt.expectedReport.DryRun && row.Id == "" {
+					t.Errorf("ArticleController_Import() row %v has no id", row.Line)
+				}
+			}
+			if fmt.Sprint(foundErrors) != fmt.Sprint(tt.expectedErrors) {
+				t.Errorf("ArticleController_Import() failed lines = %v, want %v", foundErrors, tt.expectedErrors)
+			}
+		})
+	}
+}
+
+func TestArticleController_Import_Batches(t *testing.T) {
+	var batches []int
+	c := &ArticleController{
+		ArticleDbHandler: &mocks.MockArticleDbHandler{InsertManyFunc: func(ctx context.Context, new []db.ArticleDb) ([]primitive.ObjectID, error) {
+			batches = append(batches, len(new))
+			return make([]primitive.ObjectID, len(new)), nil
+		}},
+		Validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+
+	var body strings.Builder
+	for i := 0; i < importBatchSize+1; i++ {
+		fmt.Fprintf(&body, "{\"title\": \"Title_%d\", \"expirationDate\": \"2030-01-01T00:00:00Z\", \"description\": \"Description\"}\n", i)
+	}
+
+	context, _ := gin.CreateTestContext(httptest.NewRecorder())
+	context.Request = withoutAuthentication(httptest.NewRequest(http.MethodPost, "/article/import", strings.NewReader(body.String())))
+	context.Request.Header.Set("Content-Type", "application/x-ndjson")
+	c.Import(context)
+
+	if fmt.Sprint(batches) != fmt.Sprint([]int{importBatchSize, 1}) {
+		t.Errorf("ArticleController_Import() batches = %v, want %v", batches, []int{importBatchSize, 1})
+	}
+}
+
+func TestArticleController_ImportFile(t *testing.T) {
+	// a ZIP export of an article with an image
+	source := filepath.Join(t.TempDir(), "images")
+	if err := os.MkdirAll(filepath.Join(source, "default"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	imagePath := filepath.Join(source, "default", "image-1")
+	if err := os.WriteFile(imagePath, []byte("image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exported := db.ArticleDb{Id: primitive.NewObjectID(), Title: "Title", Description: "Description", ExpirationDate: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), ImageFilePaths: []string{imagePath}}
+	exporter := &ArticleController{
+		ImageDirectory: source,
+		ArticleDbHandler: &mocks.MockArticleDbHandler{ExportFunc: func(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error {
+			return handle(exported)
+		}},
+	}
+	directory := t.TempDir()
+	archive, err := os.Create(filepath.Join(directory, "articles.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.ExportTo(context.Background(), archive, ExportFormatZIP, db.ExportFilter{}); err != nil {
+		t.Fatalf("ExportTo() error = %v", err)
+	}
+	archive.Close()
+
+	ndjson := filepath.Join(directory, "articles.ndjson")
+	if err := os.WriteFile(ndjson, []byte(`{"title": "Title", "expirationDate": "2030-01-01T00:00:00Z", "description": "Description"}`+"\n{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		dryRun         bool
+		maxImages      int64
+		expectedReport ImportReport
+		expectedImages int
+		wantErr        bool
+	}{
+		{name: "Successfully import a ZIP export with its images", path: archive.Name(), expectedReport: ImportReport{Valid: 1, Created: 1}, expectedImages: 1},
+		{name: "Successfully validate a ZIP export in a dry run", path: archive.Name(), dryRun: true, expectedReport: ImportReport{DryRun: true, Valid: 1}},
+		{name: "Prevent images beyond the image quota", path: archive.Name(), maxImages: 1, expectedReport: ImportReport{Failed: 1}},
+		{name: "Successfully import NDJSON", path: ndjson, expectedReport: ImportReport{Valid: 1, Created: 1, Failed: 1}},
+		{name: "Prevent unknown formats", path: filepath.Join(directory, "articles.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inserted []db.ArticleDb
+			c := &ArticleController{
+				ImageDirectory:     t.TempDir(),
+				GenerateIdentifier: func() string { return primitive.NewObjectID().Hex() },
+				ArticleDbHandler: &mocks.MockArticleDbHandler{
+					InsertManyFunc: func(ctx context.Context, new []db.ArticleDb) ([]primitive.ObjectID, error) {
+						inserted = append(inserted, new...)
+						return make([]primitive.ObjectID, len(new)), nil
+					},
+					// the quota is used up
+					CountImagesFunc: func(ctx context.Context) (int64, error) {
+						return tt.maxImages, nil
+					},
+				},
+				Validate: validator.New(validator.WithRequiredStructEnabled()),
+				Quotas:   tenant.Quotas{Default: tenant.Quota{MaxImages: tt.maxImages}},
+			}
+
+			report, err := c.ImportFile(context.Background(), tt.path, tt.dryRun)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ArticleController_ImportFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if report.DryRun != tt.expectedReport.DryRun || report.Valid != tt.expectedReport.Valid || report.Created != tt.expectedReport.Created || report.Failed != tt.expectedReport.Failed {
+				t.Errorf("ArticleController_ImportFile() report = %+v, want %+v", report, tt.expectedReport)
+			}
+
+			images := 0
+			for _, article := range inserted {
+				for _, path := range article.ImageFilePaths {
+					if image, err := os.ReadFile(path); err != nil || string(image) != "image" {
+						t.Errorf("ArticleController_ImportFile() image %v = %q, %v", path, image, err)
+					}
+					images++
+				}
+			}
+			if images != tt.expectedImages {
+				t.Errorf("ArticleController_ImportFile() images = %v, want %v", images, tt.expectedImages)
+			}
+		})
+	}
+}
+
+func TestCopyImage(t *testing.T) {
+	directory := t.TempDir()
+	archivePath := filepath.Join(directory, "images.zip")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(file)
+	for name, size := range map[string]int{"small": MAX_IMAGE_SIZE, "large": MAX_IMAGE_SIZE + 1} {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(make([]byte, size)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	for _, image := range archive.File {
+		t.Run(image.Name, func(t *testing.T) {
+			path := filepath.Join(directory, image.Name)
+			err := copyImage(image, path)
+			_, statErr := os.Stat(path)
+			if image.Name == "large" {
+				// the image is removed instead of truncated
+				if !errors.Is(err, errImageTooLarge) || !os.IsNotExist(statErr) {
+					t.Errorf("copyImage() error = %v, stat = %v, want %v and no file", err, statErr, errImageTooLarge)
+				}
+				return
+			}
+			if err != nil || statErr != nil {
+				t.Errorf("copyImage() error = %v, stat = %v", err, statErr)
+			}
+		})
+	}
+}
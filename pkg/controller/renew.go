@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"article-management-service/pkg/db"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RenewArticleBody holds either a new expiration date or a duration the expiration date is pushed back by
+type RenewArticleBody struct {
+	ExpirationDate *time.Time `json:"expirationDate"`
+	Duration       string     `json:"duration"` // e.g. "720h"
+}
+
+// RenewResponse is the expiration date of a renewed article and how often it was renewed
+type RenewResponse struct {
+	ExpirationDate time.Time `json:"expirationDate"`
+	Renewals       int       `json:"renewals"`
+}
+
+// Renew pushes back the expiration date of the article. Durations extend the current expiration date,
+// or now when the article already expired. The renewal is recorded in the audit log and the revisions.
+func (c *ArticleController) Renew(context *gin.Context) {
+	articleId, err := primitive.ObjectIDFromHex(context.Param("articleId"))
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	body := &RenewArticleBody{}
+	if err := context.BindJSON(body); err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx := requestContext(context)
+	article, err := c.findForUpdate(ctx, articleId)
+	if err != nil {
+		c.handleRequestError(context, err)
+		return
+	}
+
+	now := time.Now()
+	expirationDate, err := renewedExpirationDate(body, article.ExpirationDate, now)
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	if c.RenewMaxHorizon > 0 && expirationDate.After(now.Add(c.RenewMaxHorizon)) {
+		c.handleError(context, errors.New("expiration date beyond the maximum horizon"), http.StatusBadRequest)
+		return
+	}
+
+	if c.RenewMaxRenewals > 0 && article.Renewals >= c.RenewMaxRenewals {
+		c.handleError(context, errors.New("maximum renewals reached"), http.StatusForbidden)
+		return
+	}
+
+	found, err := c.ArticleDbHandler.Renew(ctx, articleId, expirationDate, c.RenewMaxRenewals)
+	if err != nil {
+		c.handleError(context, err, http.StatusInternalServerError)
+		return
+	}
+
+	// deleted or renewed concurrently
+	if !found {
+		c.handleError(context, nil, http.StatusConflict)
+		return
+	}
+
+	renewed := *article
+	renewed.ExpirationDate = expirationDate
+	renewed.Renewals++
+	c.audit(ctx, db.AuditActionRenew, articleId, article, &renewed)
+	c.revise(ctx, article, &renewed)
+
+	context.JSON(http.StatusOK, RenewResponse{ExpirationDate: expirationDate, Renewals: renewed.Renewals})
+}
+
+// Updates may keep or bring forward the expiration date. Pushing it back is a renewal, which has to go through
+// Renew while the renewals are limited, so the horizon and the count of renewals can not be bypassed.
+func (c *ArticleController) checkExpirationDate(article *db.ArticleDb, expirationDate time.Time) error {
+	if (c.RenewMaxHorizon > 0 || c.RenewMaxRenewals > 0) && expirationDate.After(article.ExpirationDate) {
+		return fail(http.StatusBadRequest, errors.New("the expiration date is pushed back by renewing the article"))
+	}
+	return nil
+}
+
+func renewedExpirationDate(body *RenewArticleBody, current time.Time, now time.Time) (time.Time, error) {
+	if (body.ExpirationDate == nil) == (body.Duration == "") {
+		return time.Time{}, errors.New("either expirationDate or duration is required")
+	}
+
+	if body.ExpirationDate != nil {
+		if !body.ExpirationDate.After(current) {
+			return time.Time{}, errors.New("expiration date is not after the current one")
+		}
+		// renewing an expired article to another date in the past would not bring it back
+		if !body.ExpirationDate.After(now) {
+			return time.Time{}, errors.New("expiration date is not in the future")
+		}
+		return *body.ExpirationDate, nil
+	}
+
+	duration, err := time.ParseDuration(body.Duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if duration <= 0 {
+		return time.Time{}, errors.New("duration is not positive")
+	}
+
+	if current.Before(now) {
+		current = now
+	}
+	return current.Add(duration), nil
+}
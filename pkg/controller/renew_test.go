@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRenewedExpirationDate(t *testing.T) {
+	now := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+	future := now.Add(48 * time.Hour)
+	past := now.Add(-48 * time.Hour)
+	later := now.Add(96 * time.Hour)
+
+	tests := []struct {
+		name     string
+		body     RenewArticleBody
+		current  time.Time
+		expected time.Time
+		wantErr  bool
+	}{
+		{name: "Successfully renew to a date", body: RenewArticleBody{ExpirationDate: &later}, current: future, expected: later},
+		{name: "Successfully extend the current expiration date", body: RenewArticleBody{Duration: "24h"}, current: future, expected: future.Add(24 * time.Hour)},
+		{name: "Successfully extend expired articles from now", body: RenewArticleBody{Duration: "24h"}, current: past, expected: now.Add(24 * time.Hour)},
+		{name: "Prevent missing date and duration", body: RenewArticleBody{}, current: future, wantErr: true},
+		{name: "Prevent both date and duration", body: RenewArticleBody{ExpirationDate: &later, Duration: "24h"}, current: future, wantErr: true},
+		{name: "Prevent date in the past of expired articles", body: RenewArticleBody{ExpirationDate: &past}, current: past.Add(-time.Hour), wantErr: true},
+		{name: "Prevent date before the current one", body: RenewArticleBody{ExpirationDate: &future}, current: later, wantErr: true},
+		{name: "Prevent invalid duration", body: RenewArticleBody{Duration: "a week"}, current: future, wantErr: true},
+		{name: "Prevent negative duration", body: RenewArticleBody{Duration: "-24h"}, current: future, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := renewedExpirationDate(&tt.body, tt.current, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renewedExpirationDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !found.Equal(tt.expected) {
+				t.Errorf("renewedExpirationDate() = %v, want %v", found, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArticleController_Renew(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	renew := func(ctx context.Context, id primitive.ObjectID, expirationDate time.Time, maxRenewals int) (bool, error) {
+		return true, nil
+	}
+
+	tests := []struct {
+		name           string
+		body           RenewArticleBody
+		renewals       int
+		expectedStatus int
+	}{
+		{name: "success", body: RenewArticleBody{Duration: "720h"}, renewals: 1, expectedStatus: http.StatusOK},
+		{name: "Prevent renewing beyond the maximum horizon", body: RenewArticleBody{Duration: "17520h"}, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent renewing more than the maximum renewals", body: RenewArticleBody{Duration: "720h"}, renewals: 2, expectedStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ArticleController{
+				ArticleDbHandler: &mocks.MockArticleDbHandler{
+					FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+						return &db.ArticleDb{Id: id, ExpirationDate: time.Now().Add(time.Hour), Renewals: tt.renewals}, nil
+					},
+					RenewFunc: renew,
+				},
+				RenewMaxHorizon:  365 * 24 * time.Hour,
+				RenewMaxRenewals: 2,
+			}
+
+			jsonRequest, _ := json.Marshal(tt.body)
+			context, _ := gin.CreateTestContext(httptest.NewRecorder())
+			context.Params = gin.Params{{Key: "articleId", Value: articleId.Hex()}}
+			context.Request = withoutAuthentication(httptest.NewRequest("POST", "/article/"+articleId.Hex()+"/renew", bytes.NewBuffer(jsonRequest)))
+			context.Request.Header.Set("Content-Type", "application/json")
+			c.Renew(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_Renew() = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldChange is a field that differs between two revisions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Update replaces the title, description, expiration date and, when given, the expiry policy of the article;
+// the previous version stays available as a revision
+func (c *ArticleController) Update(context *gin.Context) {
+	articleId, err := primitive.ObjectIDFromHex(context.Param("articleId"))
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	body := &NewArticleBody{}
+	if err := context.BindJSON(body); err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := c.edit(requestContext(context), articleId, *body); err != nil {
+		c.handleRequestError(context, err)
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
+
+// Validates and stores the new content of the article; shared by the REST and GraphQL apis
+func (c *ArticleController) edit(ctx context.Context, articleId primitive.ObjectID, body NewArticleBody) (*db.ArticleDb, error) {
+	if err := c.Validate.Struct(body); err != nil {
+		return nil, fail(http.StatusBadRequest, err)
+	}
+
+	article, err := c.findForUpdate(ctx, articleId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.update(ctx, article, db.ArticleDb{Title: body.Title, Description: body.Description, ExpirationDate: body.ExpirationDate, ExpiryPolicy: body.ExpiryPolicy}, db.AuditActionUpdate)
+}
+
+// FindRevisions returns all revisions of the article, oldest first
+func (c *ArticleController) FindRevisions(context *gin.Context) {
+	articleId, err := primitive.ObjectIDFromHex(context.Param("articleId"))
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	if !c.authorize(context, authz.ActionRead, "") || !c.findRevised(context, articleId) {
+		return
+	}
+
+	revisions, err := c.RevisionDbHandler.FindByArticleId(context.Request.Context(), articleId)
+	if err != nil {
+		c.handleError(context, err, http.StatusInternalServerError)
+		return
+	}
+
+	if len(revisions) == 0 {
+		c.handleError(context, nil, http.StatusNotFound)
+		return
+	}
+
+	context.JSON(http.StatusOK, revisions)
+}
+
+// FindRevision returns one revision of the article
+func (c *ArticleController) FindRevision(context *gin.Context) {
+	if !c.authorize(context, authz.ActionRead, "") {
+		return
+	}
+
+	revision, ok := c.findRevision(context, context.Param("n"))
+	if !ok {
+		return
+	}
+
+	context.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions returns the fields that changed from revision n to the revision of the query parameter to,
+// by default the latest revision
+func (c *ArticleController) DiffRevisions(context *gin.Context) {
+	if !c.authorize(context, authz.ActionRead, "") {
+		return
+	}
+
+	from, ok := c.findRevision(context, context.Param("n"))
+	if !ok {
+		return
+	}
+
+	var to *db.RevisionDb
+	if number := context.Query("to"); number != "" {
+		if to, ok = c.findRevision(context, number); !ok {
+			return
+		}
+	} else {
+		revisions, err := c.RevisionDbHandler.FindByArticleId(context.Request.Context(), from.ArticleId)
+		if err != nil {
+			c.handleError(context, err, http.StatusInternalServerError)
+			return
+		}
+		to = &revisions[len(revisions)-1]
+	}
+
+	context.JSON(http.StatusOK, diffRevisions(*from, *to))
+}
+
+// RestoreRevision rolls the title, description and expiration date of the article back to revision n;
+// the rollback is recorded as a new revision. Images are not rolled back.
+func (c *ArticleController) RestoreRevision(context *gin.Context) {
+	articleId, err := primitive.ObjectIDFromHex(context.Param("articleId"))
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx := requestContext(context)
+	article, err := c.findForUpdate(ctx, articleId)
+	if err != nil {
+		c.handleRequestError(context, err)
+		return
+	}
+
+	revision, ok := c.findRevision(context, context.Param("n"))
+	if !ok {
+		return
+	}
+
+	if _, err := c.update(ctx, article, db.ArticleDb{Title: revision.Title, Description: revision.Description, ExpirationDate: revision.ExpirationDate}, db.AuditActionUpdate); err != nil {
+		c.handleRequestError(context, err)
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
+
+// Finds the article and checks the identity may update it; a request error otherwise
+func (c *ArticleController) findForUpdate(ctx context.Context, articleId primitive.ObjectID) (*db.ArticleDb, error) {
+	article, err := c.ArticleDbHandler.FindOneById(ctx, articleId)
+	if err != nil {
+		return nil, fail(http.StatusInternalServerError, err)
+	}
+
+	if article == nil {
+		return nil, fail(http.StatusNotFound, nil)
+	}
+
+	if err := c.permit(ctx, authz.ActionUpdate, article.AuthorId); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// Stores the new content of the article and records the change; returns the updated article
+func (c *ArticleController) update(ctx context.Context, article *db.ArticleDb, content db.ArticleDb, action string) (*db.ArticleDb, error) {
+	if err := c.checkExpirationDate(article, content.ExpirationDate); err != nil {
+		return nil, err
+	}
+
+	found, err := c.ArticleDbHandler.UpdateOne(ctx, article.Id, content)
+	if err != nil {
+		return nil, fail(http.StatusInternalServerError, err)
+	}
+
+	// deleted concurrently
+	if !found {
+		return nil, fail(http.StatusNotFound, nil)
+	}
+
+	updated := *article
+	updated.Title = content.Title
+	updated.Description = content.Description
+	updated.ExpirationDate = content.ExpirationDate
+	if content.ExpiryPolicy != "" {
+		updated.ExpiryPolicy = content.ExpiryPolicy
+	}
+	c.audit(ctx, action, article.Id, article, &updated)
+	c.revise(ctx, article, &updated)
+	return &updated, nil
+}
+
+// Finds the revision of the article of the request by its number; aborts the request when there is none
+func (c *ArticleController) findRevision(context *gin.Context, number string) (*db.RevisionDb, bool) {
+	articleId, err := primitive.ObjectIDFromHex(context.Param("articleId"))
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return nil, false
+	}
+
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 {
+		c.handleError(context, errors.New("invalid revision number"), http.StatusBadRequest)
+		return nil, false
+	}
+	if !c.findRevised(context, articleId) {
+		return nil, false
+	}
+
+	revision, err := c.RevisionDbHandler.FindOne(context.Request.Context(), articleId, n)
+	if err != nil {
+		c.handleError(context, err, http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if revision == nil {
+		c.handleError(context, nil, http.StatusNotFound)
+		return nil, false
+	}
+	return revision, true
+}
+
+// Checks the article of the revisions exists outside the trash, as the revisions of deleted articles are hidden
+// like the articles; aborts the request otherwise
+func (c *ArticleController) findRevised(context *gin.Context, articleId primitive.ObjectID) bool {
+	article, err := c.ArticleDbHandler.FindOneById(context.Request.Context(), articleId)
+	if err != nil {
+		c.handleError(context, err, http.StatusInternalServerError)
+		return false
+	}
+	if article == nil {
+		c.handleError(context, nil, http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// Records the new version of the article. Articles created before revisions were introduced get their
+// previous version recorded first. Like the audit log, a failure does not fail the request.
+func (c *ArticleController) revise(ctx context.Context, before *db.ArticleDb, after *db.ArticleDb) {
+	if c.RevisionDbHandler == nil {
+		return
+	}
+
+	if before != nil {
+		revisions, err := c.RevisionDbHandler.FindByArticleId(ctx, before.Id)
+		if err != nil {
+			return
+		}
+		if len(revisions) == 0 {
+			first := revisionOf(before)
+			first.ActorId = before.AuthorId
+			if _, err := c.RevisionDbHandler.InsertOne(ctx, first); err != nil {
+				return
+			}
+		}
+	}
+
+	revision := revisionOf(after)
+	revision.ActorId = authorId(ctx)
+	_, _ = c.RevisionDbHandler.InsertOne(ctx, revision)
+}
+
+func revisionOf(article *db.ArticleDb) db.RevisionDb {
+	return db.RevisionDb{
+		ArticleId:      article.Id,
+		Title:          article.Title,
+		ExpirationDate: article.ExpirationDate,
+		Description:    article.Description,
+		ImageFilePaths: article.ImageFilePaths,
+	}
+}
+
+// Compares the content fields of two revisions
+func diffRevisions(from db.RevisionDb, to db.RevisionDb) []FieldChange {
+	changes := make([]FieldChange, 0)
+	add := func(field string, a interface{}, b interface{}) {
+		if !reflect.DeepEqual(a, b) {
+			changes = append(changes, FieldChange{Field: field, From: a, To: b})
+		}
+	}
+
+	add("title", from.Title, to.Title)
+	add("description", from.Description, to.Description)
+	if !from.ExpirationDate.Equal(to.ExpirationDate) {
+		changes = append(changes, FieldChange{Field: "expirationDate", From: from.ExpirationDate, To: to.ExpirationDate})
+	}
+	if len(from.ImageFilePaths) != 0 || len(to.ImageFilePaths) != 0 {
+		add("imagePaths", from.ImageFilePaths, to.ImageFilePaths)
+	}
+	return changes
+}
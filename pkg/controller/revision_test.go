@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiffRevisions(t *testing.T) {
+	expiration := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	base := db.RevisionDb{Title: "Title", Description: "Description", ExpirationDate: expiration}
+
+	tests := []struct {
+		name     string
+		to       db.RevisionDb
+		expected []string
+	}{
+		{name: "Successfully find no changes", to: base, expected: []string{}},
+		{name: "Successfully find changed description", to: db.RevisionDb{Title: "Title", Description: "Changed", ExpirationDate: expiration}, expected: []string{"description"}},
+		{
+			name:     "Successfully find all changed fields",
+			to:       db.RevisionDb{Title: "Changed", Description: "Changed", ExpirationDate: expiration.Add(time.Hour), ImageFilePaths: []string{"images/default/1"}},
+			expected: []string{"title", "description", "expirationDate", "imagePaths"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := make([]string, 0)
+			for _, change := range diffRevisions(base, tt.to) {
+				fields = append(fields, change.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.expected) {
+				t.Errorf("diffRevisions() = %v, want %v", fields, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArticleController_Update(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	author := &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}
+	body := NewArticleBody{Title: "Changed_Title", ExpirationDate: time.Now(), Description: "Changed_Description"}
+	findArticle := func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+		return &db.ArticleDb{Id: id, Title: "Test_Title", Description: "Test_Description", AuthorId: "editor-1"}, nil
+	}
+	updateArticle := func(ctx context.Context, id primitive.ObjectID, update db.ArticleDb) (bool, error) {
+		return true, nil
+	}
+
+	tests := []struct {
+		name              string
+		identity          *auth.Identity
+		handler           *mocks.MockArticleDbHandler
+		existingRevisions []db.RevisionDb
+		renewMaxRenewals  int
+		expectedStatus    int
+		expectedRevisions []string
+	}{
+		{name: "Prevent updating unknown article", identity: author, handler: &mocks.MockArticleDbHandler{}, expectedStatus: http.StatusNotFound},
+		{
+			name:           "Prevent editor updating article of someone else",
+			identity:       &auth.Identity{Subject: "editor-2", Roles: []string{authz.RoleEditor}},
+			handler:        &mocks.MockArticleDbHandler{FindOneByIdFunc: findArticle},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:              "Successfully record the new revision",
+			identity:          author,
+			handler:           &mocks.MockArticleDbHandler{FindOneByIdFunc: findArticle, UpdateOneFunc: updateArticle},
+			existingRevisions: []db.RevisionDb{{Number: 1, Description: "Test_Description"}},
+			expectedStatus:    http.StatusNoContent,
+			expectedRevisions: []string{"Changed_Description"},
+		},
+		{
+			name:             "Prevent pushing back the expiration date when the renewals are limited",
+			identity:         author,
+			handler:          &mocks.MockArticleDbHandler{FindOneByIdFunc: findArticle, UpdateOneFunc: updateArticle},
+			renewMaxRenewals: 10,
+			expectedStatus:   http.StatusBadRequest,
+		},
+		{
+			name:              "Successfully record the previous version of articles without revisions",
+			identity:          author,
+			handler:           &mocks.MockArticleDbHandler{FindOneByIdFunc: findArticle, UpdateOneFunc: updateArticle},
+			expectedStatus:    http.StatusNoContent,
+			expectedRevisions: []string{"Test_Description", "Changed_Description"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revisions := make([]string, 0)
+			c := &ArticleController{
+				ArticleDbHandler: tt.handler,
+				RevisionDbHandler: &mocks.MockRevisionDbHandler{
+					FindByArticleIdFunc: func(ctx context.Context, articleId primitive.ObjectID) ([]db.RevisionDb, error) {
+						return tt.existingRevisions, nil
+					},
+					InsertOneFunc: func(ctx context.Context, new db.RevisionDb) (int, error) {
+						revisions = append(revisions, new.Description)
+						return len(revisions), nil
+					},
+				},
+				Validate:         validator.New(validator.WithRequiredStructEnabled()),
+				RenewMaxRenewals: tt.renewMaxRenewals,
+			}
+
+			context := createJSONBodyContext(t, body)
+			context.Params = gin.Params{{Key: "articleId", Value: articleId.Hex()}}
+			context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), tt.identity))
+			c.Update(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_Update() = %v, want %v", foundStatus, tt.expectedStatus)
+				return
+			}
+			if tt.expectedRevisions != nil && !reflect.DeepEqual(revisions, tt.expectedRevisions) {
+				t.Errorf("ArticleController_Update() revisions = %v, want %v", revisions, tt.expectedRevisions)
+			}
+		})
+	}
+}
+
+func TestArticleController_RestoreRevision(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	var updated db.ArticleDb
+	c := &ArticleController{
+		ArticleDbHandler: &mocks.MockArticleDbHandler{
+			FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+				return &db.ArticleDb{Id: id, Title: "Title", Description: "Changed_Description"}, nil
+			},
+			UpdateOneFunc: func(ctx context.Context, id primitive.ObjectID, update db.ArticleDb) (bool, error) {
+				updated = update
+				return true, nil
+			},
+		},
+		RevisionDbHandler: &mocks.MockRevisionDbHandler{FindOneFunc: func(ctx context.Context, articleId primitive.ObjectID, number int) (*db.RevisionDb, error) {
+			if number != 1 {
+				return nil, nil
+			}
+			return &db.RevisionDb{ArticleId: articleId, Number: 1, Title: "Title", Description: "Original_Description"}, nil
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		n              string
+		expectedStatus int
+	}{
+		{name: "Prevent invalid revision number", n: "first", expectedStatus: http.StatusBadRequest},
+		{name: "Prevent unknown revision", n: "2", expectedStatus: http.StatusNotFound},
+		{name: "success", n: "1", expectedStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := createArticleIdContext(articleId.Hex(), nil)
+			context.Params = gin.Params{{Key: "articleId", Value: articleId.Hex()}, {Key: "n", Value: tt.n}}
+			context.Request = withoutAuthentication(context.Request)
+			c.RestoreRevision(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_RestoreRevision() = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+		})
+	}
+
+	if updated.Description != "Original_Description" {
+		t.Errorf("ArticleController_RestoreRevision() description = %v, want %v", updated.Description, "Original_Description")
+	}
+}
+
+func TestArticleController_FindRevisions(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	deletedId := primitive.NewObjectID()
+	c := &ArticleController{
+		ArticleDbHandler: &mocks.MockArticleDbHandler{
+			// articles in the trash are not found
+			FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+				if id == deletedId {
+					return nil, nil
+				}
+				return &db.ArticleDb{Id: id, Title: "Title"}, nil
+			},
+		},
+		RevisionDbHandler: &mocks.MockRevisionDbHandler{
+			FindByArticleIdFunc: func(ctx context.Context, articleId primitive.ObjectID) ([]db.RevisionDb, error) {
+				return []db.RevisionDb{{ArticleId: articleId, Number: 1, Title: "Title"}}, nil
+			},
+			FindOneFunc: func(ctx context.Context, articleId primitive.ObjectID, number int) (*db.RevisionDb, error) {
+				return &db.RevisionDb{ArticleId: articleId, Number: number, Title: "Title"}, nil
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		articleId      primitive.ObjectID
+		n              string
+		expectedStatus int
+	}{
+		{name: "Successfully find the revisions", articleId: articleId, expectedStatus: http.StatusOK},
+		{name: "Successfully find a revision", articleId: articleId, n: "1", expectedStatus: http.StatusOK},
+		{name: "Prevent finding the revisions of deleted articles", articleId: deletedId, expectedStatus: http.StatusNotFound},
+		{name: "Prevent finding a revision of deleted articles", articleId: deletedId, n: "1", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := createArticleIdContext(tt.articleId.Hex(), nil)
+			context.Params = gin.Params{{Key: "articleId", Value: tt.articleId.Hex()}, {Key: "n", Value: tt.n}}
+			context.Request = withoutAuthentication(context.Request)
+			if tt.n == "" {
+				c.FindRevisions(context)
+			} else {
+				c.FindRevision(context)
+			}
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_FindRevisions() = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package controller
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The error codes of JSON-RPC 2.0; the server errors (-32000 to -32099) carry the HTTP status of the
+// REST endpoints in their data
+const (
+	RpcParseError      = -32700
+	RpcInvalidRequest  = -32600
+	RpcMethodNotFound  = -32601
+	RpcInvalidParams   = -32602
+	RpcInternalError   = -32603
+	RpcUnauthorized    = -32001
+	RpcForbidden       = -32003
+	RpcNotFound        = -32004
+	RpcTooManyRequests = -32029
+)
+
+const (
+	// the largest body; fits a few base64 encoded images
+	maxRpcBodySize = 8 * MAX_IMAGE_SIZE
+	// the most calls of one batch
+	maxRpcBatchSize = 100
+)
+
+// RpcController serves the article methods over JSON-RPC 2.0 with the logic of the ArticleController. The
+// calls of a batch are charged and authorized one by one, by the kind of their method.
+type RpcController struct {
+	ArticleController   *ArticleController
+	Logger              *logrus.Logger
+	Limit               CallLimiter // optional; charges each call to the budget of its method
+	CredentialsRequired bool        // creating and attaching reject anonymous calls, like the routes with auth.Required
+}
+
+// RpcRequest is one call; without id it is a notification, which is not answered
+type RpcRequest struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Id      json.RawMessage `json:"id,omitempty"`
+}
+
+// RpcResponse holds either the result or the error of a call; the id is null when the request could not be read
+type RpcResponse struct {
+	Jsonrpc string           `json:"jsonrpc"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *RpcError        `json:"error,omitempty"`
+	Id      json.RawMessage  `json:"id"`
+}
+
+type RpcError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *RpcErrorData `json:"data,omitempty"`
+}
+
+// RpcErrorData is the HTTP status of the REST endpoint for the same failure and, for client errors, why
+type RpcErrorData struct {
+	Status int    `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// GetArticleParams are the params of article.get
+type GetArticleParams struct {
+	Id string `json:"id"`
+}
+
+// FindArticlesParams are the params of article.find; without withImages all titles are found
+type FindArticlesParams struct {
+	WithImages *bool `json:"withImages,omitempty"`
+}
+
+// AttachImageParams are the params of article.attachImage; the image is base64 encoded
+type AttachImageParams struct {
+	ArticleId string `json:"articleId"`
+	Image     []byte `json:"image"`
+}
+
+var nullId = json.RawMessage("null")
+
+// Handle answers a call or a batch of calls. The calls of a batch are run in order; notifications
+// are run as well, but not answered, so a batch of notifications only is answered with 204.
+func (c *RpcController) Handle(context *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(context.Writer, context.Request.Body, maxRpcBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			handleError(context, c.Logger, err, http.StatusRequestEntityTooLarge)
+			return
+		}
+		handleError(context, c.Logger, err, http.StatusBadRequest)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		response := c.call(context, body)
+		if response == nil {
+			context.Status(http.StatusNoContent)
+			return
+		}
+		context.JSON(http.StatusOK, response)
+		return
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		context.JSON(http.StatusOK, rpcFailure(nullId, RpcParseError, err))
+		return
+	}
+	if len(batch) == 0 || len(batch) > maxRpcBatchSize {
+		context.JSON(http.StatusOK, rpcFailure(nullId, RpcInvalidRequest, fmt.Errorf("a batch holds 1 to %d calls", maxRpcBatchSize)))
+		return
+	}
+
+	responses := make([]*RpcResponse, 0, len(batch))
+	for _, request := range batch {
+		if response := c.call(context, request); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		context.Status(http.StatusNoContent)
+		return
+	}
+	context.JSON(http.StatusOK, responses)
+}
+
+// Runs one call; nil for notifications
+func (c *RpcController) call(context *gin.Context, data json.RawMessage) *RpcResponse {
+	// the id is read on its own, as a missing id (notification) differs from a null id
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || len(data) == 0 {
+			return rpcFailure(nullId, RpcParseError, err)
+		}
+		return rpcFailure(nullId, RpcInvalidRequest, err)
+	}
+
+	id, notification := fields["id"], false
+	if id == nil {
+		notification = true
+	} else if !isRpcId(id) {
+		return rpcFailure(nullId, RpcInvalidRequest, errors.New("the id is not a string, number or null"))
+	}
+
+	var request RpcRequest
+	if err := json.Unmarshal(data, &request); err != nil || request.Jsonrpc != "2.0" || request.Method == "" {
+		if notification {
+			return nil
+		}
+		return rpcFailure(id, RpcInvalidRequest, errors.New(`not a JSON-RPC 2.0 request; jsonrpc is "2.0" and method is a string`))
+	}
+
+	result, err := c.run(requestContext(context), request)
+	if notification {
+		if err != nil {
+			logging.FromContext(context.Request.Context(), c.Logger).WithError(err).WithField("method", request.Method).Info("notification failed")
+		}
+		return nil
+	}
+	if err != nil {
+		return c.rpcError(context, id, request.Method, err)
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return c.rpcError(context, id, request.Method, err)
+	}
+	message := json.RawMessage(raw)
+	return &RpcResponse{Jsonrpc: "2.0", Result: &message, Id: id}
+}
+
+// rpcParamsError is params that do not fit the method
+type rpcParamsError struct {
+	err error
+}
+
+func (e *rpcParamsError) Error() string {
+	return e.err.Error()
+}
+
+// rpcMethodError is an unknown method
+type rpcMethodError struct {
+	method string
+}
+
+func (e *rpcMethodError) Error() string {
+	return fmt.Sprintf("unknown method %q", e.method)
+}
+
+// Charges and checks the call before running it
+func (c *RpcController) run(ctx context.Context, request RpcRequest) (interface{}, error) {
+	budget := rpcBudget(request.Method)
+	if err := charge(ctx, c.Limit, budget); err != nil {
+		return nil, err
+	}
+	if budget != BudgetRead && c.CredentialsRequired && auth.FromContext(ctx) == nil {
+		return nil, fail(http.StatusUnauthorized, nil)
+	}
+	return c.dispatch(ctx, request)
+}
+
+// The budget of a method; unknown methods are charged as reads
+func rpcBudget(method string) string {
+	switch method {
+	case "article.create":
+		return BudgetCreate
+	case "article.attachImage":
+		return BudgetUpload
+	}
+	return BudgetRead
+}
+
+func (c *RpcController) dispatch(ctx context.Context, request RpcRequest) (interface{}, error) {
+	articles := c.ArticleController
+
+	switch request.Method {
+	case "article.create":
+		var params NewArticleBody
+		if err := decodeRpcParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+		created, err := articles.create(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return CreatedResponse{Id: created.Id.Hex()}, nil
+
+	case "article.get":
+		var params GetArticleParams
+		if err := decodeRpcParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+		articleId, err := primitive.ObjectIDFromHex(params.Id)
+		if err != nil {
+			return nil, &rpcParamsError{err: err}
+		}
+		return articles.get(ctx, articleId)
+
+	case "article.find":
+		var params FindArticlesParams
+		if err := decodeRpcParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+		return articles.find(ctx, params.WithImages)
+
+	case "article.attachImage":
+		var params AttachImageParams
+		if err := decodeRpcParams(request.Params, &params); err != nil {
+			return nil, err
+		}
+		articleId, err := primitive.ObjectIDFromHex(params.ArticleId)
+		if err != nil {
+			return nil, &rpcParamsError{err: err}
+		}
+		if len(params.Image) == 0 {
+			return nil, &rpcParamsError{err: errors.New("the image is empty")}
+		}
+		load := func() (*uploadedImage, error) {
+			return &uploadedImage{size: int64(len(params.Image)), save: func(path string) error {
+				return saveImage(ctx, params.Image, path)
+			}}, nil
+		}
+		return nil, articles.attachImage(ctx, articleId, load)
+	}
+	return nil, &rpcMethodError{method: request.Method}
+}
+
+// Decodes by-name params; missing params are the zero value of the params
+func decodeRpcParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || bytes.Equal(params, nullId) {
+		return nil
+	}
+	if params[0] != '{' {
+		return &rpcParamsError{err: errors.New("params are passed by name")}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcParamsError{err: err}
+	}
+	return nil
+}
+
+// Maps the error of a call to its error object
+func (c *RpcController) rpcError(context *gin.Context, id json.RawMessage, method string, err error) *RpcResponse {
+	var paramsErr *rpcParamsError
+	var methodErr *rpcMethodError
+	switch {
+	case errors.As(err, &paramsErr):
+		return rpcFailure(id, RpcInvalidParams, err)
+	case errors.As(err, &methodErr):
+		return rpcFailure(id, RpcMethodNotFound, err)
+	}
+
+	status := statusOf(err)
+	entry := logging.FromContext(context.Request.Context(), c.Logger).WithError(err).WithFields(logrus.Fields{"method": method, "status": status})
+	if status >= http.StatusInternalServerError {
+		entry.Error("call failed")
+	} else {
+		entry.Info("call rejected")
+	}
+
+	response := &RpcResponse{Jsonrpc: "2.0", Id: id, Error: &RpcError{Message: http.StatusText(status), Data: &RpcErrorData{Status: status}}}
+	switch status {
+	case http.StatusBadRequest:
+		response.Error.Code = RpcInvalidParams
+	case http.StatusUnauthorized:
+		response.Error.Code = RpcUnauthorized
+	case http.StatusForbidden:
+		response.Error.Code = RpcForbidden
+	case http.StatusNotFound:
+		response.Error.Code = RpcNotFound
+	case http.StatusTooManyRequests:
+		response.Error.Code = RpcTooManyRequests
+	default:
+		response.Error.Code = RpcInternalError
+	}
+
+	// the reasons of server errors are only logged
+	var requestErr *requestError
+	if status < http.StatusInternalServerError && errors.As(err, &requestErr) && requestErr.err != nil {
+		response.Error.Data.Reason = requestErr.err.Error()
+	}
+	return response
+}
+
+func rpcFailure(id json.RawMessage, code int, err error) *RpcResponse {
+	messages := map[int]string{
+		RpcParseError:     "Parse error",
+		RpcInvalidRequest: "Invalid Request",
+		RpcMethodNotFound: "Method not found",
+		RpcInvalidParams:  "Invalid params",
+		RpcInternalError:  "Internal error",
+	}
+
+	rpcErr := &RpcError{Code: code, Message: messages[code]}
+	if code != RpcInternalError && err != nil {
+		rpcErr.Data = &RpcErrorData{Status: http.StatusBadRequest, Reason: err.Error()}
+	}
+	return &RpcResponse{Jsonrpc: "2.0", Error: rpcErr, Id: id}
+}
+
+// Ids are strings, numbers or null
+func isRpcId(id json.RawMessage) bool {
+	var value interface{}
+	if err := json.Unmarshal(id, &value); err != nil {
+		return false
+	}
+	switch value.(type) {
+	case string, float64, nil:
+		return true
+	}
+	return false
+}
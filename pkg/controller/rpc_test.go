@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRpcController_Handle(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	missingId := primitive.NewObjectID()
+	handler := &mocks.MockArticleDbHandler{
+		InsertOneFunc: func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
+			return articleId, nil
+		},
+		FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+			if id == missingId {
+				return nil, nil
+			}
+			return &db.ArticleDb{Id: id, Title: "Test_Title", AuthorId: "editor-1"}, nil
+		},
+		FindAllTitlesFunc: func(ctx context.Context) ([]string, error) {
+			return []string{"Test_Title"}, nil
+		},
+	}
+	viewer := &auth.Identity{Subject: "viewer-1", Roles: []string{authz.RoleViewer}}
+	// allows the calls of the budget only
+	only := func(allowed string) CallLimiter {
+		return func(ctx context.Context, budget string, clientIp string) (bool, int) {
+			return budget == allowed, 30
+		}
+	}
+
+	tests := []struct {
+		name                string
+		identity            *auth.Identity
+		limit               CallLimiter
+		credentialsRequired bool
+		body                string
+		expectedStatus      int
+		expectedBody        string // compared as JSON
+	}{
+		{
+			name:           "Successfully create an article",
+			body:           `{"jsonrpc": "2.0", "method": "article.create", "params": {"title": "Test_Title", "description": "Test_Description", "expirationDate": "2030-01-01T00:00:00Z"}, "id": 1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "result": {"id": "` + articleId.Hex() + `"}, "id": 1}`,
+		},
+		{
+			name:           "Successfully find titles",
+			body:           `{"jsonrpc": "2.0", "method": "article.find", "id": "a"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "result": ["Test_Title"], "id": "a"}`,
+		},
+		{
+			name:           "Successfully answer a batch without its notifications",
+			body:           `[{"jsonrpc": "2.0", "method": "article.find"}, {"jsonrpc": "2.0", "method": "article.unknown", "id": 2}, {"jsonrpc": "2.0", "method": "article.find", "id": 3}]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found", "data": {"status": 400, "reason": "unknown method \"article.unknown\""}}, "id": 2}, {"jsonrpc": "2.0", "result": ["Test_Title"], "id": 3}]`,
+		},
+		{
+			name:           "Successfully run notifications only",
+			body:           `[{"jsonrpc": "2.0", "method": "article.find"}]`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Prevent invalid JSON",
+			body:           `{"jsonrpc": "2.0", "method"`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error", "data": {"status": 400, "reason": "unexpected end of JSON input"}}, "id": null}`,
+		},
+		{
+			name:           "Prevent empty batch",
+			body:           `[]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request", "data": {"status": 400, "reason": "a batch holds 1 to 100 calls"}}, "id": null}`,
+		},
+		{
+			name:           "Prevent request of another version",
+			body:           `{"jsonrpc": "1.0", "method": "article.find", "id": 1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request", "data": {"status": 400, "reason": "not a JSON-RPC 2.0 request; jsonrpc is \"2.0\" and method is a string"}}, "id": 1}`,
+		},
+		{
+			name:           "Prevent positional params",
+			body:           `{"jsonrpc": "2.0", "method": "article.get", "params": ["` + articleId.Hex() + `"], "id": 1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": {"status": 400, "reason": "params are passed by name"}}, "id": 1}`,
+		},
+		{
+			name:           "Prevent invalid article",
+			body:           `{"jsonrpc": "2.0", "method": "article.create", "params": {"description": "Test_Description"}, "id": 1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Bad Request", "data": {"status": 400, "reason": "Key: 'NewArticleBody.Title' Error:Field validation for 'Title' failed on the 'required' tag\nKey: 'NewArticleBody.ExpirationDate' Error:Field validation for 'ExpirationDate' failed on the 'required' tag"}}, "id": 1}`,
+		},
+		{
+			name:           "Prevent getting missing article",
+			body:           `{"jsonrpc": "2.0", "method": "article.get", "params": {"id": "` + missingId.Hex() + `"}, "id": 1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "error": {"code": -32004, "message": "Not Found", "data": {"status": 404}}, "id": 1}`,
+		},
+		{
+			name:           "Prevent viewer attaching",
+			identity:       viewer,
+			body:           `{"jsonrpc": "2.0", "method": "article.attachImage", "params": {"articleId": "` + articleId.Hex() + `", "image": "aW1hZ2U="}, "id": 1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "error": {"code": -32003, "message": "Forbidden", "data": {"status": 403}}, "id": 1}`,
+		},
+		{
+			name:           "Prevent creating with a spent create budget",
+			limit:          only(BudgetRead),
+			body:           `[{"jsonrpc": "2.0", "method": "article.create", "params": {"title": "Test_Title", "description": "Test_Description", "expirationDate": "2030-01-01T00:00:00Z"}, "id": 1}, {"jsonrpc": "2.0", "method": "article.find", "id": 2}]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"jsonrpc": "2.0", "error": {"code": -32029, "message": "Too Many Requests", "data": {"status": 429, "reason": "the create budget is spent; retry after 30 seconds"}}, "id": 1}, {"jsonrpc": "2.0", "result": ["Test_Title"], "id": 2}]`,
+		},
+		{
+			name:           "Successfully charge attaching to the upload budget",
+			limit:          only(BudgetUpload),
+			body:           `{"jsonrpc": "2.0", "method": "article.attachImage", "params": {"articleId": "` + articleId.Hex() + `", "image": "aW1hZ2U="}, "id": 1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "result": null, "id": 1}`,
+		},
+		{
+			name:                "Successfully get anonymously when credentials are required",
+			credentialsRequired: true,
+			body:                `{"jsonrpc": "2.0", "method": "article.get", "params": {"id": "` + missingId.Hex() + `"}, "id": 1}`,
+			expectedStatus:      http.StatusOK,
+			expectedBody:        `{"jsonrpc": "2.0", "error": {"code": -32004, "message": "Not Found", "data": {"status": 404}}, "id": 1}`,
+		},
+		{
+			name:                "Prevent anonymous create when credentials are required",
+			credentialsRequired: true,
+			body:                `{"jsonrpc": "2.0", "method": "article.create", "params": {"title": "Test_Title", "description": "Test_Description", "expirationDate": "2030-01-01T00:00:00Z"}, "id": 1}`,
+			expectedStatus:      http.StatusOK,
+			expectedBody:        `{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Unauthorized", "data": {"status": 401}}, "id": 1}`,
+		},
+		{
+			name:           "Successfully attach an image",
+			body:           `{"jsonrpc": "2.0", "method": "article.attachImage", "params": {"articleId": "` + articleId.Hex() + `", "image": "aW1hZ2U="}, "id": 1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"jsonrpc": "2.0", "result": null, "id": 1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directory := t.TempDir()
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Request = withoutAuthentication(httptest.NewRequest("POST", "/rpc", strings.NewReader(tt.body)))
+			context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), tt.identity))
+
+			c := &RpcController{ArticleController: &ArticleController{
+				ImageDirectory:     directory,
+				GenerateIdentifier: func() string { return "image" },
+				ArticleDbHandler:   handler,
+				Validate:           validator.New(validator.WithRequiredStructEnabled()),
+			}, Limit: tt.limit, CredentialsRequired: tt.credentialsRequired}
+			c.Handle(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("RpcController_Handle() = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+			if tt.expectedBody == "" {
+				return
+			}
+			var found, expected interface{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &found); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.expectedBody), &expected); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			foundJSON, _ := json.Marshal(found)
+			expectedJSON, _ := json.Marshal(expected)
+			if string(foundJSON) != string(expectedJSON) {
+				t.Errorf("RpcController_Handle() body = %s, want %s", foundJSON, expectedJSON)
+			}
+		})
+	}
+}
+
+func TestRpcController_Handle_AttachImage(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	var savedPath string
+	handler := &mocks.MockArticleDbHandler{
+		FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+			return &db.ArticleDb{Id: id, Title: "Test_Title"}, nil
+		},
+		AppendImageFunc: func(ctx context.Context, id primitive.ObjectID, path string) (bool, error) {
+			savedPath = path
+			return true, nil
+		},
+	}
+
+	context, _ := gin.CreateTestContext(httptest.NewRecorder())
+	body := `{"jsonrpc": "2.0", "method": "article.attachImage", "params": {"articleId": "` + articleId.Hex() + `", "image": "aW1hZ2U="}, "id": 1}`
+	context.Request = withoutAuthentication(httptest.NewRequest("POST", "/rpc", strings.NewReader(body)))
+
+	c := &RpcController{ArticleController: &ArticleController{
+		ImageDirectory:     t.TempDir(),
+		GenerateIdentifier: func() string { return "image" },
+		ArticleDbHandler:   handler,
+	}}
+	c.Handle(context)
+
+	image, err := os.ReadFile(savedPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(image) != "image" {
+		t.Errorf("RpcController_Handle() saved %q, want the decoded image", image)
+	}
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Delete moves the article to the trash, from where it can be restored until it is purged
+func (c *ArticleController) Delete(context *gin.Context) {
+	articleId, err := primitive.ObjectIDFromHex(context.Param("articleId"))
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := c.delete(requestContext(context), articleId); err != nil {
+		c.handleRequestError(context, err)
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
+
+// Moves the article to the trash and records the deletion
+func (c *ArticleController) delete(ctx context.Context, articleId primitive.ObjectID) error {
+	article, err := c.ArticleDbHandler.FindOneById(ctx, articleId)
+	if err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
+
+	if article == nil {
+		return fail(http.StatusNotFound, nil)
+	}
+
+	if err := c.permit(ctx, authz.ActionDelete, article.AuthorId); err != nil {
+		return err
+	}
+
+	found, err := c.ArticleDbHandler.SoftDelete(ctx, articleId)
+	if err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
+
+	// deleted concurrently
+	if !found {
+		return fail(http.StatusNotFound, nil)
+	}
+
+	deleted := *article
+	deletedAt := time.Now()
+	deleted.DeletedAt = &deletedAt
+	c.audit(ctx, db.AuditActionDelete, articleId, article, &deleted)
+	return nil
+}
+
+// Restore moves the article out of the trash
+func (c *ArticleController) Restore(context *gin.Context) {
+	articleId, err := primitive.ObjectIDFromHex(context.Param("articleId"))
+	if err != nil {
+		c.handleError(context, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := c.restore(requestContext(context), articleId); err != nil {
+		c.handleRequestError(context, err)
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
+
+// Moves the article out of the trash and records the restoration
+func (c *ArticleController) restore(ctx context.Context, articleId primitive.ObjectID) error {
+	article, err := c.ArticleDbHandler.FindOneInTrashById(ctx, articleId)
+	if err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
+
+	if article == nil {
+		return fail(http.StatusNotFound, nil)
+	}
+
+	if err := c.permit(ctx, authz.ActionDelete, article.AuthorId); err != nil {
+		return err
+	}
+
+	found, err := c.ArticleDbHandler.Restore(ctx, articleId)
+	if err != nil {
+		return fail(http.StatusInternalServerError, err)
+	}
+
+	// restored or purged concurrently
+	if !found {
+		return fail(http.StatusNotFound, nil)
+	}
+
+	restored := *article
+	restored.DeletedAt = nil
+	c.audit(ctx, db.AuditActionRestore, articleId, article, &restored)
+	return nil
+}
+
+// FindTrash returns the articles in the trash of the tenant
+func (c *ArticleController) FindTrash(context *gin.Context) {
+	articles, err := c.findTrash(requestContext(context))
+	if err != nil {
+		c.handleRequestError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, articles)
+}
+
+// The articles in the trash of the tenant
+func (c *ArticleController) findTrash(ctx context.Context) ([]db.ArticleDb, error) {
+	if err := c.permit(ctx, authz.ActionRead, ""); err != nil {
+		return nil, err
+	}
+
+	articles, err := c.ArticleDbHandler.FindTrash(ctx)
+	if err != nil {
+		return nil, fail(http.StatusInternalServerError, err)
+	}
+	return articles, nil
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func createArticleIdContext(articleId string, identity *auth.Identity) *gin.Context {
+	context, _ := gin.CreateTestContext(httptest.NewRecorder())
+	context.Params = gin.Params{{Key: "articleId", Value: articleId}}
+	context.Request = httptest.NewRequest("POST", "/article/"+articleId, nil)
+	context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), identity))
+	return context
+}
+
+func TestArticleController_Delete(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	author := &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}
+	findArticle := func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+		return &db.ArticleDb{Id: id, Title: "Test_Title", AuthorId: "editor-1"}, nil
+	}
+
+	tests := []struct {
+		name           string
+		articleId      string
+		identity       *auth.Identity
+		handler        *mocks.MockArticleDbHandler
+		expectedStatus int
+		expectedAudit  bool
+	}{
+		{name: "Prevent invalid id", articleId: "invalid", identity: author, handler: &mocks.MockArticleDbHandler{}, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent deleting unknown article", articleId: articleId.Hex(), identity: author, handler: &mocks.MockArticleDbHandler{}, expectedStatus: http.StatusNotFound},
+		{
+			name:           "Prevent editor deleting article of someone else",
+			articleId:      articleId.Hex(),
+			identity:       &auth.Identity{Subject: "editor-2", Roles: []string{authz.RoleEditor}},
+			handler:        &mocks.MockArticleDbHandler{FindOneByIdFunc: findArticle},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:      "internal error - softDelete failure",
+			articleId: articleId.Hex(),
+			identity:  author,
+			handler: &mocks.MockArticleDbHandler{FindOneByIdFunc: findArticle, SoftDeleteFunc: func(ctx context.Context, id primitive.ObjectID) (bool, error) {
+				return false, fmt.Errorf("test failure")
+			}},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:      "success",
+			articleId: articleId.Hex(),
+			identity:  author,
+			handler: &mocks.MockArticleDbHandler{FindOneByIdFunc: findArticle, SoftDeleteFunc: func(ctx context.Context, id primitive.ObjectID) (bool, error) {
+				return true, nil
+			}},
+			expectedStatus: http.StatusNoContent,
+			expectedAudit:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []db.AuditEntryDb
+			c := &ArticleController{ArticleDbHandler: tt.handler, AuditDbHandler: &mocks.MockAuditDbHandler{InsertOneFunc: func(ctx context.Context, new db.AuditEntryDb) error {
+				entries = append(entries, new)
+				return nil
+			}}}
+
+			context := createArticleIdContext(tt.articleId, tt.identity)
+			c.Delete(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_Delete() = %v, want %v", foundStatus, tt.expectedStatus)
+				return
+			}
+			if tt.expectedAudit && (len(entries) != 1 || entries[0].Action != db.AuditActionDelete || entries[0].After.DeletedAt == nil) {
+				t.Errorf("ArticleController_Delete() audit entries = %+v", entries)
+			}
+		})
+	}
+}
+
+func TestArticleController_Restore(t *testing.T) {
+	articleId := primitive.NewObjectID()
+	author := &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}
+	findArticle := func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+		return &db.ArticleDb{Id: id, Title: "Test_Title", AuthorId: "editor-1"}, nil
+	}
+
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		handler        *mocks.MockArticleDbHandler
+		expectedStatus int
+	}{
+		{name: "Prevent restoring article not in the trash", identity: author, handler: &mocks.MockArticleDbHandler{}, expectedStatus: http.StatusNotFound},
+		{
+			name:           "Prevent viewer restoring",
+			identity:       &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleViewer}},
+			handler:        &mocks.MockArticleDbHandler{FindOneInTrashByIdFunc: findArticle},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "Prevent restoring article purged concurrently",
+			identity: author,
+			handler: &mocks.MockArticleDbHandler{FindOneInTrashByIdFunc: findArticle, RestoreFunc: func(ctx context.Context, id primitive.ObjectID) (bool, error) {
+				return false, nil
+			}},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:     "success",
+			identity: author,
+			handler: &mocks.MockArticleDbHandler{FindOneInTrashByIdFunc: findArticle, RestoreFunc: func(ctx context.Context, id primitive.ObjectID) (bool, error) {
+				return true, nil
+			}},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ArticleController{ArticleDbHandler: tt.handler}
+
+			context := createArticleIdContext(articleId.Hex(), tt.identity)
+			c.Restore(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("ArticleController_Restore() = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/webhook"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WebhookController struct {
+	WebhookDbHandler         db.WebhookDbHandlerInterface
+	WebhookDeliveryDbHandler db.WebhookDeliveryDbHandlerInterface
+	Validate                 *validator.Validate
+	Resolver                 webhook.Resolver // resolves the hosts of the urls; defaults to net.DefaultResolver
+	AllowPrivateTargets      bool             // accepts urls of private, loopback and link-local addresses
+	Logger                   *logrus.Logger
+}
+
+type NewWebhookBody struct {
+	Url    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=created updated image-attached deleted restored expired"`
+	Secret string   `json:"secret" validate:"required,min=16,max=256"` // signs the deliveries; never returned
+}
+
+// Create subscribes a url to article events of the tenant
+func (c *WebhookController) Create(context *gin.Context) {
+	if !authorize(context, c.Logger, authz.ActionManageWebhooks, "") {
+		return
+	}
+
+	body := &NewWebhookBody{}
+	if err := context.BindJSON(body); err != nil {
+		handleError(context, c.Logger, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Validate.Struct(body); err != nil {
+		handleError(context, c.Logger, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := webhook.CheckTarget(context.Request.Context(), c.Resolver, body.Url, c.AllowPrivateTargets); err != nil {
+		handleError(context, c.Logger, err, http.StatusBadRequest)
+		return
+	}
+
+	id, err := c.WebhookDbHandler.InsertOne(context.Request.Context(), db.WebhookDb{
+		Url:       body.Url,
+		Events:    body.Events,
+		Secret:    body.Secret,
+		CreatedBy: authorId(context.Request.Context()),
+	})
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusInternalServerError)
+		return
+	}
+
+	context.JSON(http.StatusCreated, CreatedResponse{Id: id.Hex()})
+}
+
+// Find returns the webhooks of the tenant without their secrets
+func (c *WebhookController) Find(context *gin.Context) {
+	if !authorize(context, c.Logger, authz.ActionManageWebhooks, "") {
+		return
+	}
+
+	webhooks, err := c.WebhookDbHandler.FindAll(context.Request.Context())
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusInternalServerError)
+		return
+	}
+
+	context.JSON(http.StatusOK, webhooks)
+}
+
+// Delete unsubscribes the webhook; pending deliveries are given up
+func (c *WebhookController) Delete(context *gin.Context) {
+	webhookId, ok := c.webhookId(context)
+	if !ok {
+		return
+	}
+
+	found, err := c.WebhookDbHandler.DeleteOne(context.Request.Context(), webhookId)
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		handleError(context, c.Logger, nil, http.StatusNotFound)
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
+
+// FindDeliveries returns the delivery history of the webhook, newest first; the status query parameter
+// narrows it down, e.g. status=dead lists the dead letters
+func (c *WebhookController) FindDeliveries(context *gin.Context) {
+	webhookId, ok := c.webhookId(context)
+	if !ok {
+		return
+	}
+
+	status := context.Query("status")
+	if status != "" && status != db.DeliveryStatusPending && status != db.DeliveryStatusDelivered && status != db.DeliveryStatusDead {
+		handleError(context, c.Logger, errors.New("unknown delivery status"), http.StatusBadRequest)
+		return
+	}
+
+	if !c.exists(context, webhookId) {
+		return
+	}
+
+	deliveries, err := c.WebhookDeliveryDbHandler.FindByWebhookId(context.Request.Context(), webhookId, status)
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusInternalServerError)
+		return
+	}
+
+	context.JSON(http.StatusOK, deliveries)
+}
+
+// RetryDelivery delivers a dead delivery again, starting with a new round of attempts
+func (c *WebhookController) RetryDelivery(context *gin.Context) {
+	webhookId, ok := c.webhookId(context)
+	if !ok {
+		return
+	}
+
+	deliveryId, err := primitive.ObjectIDFromHex(context.Param("deliveryId"))
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusBadRequest)
+		return
+	}
+
+	if !c.exists(context, webhookId) {
+		return
+	}
+
+	found, err := c.WebhookDeliveryDbHandler.Retry(context.Request.Context(), webhookId, deliveryId)
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusInternalServerError)
+		return
+	}
+
+	// only dead deliveries can be retried
+	if !found {
+		handleError(context, c.Logger, nil, http.StatusNotFound)
+		return
+	}
+
+	context.Status(http.StatusAccepted)
+}
+
+// Authorizes the request and parses the webhook id of the path; aborts the request when false
+func (c *WebhookController) webhookId(context *gin.Context) (primitive.ObjectID, bool) {
+	if !authorize(context, c.Logger, authz.ActionManageWebhooks, "") {
+		return primitive.NilObjectID, false
+	}
+
+	webhookId, err := primitive.ObjectIDFromHex(context.Param("webhookId"))
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusBadRequest)
+		return primitive.NilObjectID, false
+	}
+	return webhookId, true
+}
+
+// Reports whether the webhook exists for the tenant; aborts the request when false
+func (c *WebhookController) exists(context *gin.Context, webhookId primitive.ObjectID) bool {
+	webhook, err := c.WebhookDbHandler.FindOneById(context.Request.Context(), webhookId)
+	if err != nil {
+		handleError(context, c.Logger, err, http.StatusInternalServerError)
+		return false
+	}
+
+	if webhook == nil {
+		handleError(context, c.Logger, nil, http.StatusNotFound)
+		return false
+	}
+	return true
+}
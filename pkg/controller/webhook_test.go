@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeResolver map[string]string
+
+func (r fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return []net.IPAddr{{IP: net.ParseIP(r[host])}}, nil
+}
+
+func TestWebhookController_Create(t *testing.T) {
+	admin := &auth.Identity{Subject: "admin-1", Roles: []string{authz.RoleAdmin}}
+	editor := &auth.Identity{Subject: "editor-1", Roles: []string{authz.RoleEditor}}
+	valid := NewWebhookBody{Url: "https://example.com/hook", Events: []string{db.ArticleEventCreated, db.ArticleEventExpired}, Secret: "0123456789abcdef"}
+
+	tests := []struct {
+		name           string
+		body           NewWebhookBody
+		identity       *auth.Identity
+		expectedStatus int
+	}{
+		{name: "Successfully create", body: valid, identity: admin, expectedStatus: http.StatusCreated},
+		{name: "Prevent editors managing webhooks", body: valid, identity: editor, expectedStatus: http.StatusForbidden},
+		{name: "Prevent urls other than http", body: NewWebhookBody{Url: "ftp://example.com/hook", Events: valid.Events, Secret: valid.Secret}, identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent unknown events", body: NewWebhookBody{Url: valid.Url, Events: []string{"renamed"}, Secret: valid.Secret}, identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent no events", body: NewWebhookBody{Url: valid.Url, Secret: valid.Secret}, identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent short secrets", body: NewWebhookBody{Url: valid.Url, Events: valid.Events, Secret: "short"}, identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent hosts resolving to private addresses", body: NewWebhookBody{Url: "https://internal.example.com/hook", Events: valid.Events, Secret: valid.Secret}, identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent link-local addresses", body: NewWebhookBody{Url: "http://169.254.169.254/latest/meta-data", Events: valid.Events, Secret: valid.Secret}, identity: admin, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent anonymous managing webhooks without authentication", body: valid, identity: nil, expectedStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inserted *db.WebhookDb
+			c := &WebhookController{
+				WebhookDbHandler: &mocks.MockWebhookDbHandler{InsertOneFunc: func(ctx context.Context, new db.WebhookDb) (primitive.ObjectID, error) {
+					inserted = &new
+					return primitive.NewObjectID(), nil
+				}},
+				Validate: validator.New(validator.WithRequiredStructEnabled()),
+				Resolver: fakeResolver{"example.com": "93.184.216.34", "internal.example.com": "10.0.0.7"},
+			}
+
+			body, _ := json.Marshal(tt.body)
+			context, _ := gin.CreateTestContext(httptest.NewRecorder())
+			context.Request = withoutAuthentication(httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body)))
+			context.Request.Header.Set("Content-Type", "application/json")
+			context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), tt.identity))
+			c.Create(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedStatus {
+				t.Errorf("WebhookController_Create() status = %v, want %v", foundStatus, tt.expectedStatus)
+			}
+			if tt.expectedStatus == http.StatusCreated && (inserted == nil || inserted.Secret != tt.body.Secret || inserted.CreatedBy != "admin-1") {
+				t.Errorf("WebhookController_Create() inserted = %+v", inserted)
+			}
+			if tt.expectedStatus != http.StatusCreated && inserted != nil {
+				t.Errorf("WebhookController_Create() inserted = %+v, want none", inserted)
+			}
+		})
+	}
+}
+
+func TestWebhookController_FindDeliveries(t *testing.T) {
+	admin := &auth.Identity{Subject: "admin-1", Roles: []string{authz.RoleAdmin}}
+	webhookId := primitive.NewObjectID()
+
+	tests := []struct {
+		name           string
+		webhookId      string
+		query          string
+		found          bool
+		expectedStatus string
+		expectedCode   int
+	}{
+		{name: "Successfully find all", webhookId: webhookId.Hex(), found: true, expectedCode: http.StatusOK},
+		{name: "Successfully find the dead letters", webhookId: webhookId.Hex(), query: "?status=dead", found: true, expectedStatus: db.DeliveryStatusDead, expectedCode: http.StatusOK},
+		{name: "Prevent unknown status", webhookId: webhookId.Hex(), query: "?status=lost", found: true, expectedCode: http.StatusBadRequest},
+		{name: "Prevent invalid webhook id", webhookId: "invalid", found: true, expectedCode: http.StatusBadRequest},
+		{name: "Prevent unknown webhooks", webhookId: webhookId.Hex(), found: false, expectedCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var foundStatus string
+			c := &WebhookController{
+				WebhookDbHandler: &mocks.MockWebhookDbHandler{FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.WebhookDb, error) {
+					if !tt.found {
+						return nil, nil
+					}
+					return &db.WebhookDb{Id: id}, nil
+				}},
+				WebhookDeliveryDbHandler: &mocks.MockWebhookDeliveryDbHandler{FindByWebhookIdFunc: func(ctx context.Context, id primitive.ObjectID, status string) ([]db.WebhookDeliveryDb, error) {
+					foundStatus = status
+					return []db.WebhookDeliveryDb{}, nil
+				}},
+			}
+
+			context, _ := gin.CreateTestContext(httptest.NewRecorder())
+			context.Params = gin.Params{{Key: "webhookId", Value: tt.webhookId}}
+			context.Request = httptest.NewRequest(http.MethodGet, "/webhooks/"+tt.webhookId+"/deliveries"+tt.query, nil)
+			context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), admin))
+			c.FindDeliveries(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedCode {
+				t.Errorf("WebhookController_FindDeliveries() status = %v, want %v", foundStatus, tt.expectedCode)
+			}
+			if foundStatus != tt.expectedStatus {
+				t.Errorf("WebhookController_FindDeliveries() filtered by %v, want %v", foundStatus, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestWebhookController_RetryDelivery(t *testing.T) {
+	admin := &auth.Identity{Subject: "admin-1", Roles: []string{authz.RoleAdmin}}
+	webhookId := primitive.NewObjectID()
+
+	tests := []struct {
+		name         string
+		deliveryId   string
+		dead         bool
+		expectedCode int
+	}{
+		{name: "Successfully retry dead deliveries", deliveryId: primitive.NewObjectID().Hex(), dead: true, expectedCode: http.StatusAccepted},
+		{name: "Prevent retrying deliveries that are not dead", deliveryId: primitive.NewObjectID().Hex(), dead: false, expectedCode: http.StatusNotFound},
+		{name: "Prevent invalid delivery id", deliveryId: "invalid", expectedCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &WebhookController{
+				WebhookDbHandler: &mocks.MockWebhookDbHandler{FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.WebhookDb, error) {
+					return &db.WebhookDb{Id: id}, nil
+				}},
+				WebhookDeliveryDbHandler: &mocks.MockWebhookDeliveryDbHandler{RetryFunc: func(ctx context.Context, id primitive.ObjectID, deliveryId primitive.ObjectID) (bool, error) {
+					return tt.dead, nil
+				}},
+			}
+
+			context, _ := gin.CreateTestContext(httptest.NewRecorder())
+			context.Params = gin.Params{{Key: "webhookId", Value: webhookId.Hex()}, {Key: "deliveryId", Value: tt.deliveryId}}
+			context.Request = httptest.NewRequest(http.MethodPost, "/webhooks/"+webhookId.Hex()+"/deliveries/"+tt.deliveryId+"/retry", nil)
+			context.Request = context.Request.WithContext(auth.WithIdentity(context.Request.Context(), admin))
+			c.RetryDelivery(context)
+
+			if foundStatus := context.Writer.Status(); foundStatus != tt.expectedCode {
+				t.Errorf("WebhookController_RetryDelivery() status = %v, want %v", foundStatus, tt.expectedCode)
+			}
+		})
+	}
+}
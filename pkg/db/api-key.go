@@ -0,0 +1,76 @@
+package db
+
+import (
+	"article-management-service/pkg/logging"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ApiKeyDbHandler struct {
+	Logger *logrus.Logger
+	coll   *mongo.Collection
+}
+
+type ApiKeyDbHandlerInterface interface {
+	New(database *mongo.Database) error
+	InsertOne(ctx context.Context, new ApiKeyDb) (primitive.ObjectID, error)
+	FindOneByHash(ctx context.Context, hash string) (*ApiKeyDb, error)
+}
+
+// ApiKeyDb is a static api key; only the sha256 hash of the key is stored.
+type ApiKeyDb struct {
+	Id        primitive.ObjectID `bson:"_id,omitempty"`
+	Name      string             `bson:"name,omitempty"`
+	Hash      string             `bson:"hash"`
+	Subject   string             `bson:"subject"`
+	Tenant    string             `bson:"tenant,omitempty"`
+	Roles     []string           `bson:"roles,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt,omitempty"`
+	RevokedAt *time.Time         `bson:"revokedAt,omitempty"`
+}
+
+// Binds the apiKeys collection; the unique index on the hash is created by the migrations
+func (h *ApiKeyDbHandler) New(database *mongo.Database) error {
+	h.coll = database.Collection("apiKeys")
+	return nil
+}
+
+func (h *ApiKeyDbHandler) logError(ctx context.Context, operation string, err error) {
+	logging.FromContext(ctx, h.Logger).WithError(err).WithFields(logrus.Fields{
+		"collection": "apiKeys",
+		"operation":  operation,
+	}).Error("db operation failed")
+}
+
+// Inserts one api key in the db
+func (h *ApiKeyDbHandler) InsertOne(ctx context.Context, new ApiKeyDb) (primitive.ObjectID, error) {
+	result, err := h.coll.InsertOne(ctx, new)
+	if err != nil {
+		h.logError(ctx, "InsertOne", err)
+		return primitive.NilObjectID, err
+	}
+
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// Finds the api key that is not revoked by the hash of the key
+func (h *ApiKeyDbHandler) FindOneByHash(ctx context.Context, hash string) (*ApiKeyDb, error) {
+	filter := bson.M{"hash": hash, "revokedAt": bson.M{"$exists": false}}
+	var apiKey ApiKeyDb
+	err := h.coll.FindOne(ctx, filter).Decode(&apiKey)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		h.logError(ctx, "FindOneByHash", err)
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
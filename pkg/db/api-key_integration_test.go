@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestApiKeyDbHandler_FindOneByHash(t *testing.T) {
+	t.Parallel()
+
+	database, close := createDb(t)
+	defer close()
+
+	h := ApiKeyDbHandler{}
+	if err := h.New(database); err != nil {
+		t.Fatalf("ApiKeyDbHandler.New() error = %v, wantErr %v", err, false)
+	}
+
+	revokedAt := time.Now()
+	for _, apiKey := range []ApiKeyDb{
+		{Name: "importer", Hash: "valid_hash", Subject: "importer"},
+		{Name: "old", Hash: "revoked_hash", Subject: "old", RevokedAt: &revokedAt},
+	} {
+		if _, err := h.InsertOne(context.Background(), apiKey); err != nil {
+			t.Fatalf("ApiKeyDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+		}
+	}
+
+	t.Run("Successfully found the api key by hash", func(t *testing.T) {
+		found, err := h.FindOneByHash(context.Background(), "valid_hash")
+		if err != nil || found == nil || found.Subject != "importer" {
+			t.Errorf("ApiKeyDbHandler.FindOneByHash() = %v, %v, want subject %v", found, err, "importer")
+		}
+	})
+
+	t.Run("Successfully ignore revoked api keys", func(t *testing.T) {
+		found, err := h.FindOneByHash(context.Background(), "revoked_hash")
+		if err != nil || found != nil {
+			t.Errorf("ApiKeyDbHandler.FindOneByHash() = %v, %v, want %v", found, err, nil)
+		}
+	})
+
+	t.Run("Prevent inserting the same key twice", func(t *testing.T) {
+		if _, err := h.InsertOne(context.Background(), ApiKeyDb{Hash: "valid_hash", Subject: "other"}); err == nil {
+			t.Errorf("ApiKeyDbHandler.InsertOne() error = %v, wantErr %v", err, true)
+		}
+	})
+}
@@ -1,76 +1,293 @@
 package db
 
 import (
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"article-management-service/pkg/tracing"
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = tracing.Tracer("article-management-service/pkg/db")
+
 type ArticleDbHandler struct {
-	coll *mongo.Collection
+	Logger  *logrus.Logger
+	coll    *mongo.Collection
+	archive *mongo.Collection
 }
 
 type ArticleDbHandlerInterface interface {
 	New(database *mongo.Database) error
-	InsertOne(new ArticleDb) (primitive.ObjectID, error)
-	AppendImage(id primitive.ObjectID, path string) error
-	FindOneById(id primitive.ObjectID) (*ArticleDb, error)
-	FindAllTitles() ([]string, error)
-	FindTitlesByHasImage(withImage bool) ([]string, error)
+	InsertOne(ctx context.Context, new ArticleDb) (primitive.ObjectID, error)
+	InsertMany(ctx context.Context, new []ArticleDb) ([]primitive.ObjectID, error)
+	AppendImage(ctx context.Context, id primitive.ObjectID, path string) (bool, error)
+	UpdateOne(ctx context.Context, id primitive.ObjectID, update ArticleDb) (bool, error)
+	Renew(ctx context.Context, id primitive.ObjectID, expirationDate time.Time, maxRenewals int) (bool, error)
+	FindOneById(ctx context.Context, id primitive.ObjectID) (*ArticleDb, error)
+	FindAllTitles(ctx context.Context) ([]string, error)
+	FindTitlesByHasImage(ctx context.Context, withImage bool) ([]string, error)
+	Export(ctx context.Context, filter ExportFilter, handle func(article ArticleDb) error) error
+	FindPage(ctx context.Context, filter ExportFilter, after primitive.ObjectID, limit int64) ([]ArticleDb, error)
+	CountArticles(ctx context.Context) (int64, error)
+	CountImages(ctx context.Context) (int64, error)
+	FindAllImagePaths(ctx context.Context) ([]string, error)
+	SoftDelete(ctx context.Context, id primitive.ObjectID) (bool, error)
+	Restore(ctx context.Context, id primitive.ObjectID) (bool, error)
+	FindOneInTrashById(ctx context.Context, id primitive.ObjectID) (*ArticleDb, error)
+	FindTrash(ctx context.Context) ([]ArticleDb, error)
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) ([]ArticleDb, error)
+	ArchiveExpired(ctx context.Context, now time.Time) ([]ArticleDb, error)
+	HideExpired(ctx context.Context, now time.Time) (int64, error)
+	BackfillExpiryPolicy(ctx context.Context, policy string) (int64, error)
+	EnableChangeEvents(ctx context.Context) error
+	Watch(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error)
+	WatchAllTenants(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error)
+}
+
+// What happens to an article once it expires
+const (
+	ExpiryPolicyDelete  = "delete"  // removed by the TTL index
+	ExpiryPolicyArchive = "archive" // moved to the archive collection by the expiry job
+	ExpiryPolicyHide    = "hide"    // kept, but hidden from listings and marked hidden by the expiry job
+)
+
+func IsValidExpiryPolicy(policy string) bool {
+	return policy == ExpiryPolicyDelete || policy == ExpiryPolicyArchive || policy == ExpiryPolicyHide
 }
 
 // ArticleDbHandler implements ArticleDbHandlerInterface.
 type ArticleDb struct {
-	Id             primitive.ObjectID `bson:"_id,omitempty"`
-	Title          string             `bson:"title,omitempty"`
-	ExpirationDate time.Time          `bson:"expirationDate,omitempty"`
-	Description    string             `bson:"description,omitempty"`
-	ImageFilePaths []string           `bson:"imagePaths,omitempty"`
+	Id             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantId       string             `bson:"tenantId,omitempty" json:"tenantId,omitempty"`
+	AuthorId       string             `bson:"authorId,omitempty" json:"authorId,omitempty"`
+	Title          string             `bson:"title,omitempty" json:"title"`
+	ExpirationDate time.Time          `bson:"expirationDate,omitempty" json:"expirationDate"`
+	Description    string             `bson:"description,omitempty" json:"description"`
+	ImageFilePaths []string           `bson:"imagePaths,omitempty" json:"imagePaths,omitempty"`
+	DeletedAt      *time.Time         `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"` // set while the article is in the trash
+	ExpiryPolicy   string             `bson:"expiryPolicy,omitempty" json:"expiryPolicy,omitempty"`
+	Renewals       int                `bson:"renewals,omitempty" json:"renewals,omitempty"`
+	HiddenAt       *time.Time         `bson:"hiddenAt,omitempty" json:"hiddenAt,omitempty"` // set once an article with the hide policy expired
+}
+
+// archivedArticle is an expired article in the archive collection
+type archivedArticle struct {
+	ArticleDb  `bson:",inline"`
+	ArchivedAt time.Time `bson:"archivedAt"`
 }
 
-// Creates a new articles collection and adds indexes for ttl
+// Binds the articles collection and its archive and creates the articles collection with the validator of the
+// schema when missing; the indexes are created and the validator is updated by the migrations
 func (h *ArticleDbHandler) New(database *mongo.Database) error {
 	h.coll = database.Collection("articles")
+	h.archive = database.Collection("articles_archive")
 
-	// makes the expirationDate the TTL of the document
-	_, err := h.coll.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
-		Keys:    bson.D{{Key: "expirationDate", Value: 1}},
-		Options: options.Index().SetExpireAfterSeconds(0),
-	})
+	_, err := createArticles(context.TODO(), database, articleSchema)
 	return err
 }
 
+// Scopes the filter to the tenant of the context; articles without tenant (created before
+// multi-tenancy) belong to the default tenant
+func tenantFilter(ctx context.Context, filter bson.M) bson.M {
+	tenantId := tenant.FromContext(ctx)
+	if tenantId == tenant.Default {
+		filter["tenantId"] = bson.M{"$in": bson.A{tenant.Default, nil}}
+	} else {
+		filter["tenantId"] = tenantId
+	}
+	return filter
+}
+
+func isIndexNotFound(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && (commandErr.Code == 27 || commandErr.Name == "IndexNotFound" || commandErr.Name == "NamespaceNotFound")
+}
+
+// Hides expired articles that are not deleted by the TTL index from listings
+func notExpired(filter bson.M) bson.M {
+	filter["$or"] = bson.A{
+		bson.M{"expiryPolicy": bson.M{"$nin": bson.A{ExpiryPolicyArchive, ExpiryPolicyHide}}},
+		bson.M{"expirationDate": bson.M{"$gt": time.Now()}},
+	}
+	return filter
+}
+
+// Hides articles in the trash
+func notDeleted(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+	return filter
+}
+
+// Only matches articles in the trash
+func deleted(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": true}
+	return filter
+}
+
+// Starts a client span for a db operation on the articles collection
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "ArticleDbHandler."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMongoDB,
+			semconv.DBMongoDBCollection("articles"),
+			semconv.DBOperation(operation),
+		),
+	)
+}
+
+// Logs a failed db operation with the request id of the context, so it can be correlated with the request,
+// and marks the span of the operation as failed
+func (h *ArticleDbHandler) logError(ctx context.Context, operation string, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	logging.FromContext(ctx, h.Logger).WithError(err).WithFields(logrus.Fields{
+		"collection": "articles",
+		"operation":  operation,
+	}).Error("db operation failed")
+}
+
 // Inserts one article in the db
-func (h *ArticleDbHandler) InsertOne(new ArticleDb) (primitive.ObjectID, error) {
-	result, err := h.coll.InsertOne(context.TODO(), new)
+func (h *ArticleDbHandler) InsertOne(ctx context.Context, new ArticleDb) (primitive.ObjectID, error) {
+	ctx, span := startSpan(ctx, "InsertOne")
+	defer span.End()
+
+	new.TenantId = tenant.FromContext(ctx)
+	result, err := h.coll.InsertOne(ctx, new)
 	if err != nil {
+		h.logError(ctx, "InsertOne", err)
 		return primitive.NilObjectID, err
 	}
 
 	return result.InsertedID.(primitive.ObjectID), nil
 }
 
-// Appends an image path to an article in the db
-func (h *ArticleDbHandler) AppendImage(id primitive.ObjectID, path string) error {
+// Inserts the articles in one batch for the tenant of the context and returns their ids in order. The
+// insert stops at the first failing article; the articles before it stay inserted.
+func (h *ArticleDbHandler) InsertMany(ctx context.Context, new []ArticleDb) ([]primitive.ObjectID, error) {
+	ctx, span := startSpan(ctx, "InsertMany")
+	defer span.End()
+
+	if len(new) == 0 {
+		return []primitive.ObjectID{}, nil
+	}
+
+	tenantId := tenant.FromContext(ctx)
+	ids := make([]primitive.ObjectID, len(new))
+	documents := make([]interface{}, len(new))
+	for i, article := range new {
+		if article.Id.IsZero() {
+			article.Id = primitive.NewObjectID()
+		}
+		article.TenantId = tenantId
+		ids[i] = article.Id
+		documents[i] = article
+	}
+
+	if _, err := h.coll.InsertMany(ctx, documents); err != nil {
+		h.logError(ctx, "InsertMany", err)
+		// the inserts are ordered, so the documents before the first failed one are stored
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+			return ids[:bulkErr.WriteErrors[0].Index], err
+		}
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Appends an image path to an article in the db; false when there is no such article or it already has the
+// maximum amount of images
+func (h *ArticleDbHandler) AppendImage(ctx context.Context, id primitive.ObjectID, path string) (bool, error) {
+	ctx, span := startSpan(ctx, "AppendImage")
+	defer span.End()
+
+	// only matches while there is room for another image, so concurrent uploads do not exceed the limit
+	filter := bson.M{"_id": id, fmt.Sprintf("imagePaths.%d", maxImagePaths-1): bson.M{"$exists": false}}
 	update := bson.M{"$addToSet": bson.M{"imagePaths": path}} // should not have duplicate paths
-	_, err := h.coll.UpdateByID(context.TODO(), id, update)
-	return err
+	result, err := h.coll.UpdateOne(ctx, notDeleted(tenantFilter(ctx, filter)), update)
+	if err != nil {
+		h.logError(ctx, "AppendImage", err)
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// Replaces the title, description, expiration date and, when set, the expiry policy of an article; false when there is no such article
+func (h *ArticleDbHandler) UpdateOne(ctx context.Context, id primitive.ObjectID, update ArticleDb) (bool, error) {
+	ctx, span := startSpan(ctx, "UpdateOne")
+	defer span.End()
+
+	fields := bson.M{
+		"title":          update.Title,
+		"description":    update.Description,
+		"expirationDate": update.ExpirationDate,
+	}
+	if update.ExpiryPolicy != "" {
+		fields["expiryPolicy"] = update.ExpiryPolicy
+	}
+	set := bson.M{"$set": fields}
+	if update.ExpirationDate.After(time.Now()) {
+		set["$unset"] = bson.M{"hiddenAt": ""}
+	}
+	result, err := h.coll.UpdateOne(ctx, notDeleted(tenantFilter(ctx, bson.M{"_id": id})), set)
+	if err != nil {
+		h.logError(ctx, "UpdateOne", err)
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// Sets a new expiration date and counts the renewal; false when there is no such article or it was
+// already renewed maxRenewals times (0 is unlimited)
+func (h *ArticleDbHandler) Renew(ctx context.Context, id primitive.ObjectID, expirationDate time.Time, maxRenewals int) (bool, error) {
+	ctx, span := startSpan(ctx, "Renew")
+	defer span.End()
+
+	filter := notDeleted(tenantFilter(ctx, bson.M{"_id": id}))
+	if maxRenewals > 0 {
+		filter["renewals"] = bson.M{"$not": bson.M{"$gte": maxRenewals}} // also matches articles never renewed
+	}
+	update := bson.M{"$set": bson.M{"expirationDate": expirationDate}, "$inc": bson.M{"renewals": 1}}
+	if expirationDate.After(time.Now()) {
+		update["$unset"] = bson.M{"hiddenAt": ""}
+	}
+
+	result, err := h.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		h.logError(ctx, "Renew", err)
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
 }
 
 // Finds one article in the db using the indexed id
-func (h *ArticleDbHandler) FindOneById(id primitive.ObjectID) (*ArticleDb, error) {
-	filter := bson.D{{Key: "_id", Value: id}}
+func (h *ArticleDbHandler) FindOneById(ctx context.Context, id primitive.ObjectID) (*ArticleDb, error) {
+	ctx, span := startSpan(ctx, "FindOneById")
+	defer span.End()
+
+	filter := notDeleted(tenantFilter(ctx, bson.M{"_id": id}))
 	var article ArticleDb
-	err := h.coll.FindOne(context.TODO(), filter).Decode(&article)
+	err := h.coll.FindOne(ctx, filter).Decode(&article)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
 		}
+		h.logError(ctx, "FindOneById", err)
 		return nil, err
 	}
 
@@ -78,10 +295,11 @@ func (h *ArticleDbHandler) FindOneById(id primitive.ObjectID) (*ArticleDb, error
 }
 
 // Helper function to consumes an article cursor and returns an title array
-func consumeArticleCursorForTitles(cur *mongo.Cursor) ([]string, error) {
+func consumeArticleCursorForTitles(ctx context.Context, cur *mongo.Cursor) ([]string, error) {
+	defer cur.Close(ctx)
 	titles := make([]string, 0)
 
-	for cur.Next(context.TODO()) {
+	for cur.Next(ctx) {
 		var a ArticleDb
 		if err := cur.Decode(&a); err != nil {
 			return nil, err
@@ -90,28 +308,385 @@ func consumeArticleCursorForTitles(cur *mongo.Cursor) ([]string, error) {
 		titles = append(titles, a.Title)
 	}
 
-	return titles, nil
+	return titles, cur.Err()
 }
 
 // Finds all titles of the articles in the db
-func (h *ArticleDbHandler) FindAllTitles() ([]string, error) {
-	cur, err := h.coll.Find(context.TODO(), bson.D{{}})
+func (h *ArticleDbHandler) FindAllTitles(ctx context.Context) ([]string, error) {
+	ctx, span := startSpan(ctx, "FindAllTitles")
+	defer span.End()
+
+	cur, err := h.coll.Find(ctx, notExpired(notDeleted(tenantFilter(ctx, bson.M{}))))
 	if err != nil {
+		h.logError(ctx, "FindAllTitles", err)
 		return nil, err
 	}
 
-	titles, err := consumeArticleCursorForTitles(cur)
+	titles, err := consumeArticleCursorForTitles(ctx, cur)
+	if err != nil {
+		h.logError(ctx, "FindAllTitles", err)
+	}
 	return titles, err
 }
 
 // Finds all titles of the articles if they have an image or not in the db
-func (h *ArticleDbHandler) FindTitlesByHasImage(withImage bool) ([]string, error) {
-	filter := bson.M{"imagePaths.0": bson.M{"$exists": withImage}}
-	cur, err := h.coll.Find(context.TODO(), filter)
+func (h *ArticleDbHandler) FindTitlesByHasImage(ctx context.Context, withImage bool) ([]string, error) {
+	ctx, span := startSpan(ctx, "FindTitlesByHasImage")
+	defer span.End()
+
+	filter := notExpired(notDeleted(tenantFilter(ctx, bson.M{"imagePaths.0": bson.M{"$exists": withImage}})))
+	cur, err := h.coll.Find(ctx, filter)
 	if err != nil {
+		h.logError(ctx, "FindTitlesByHasImage", err)
 		return nil, err
 	}
 
-	titles, err := consumeArticleCursorForTitles(cur)
+	titles, err := consumeArticleCursorForTitles(ctx, cur)
+	if err != nil {
+		h.logError(ctx, "FindTitlesByHasImage", err)
+	}
 	return titles, err
 }
+
+// ExportFilter narrows the exported articles down; zero values do not filter
+type ExportFilter struct {
+	WithImage      *bool
+	ExpiresFrom    time.Time // inclusive
+	ExpiresTo      time.Time // exclusive
+	IncludeExpired bool      // includes expired articles that are hidden from listings
+}
+
+// The query of the articles of the tenant that pass the filter
+func (filter ExportFilter) query(ctx context.Context) bson.M {
+	query := notDeleted(tenantFilter(ctx, bson.M{}))
+	if !filter.IncludeExpired {
+		query = notExpired(query)
+	}
+	if filter.WithImage != nil {
+		query["imagePaths.0"] = bson.M{"$exists": *filter.WithImage}
+	}
+
+	expirationRange := bson.M{}
+	if !filter.ExpiresFrom.IsZero() {
+		expirationRange["$gte"] = filter.ExpiresFrom
+	}
+	if !filter.ExpiresTo.IsZero() {
+		expirationRange["$lt"] = filter.ExpiresTo
+	}
+	if len(expirationRange) > 0 {
+		query["expirationDate"] = expirationRange
+	}
+	return query
+}
+
+// Export passes the articles of the tenant to handle one at a time in the order of their creation, so they
+// are never all in memory; stops at the first error of handle and returns it
+func (h *ArticleDbHandler) Export(ctx context.Context, filter ExportFilter, handle func(article ArticleDb) error) error {
+	ctx, span := startSpan(ctx, "Export")
+	defer span.End()
+
+	query := filter.query(ctx)
+	cur, err := h.coll.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		h.logError(ctx, "Export", err)
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var article ArticleDb
+		if err := cur.Decode(&article); err != nil {
+			h.logError(ctx, "Export", err)
+			return err
+		}
+		if err := handle(article); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		h.logError(ctx, "Export", err)
+		return err
+	}
+	return nil
+}
+
+// FindPage returns up to limit articles of the tenant that pass the filter in the order of their creation,
+// starting after the given id; a nil id starts at the first article
+func (h *ArticleDbHandler) FindPage(ctx context.Context, filter ExportFilter, after primitive.ObjectID, limit int64) ([]ArticleDb, error) {
+	ctx, span := startSpan(ctx, "FindPage")
+	defer span.End()
+
+	query := filter.query(ctx)
+	if !after.IsZero() {
+		query["_id"] = bson.M{"$gt": after}
+	}
+
+	cur, err := h.coll.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		h.logError(ctx, "FindPage", err)
+		return nil, err
+	}
+
+	articles := make([]ArticleDb, 0)
+	if err := cur.All(ctx, &articles); err != nil {
+		h.logError(ctx, "FindPage", err)
+		return nil, err
+	}
+	return articles, nil
+}
+
+// Counts the articles of the tenant; articles in the trash count until they are purged
+func (h *ArticleDbHandler) CountArticles(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "CountArticles")
+	defer span.End()
+
+	count, err := h.coll.CountDocuments(ctx, tenantFilter(ctx, bson.M{}))
+	if err != nil {
+		h.logError(ctx, "CountArticles", err)
+	}
+	return count, err
+}
+
+// Counts the images attached to all articles of the tenant
+func (h *ArticleDbHandler) CountImages(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "CountImages")
+	defer span.End()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: tenantFilter(ctx, bson.M{})}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "count": bson.M{"$sum": bson.M{"$size": bson.M{"$ifNull": bson.A{"$imagePaths", bson.A{}}}}}}}},
+	}
+	cur, err := h.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		h.logError(ctx, "CountImages", err)
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var result struct {
+		Count int64 `bson:"count"`
+	}
+	if cur.Next(ctx) {
+		if err := cur.Decode(&result); err != nil {
+			h.logError(ctx, "CountImages", err)
+			return 0, err
+		}
+	}
+	return result.Count, cur.Err()
+}
+
+// FindAllImagePaths returns the paths of the images of every tenant that are still referenced, including the
+// images of articles in the trash and in the archive. The articles are iterated, as the result of distinct is
+// limited to the size of a document.
+func (h *ArticleDbHandler) FindAllImagePaths(ctx context.Context) ([]string, error) {
+	ctx, span := startSpan(ctx, "FindAllImagePaths")
+	defer span.End()
+
+	paths := make([]string, 0)
+	for _, coll := range []*mongo.Collection{h.coll, h.archive} {
+		cur, err := coll.Find(ctx, bson.M{"imagePaths.0": bson.M{"$exists": true}}, options.Find().SetProjection(bson.M{"_id": 0, "imagePaths": 1}))
+		if err != nil {
+			h.logError(ctx, "FindAllImagePaths", err)
+			return nil, err
+		}
+
+		for cur.Next(ctx) {
+			var article struct {
+				ImageFilePaths []string `bson:"imagePaths"`
+			}
+			if err := cur.Decode(&article); err != nil {
+				cur.Close(ctx)
+				h.logError(ctx, "FindAllImagePaths", err)
+				return nil, err
+			}
+			paths = append(paths, article.ImageFilePaths...)
+		}
+		err = cur.Err()
+		cur.Close(ctx)
+		if err != nil {
+			h.logError(ctx, "FindAllImagePaths", err)
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// Moves an article to the trash; false when there is no such article outside the trash
+func (h *ArticleDbHandler) SoftDelete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	ctx, span := startSpan(ctx, "SoftDelete")
+	defer span.End()
+
+	update := bson.M{"$set": bson.M{"deletedAt": time.Now()}}
+	result, err := h.coll.UpdateOne(ctx, notDeleted(tenantFilter(ctx, bson.M{"_id": id})), update)
+	if err != nil {
+		h.logError(ctx, "SoftDelete", err)
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// Moves an article out of the trash; false when there is no such article in the trash
+func (h *ArticleDbHandler) Restore(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	ctx, span := startSpan(ctx, "Restore")
+	defer span.End()
+
+	update := bson.M{"$unset": bson.M{"deletedAt": ""}}
+	result, err := h.coll.UpdateOne(ctx, deleted(tenantFilter(ctx, bson.M{"_id": id})), update)
+	if err != nil {
+		h.logError(ctx, "Restore", err)
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// Finds one article in the trash
+func (h *ArticleDbHandler) FindOneInTrashById(ctx context.Context, id primitive.ObjectID) (*ArticleDb, error) {
+	ctx, span := startSpan(ctx, "FindOneInTrashById")
+	defer span.End()
+
+	var article ArticleDb
+	err := h.coll.FindOne(ctx, deleted(tenantFilter(ctx, bson.M{"_id": id}))).Decode(&article)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		h.logError(ctx, "FindOneInTrashById", err)
+		return nil, err
+	}
+
+	return &article, nil
+}
+
+// Finds the articles in the trash of the tenant, most recently deleted first
+func (h *ArticleDbHandler) FindTrash(ctx context.Context) ([]ArticleDb, error) {
+	ctx, span := startSpan(ctx, "FindTrash")
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "deletedAt", Value: -1}})
+	cur, err := h.coll.Find(ctx, deleted(tenantFilter(ctx, bson.M{})), opts)
+	if err != nil {
+		h.logError(ctx, "FindTrash", err)
+		return nil, err
+	}
+
+	articles := make([]ArticleDb, 0)
+	if err := cur.All(ctx, &articles); err != nil {
+		h.logError(ctx, "FindTrash", err)
+		return nil, err
+	}
+	return articles, nil
+}
+
+// Permanently deletes the articles of all tenants that were moved to the trash before the given time;
+// returns the deleted articles so their images can be removed
+func (h *ArticleDbHandler) PurgeTrash(ctx context.Context, deletedBefore time.Time) ([]ArticleDb, error) {
+	ctx, span := startSpan(ctx, "PurgeTrash")
+	defer span.End()
+
+	filter := bson.M{"deletedAt": bson.M{"$lt": deletedBefore}}
+	cur, err := h.coll.Find(ctx, filter)
+	if err != nil {
+		h.logError(ctx, "PurgeTrash", err)
+		return nil, err
+	}
+
+	articles := make([]ArticleDb, 0)
+	if err := cur.All(ctx, &articles); err != nil {
+		h.logError(ctx, "PurgeTrash", err)
+		return nil, err
+	}
+	if len(articles) == 0 {
+		return articles, nil
+	}
+
+	ids := make(bson.A, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.Id)
+	}
+	// the deletedAt condition is repeated so an article restored in the meantime is kept
+	if _, err := h.coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "deletedAt": bson.M{"$lt": deletedBefore}}); err != nil {
+		h.logError(ctx, "PurgeTrash", err)
+		return nil, err
+	}
+	return articles, nil
+}
+
+// Moves the expired articles of all tenants with the archive policy to the archive collection; returns
+// the archived articles. Articles in the trash are left to the purge job.
+func (h *ArticleDbHandler) ArchiveExpired(ctx context.Context, now time.Time) ([]ArticleDb, error) {
+	ctx, span := startSpan(ctx, "ArchiveExpired")
+	defer span.End()
+
+	filter := notDeleted(bson.M{"expiryPolicy": ExpiryPolicyArchive, "expirationDate": bson.M{"$lte": now}})
+	cur, err := h.coll.Find(ctx, filter)
+	if err != nil {
+		h.logError(ctx, "ArchiveExpired", err)
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	archived := make([]ArticleDb, 0)
+	for cur.Next(ctx) {
+		var article ArticleDb
+		if err := cur.Decode(&article); err != nil {
+			h.logError(ctx, "ArchiveExpired", err)
+			return archived, err
+		}
+
+		// the copy is upserted first, so an interrupted run archives the article again rather than losing it
+		opts := options.Replace().SetUpsert(true)
+		if _, err := h.archive.ReplaceOne(ctx, bson.M{"_id": article.Id}, archivedArticle{ArticleDb: article, ArchivedAt: now}, opts); err != nil {
+			h.logError(ctx, "ArchiveExpired", err)
+			return archived, err
+		}
+		// the conditions are repeated so an article renewed or moved to the trash in the meantime is kept
+		result, err := h.coll.DeleteOne(ctx, notDeleted(bson.M{"_id": article.Id, "expiryPolicy": ExpiryPolicyArchive, "expirationDate": bson.M{"$lte": now}}))
+		if err != nil {
+			h.logError(ctx, "ArchiveExpired", err)
+			return archived, err
+		}
+		if result.DeletedCount == 0 {
+			if _, err := h.archive.DeleteOne(ctx, bson.M{"_id": article.Id}); err != nil {
+				h.logError(ctx, "ArchiveExpired", err)
+				return archived, err
+			}
+			continue
+		}
+		archived = append(archived, article)
+	}
+
+	if err := cur.Err(); err != nil {
+		h.logError(ctx, "ArchiveExpired", err)
+		return archived, err
+	}
+	return archived, nil
+}
+
+// Marks the expired articles of all tenants with the hide policy as hidden, so they are announced as expired;
+// returns the amount of hidden articles. Updates and renewals to a later expiration date unmark them.
+func (h *ArticleDbHandler) HideExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "HideExpired")
+	defer span.End()
+
+	filter := notDeleted(bson.M{"expiryPolicy": ExpiryPolicyHide, "expirationDate": bson.M{"$lte": now}, "hiddenAt": bson.M{"$exists": false}})
+	result, err := h.coll.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"hiddenAt": now}})
+	if err != nil {
+		h.logError(ctx, "HideExpired", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// Sets the expiry policy of the articles of all tenants that have none, e.g. created before expiry policies;
+// run by a migration
+func (h *ArticleDbHandler) BackfillExpiryPolicy(ctx context.Context, policy string) (int64, error) {
+	ctx, span := startSpan(ctx, "BackfillExpiryPolicy")
+	defer span.End()
+
+	backfilled, err := backfill(ctx, h.coll, bson.M{"expiryPolicy": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"expiryPolicy": policy}})
+	if err != nil {
+		h.logError(ctx, "BackfillExpiryPolicy", err)
+		return backfilled, err
+	}
+	return backfilled, nil
+}
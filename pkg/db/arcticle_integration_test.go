@@ -2,21 +2,30 @@ package db
 
 import (
 	"article-management-service/pkg/env"
+	"article-management-service/pkg/tenant"
+	"context"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func createDb(t *testing.T) (*mongo.Database, func()) {
+	return createDbWithReplica(t, false)
+}
+
+func createDbWithReplica(t *testing.T, replica bool) (*mongo.Database, func()) {
 	cfg, err := env.Load()
 	if err != nil {
 		panic(err)
 	}
 
-	mm := MockMongo{}
+	mm := MockMongo{Replica: replica}
 	uri, err := mm.HostMemoryDb(cfg.MongodPath)
 	if err != nil {
 		t.Error("Failed to launch memory db")
@@ -30,6 +39,16 @@ func createDb(t *testing.T) (*mongo.Database, func()) {
 		t.Fail()
 	}
 
+	// the indexes are created by the migrations
+	migrator := &Migrator{Migrations: Migrations(ExpiryPolicyDelete)}
+	if err := migrator.New(conn.Database); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Error("Failed to migrate the memory db")
+		t.FailNow()
+	}
+
 	return conn.Database, func() {
 		conn.Close()
 		mm.Close()
@@ -64,6 +83,50 @@ func TestArticleDbHandler_New(t *testing.T) {
 	})
 }
 
+func TestArticleDbHandler_Validator(t *testing.T) {
+	t.Parallel()
+	h, close := createColl(t)
+	defer close()
+
+	expirationDate := time.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		document bson.M
+		wantErr  bool
+	}{
+		{name: "Successfully insert valid articles", document: bson.M{"title": "Valid", "expirationDate": expirationDate, "description": "Test_Description", "imagePaths": bson.A{"1", "2", "3"}, "expiryPolicy": ExpiryPolicyHide}},
+		{name: "Prevent articles without title", document: bson.M{"expirationDate": expirationDate}, wantErr: true},
+		{name: "Prevent expiration dates that are no dates", document: bson.M{"title": "Invalid", "expirationDate": "tomorrow"}, wantErr: true},
+		{name: "Prevent more than 3 images", document: bson.M{"title": "Invalid", "imagePaths": bson.A{"1", "2", "3", "4"}}, wantErr: true},
+		{name: "Prevent too long descriptions", document: bson.M{"title": "Invalid", "description": strings.Repeat("a", 4001)}, wantErr: true},
+		{name: "Prevent unknown expiry policies", document: bson.M{"title": "Invalid", "expiryPolicy": "forever"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := h.coll.InsertOne(context.Background(), tt.document); (err != nil) != tt.wantErr {
+				t.Errorf("articles.InsertOne() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Successfully remove and restore the validator", func(t *testing.T) {
+		invalid := bson.M{"title": "Invalid", "imagePaths": bson.A{"1", "2", "3", "4"}}
+		if err := setArticleSchema(context.Background(), h.coll.Database(), nil); err != nil {
+			t.Fatalf("setArticleSchema() error = %v", err)
+		}
+		if _, err := h.coll.InsertOne(context.Background(), invalid); err != nil {
+			t.Errorf("articles.InsertOne() without validator error = %v, wantErr %v", err, false)
+		}
+
+		if err := setArticleSchema(context.Background(), h.coll.Database(), articleSchemaV1); err != nil {
+			t.Fatalf("setArticleSchema() error = %v", err)
+		}
+		if _, err := h.coll.InsertOne(context.Background(), invalid); err == nil {
+			t.Errorf("articles.InsertOne() with validator error = %v, wantErr %v", err, true)
+		}
+	})
+}
+
 func TestArticleDbHandler_InsertOne(t *testing.T) {
 	t.Parallel()
 
@@ -78,19 +141,20 @@ func TestArticleDbHandler_InsertOne(t *testing.T) {
 			ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), // have to truncate, because mongo does not store microseconds
 			Description:    "Test_Description",
 		}
-		id, err := h.InsertOne(article)
+		id, err := h.InsertOne(context.Background(), article)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
 			return
 		}
 
-		createdArticle, err := h.FindOneById(id)
+		createdArticle, err := h.FindOneById(context.Background(), id)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
 			return
 		}
 
 		article.Id = createdArticle.Id
+		article.TenantId = tenant.Default
 		article.ImageFilePaths = createdArticle.ImageFilePaths
 		if !reflect.DeepEqual(*createdArticle, article) {
 			t.Errorf("ArticleDbHandler.InsertOne() = %v, want %v", *createdArticle, article)
@@ -110,19 +174,20 @@ func TestArticleDbHandler_InsertOne(t *testing.T) {
 			Description:    "Test_Description",
 			ImageFilePaths: []string{"file_path"},
 		}
-		id, err := h.InsertOne(article)
+		id, err := h.InsertOne(context.Background(), article)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
 			return
 		}
 
-		createdArticle, err := h.FindOneById(id)
+		createdArticle, err := h.FindOneById(context.Background(), id)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
 			return
 		}
 
 		article.Id = createdArticle.Id
+		article.TenantId = tenant.Default
 		if !reflect.DeepEqual(*createdArticle, article) {
 			t.Errorf("ArticleDbHandler.InsertOne() = %v, want %v", *createdArticle, article)
 			return
@@ -144,16 +209,16 @@ func TestArticleDbHandler_AppendImage(t *testing.T) {
 			ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), // have to truncate, because mongo does not store microseconds
 			Description:    "Test_Description",
 		}
-		id, err := h.InsertOne(expected)
+		id, err := h.InsertOne(context.Background(), expected)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.AppendImage() error = %v, wantErr %v", err, false)
 			return
 		}
 
 		imagePath := "test_path"
-		h.AppendImage(id, imagePath)
+		h.AppendImage(context.Background(), id, imagePath)
 
-		createdArticle, err := h.FindOneById(id)
+		createdArticle, err := h.FindOneById(context.Background(), id)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.AppendImage() error = %v, wantErr %v", err, false)
 			return
@@ -161,6 +226,7 @@ func TestArticleDbHandler_AppendImage(t *testing.T) {
 
 		expected.ImageFilePaths = []string{imagePath}
 		expected.Id = createdArticle.Id
+		expected.TenantId = tenant.Default
 
 		if !reflect.DeepEqual(*createdArticle, expected) {
 			t.Errorf("ArticleDbHandler.AppendImage() = %v, want %v", *createdArticle, expected)
@@ -179,7 +245,7 @@ func TestArticleDbHandler_AppendImage(t *testing.T) {
 			ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), // have to truncate, because mongo does not store microseconds
 			Description:    "Test_Description",
 		}
-		id, err := h.InsertOne(expected)
+		id, err := h.InsertOne(context.Background(), expected)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.AppendImage() error = %v, wantErr %v", err, false)
 			return
@@ -187,10 +253,10 @@ func TestArticleDbHandler_AppendImage(t *testing.T) {
 
 		imagePath1 := "test_path1"
 		imagePath2 := "test_path2"
-		h.AppendImage(id, imagePath1)
-		h.AppendImage(id, imagePath2)
+		h.AppendImage(context.Background(), id, imagePath1)
+		h.AppendImage(context.Background(), id, imagePath2)
 
-		createdArticle, err := h.FindOneById(id)
+		createdArticle, err := h.FindOneById(context.Background(), id)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.AppendImage() error = %v, wantErr %v", err, false)
 			return
@@ -198,6 +264,7 @@ func TestArticleDbHandler_AppendImage(t *testing.T) {
 
 		expected.ImageFilePaths = []string{imagePath1, imagePath2}
 		expected.Id = createdArticle.Id
+		expected.TenantId = tenant.Default
 
 		if !reflect.DeepEqual(*createdArticle, expected) {
 			t.Errorf("ArticleDbHandler.AppendImage() = %v, want %v", *createdArticle, expected)
@@ -217,7 +284,7 @@ func TestArticleDbHandler_AppendImage(t *testing.T) {
 			ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), // have to truncate, because mongo does not store microseconds
 			Description:    "Test_Description",
 		}
-		id, err := h.InsertOne(expected)
+		id, err := h.InsertOne(context.Background(), expected)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
 			return
@@ -225,10 +292,10 @@ func TestArticleDbHandler_AppendImage(t *testing.T) {
 
 		imagePath1 := "test_path"
 		imagePath2 := "test_path"
-		h.AppendImage(id, imagePath1)
-		h.AppendImage(id, imagePath2)
+		h.AppendImage(context.Background(), id, imagePath1)
+		h.AppendImage(context.Background(), id, imagePath2)
 
-		createdArticle, err := h.FindOneById(id)
+		createdArticle, err := h.FindOneById(context.Background(), id)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
 			return
@@ -236,12 +303,31 @@ func TestArticleDbHandler_AppendImage(t *testing.T) {
 
 		expected.ImageFilePaths = []string{imagePath1}
 		expected.Id = createdArticle.Id
+		expected.TenantId = tenant.Default
 
 		if !reflect.DeepEqual(*createdArticle, expected) {
 			t.Errorf("ArticleDbHandler.FindOneById() = %v, want %v", *createdArticle, expected)
 			return
 		}
 	})
+
+	t.Run("Prevent appending more than the maximum amount of images", func(t *testing.T) {
+		t.Parallel()
+
+		h, close := createColl(t)
+		defer close()
+
+		id, err := h.InsertOne(context.Background(), ArticleDb{Title: "Test_Title", ImageFilePaths: []string{"path1", "path2", "path3"}})
+		if err != nil {
+			t.Errorf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+			return
+		}
+
+		found, err := h.AppendImage(context.Background(), id, "path4")
+		if err != nil || found {
+			t.Errorf("ArticleDbHandler.AppendImage() = %v, %v, want %v, %v", found, err, false, nil)
+		}
+	})
 }
 
 func TestArticleDbHandler_FindOneById(t *testing.T) {
@@ -258,19 +344,20 @@ func TestArticleDbHandler_FindOneById(t *testing.T) {
 			ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), // have to truncate, because mongo does not store microseconds
 			Description:    "Test_Description",
 		}
-		id, err := h.InsertOne(article)
+		id, err := h.InsertOne(context.Background(), article)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.FindOneById() error = %v, wantErr %v", err, false)
 			return
 		}
 
-		createdArticle, err := h.FindOneById(id)
+		createdArticle, err := h.FindOneById(context.Background(), id)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.FindOneById() error = %v, wantErr %v", err, false)
 			return
 		}
 
 		article.Id = createdArticle.Id
+		article.TenantId = tenant.Default
 		article.ImageFilePaths = createdArticle.ImageFilePaths
 		if !reflect.DeepEqual(*createdArticle, article) {
 			t.Errorf("ArticleDbHandler.FindOneById() = %v, want %v", *createdArticle, article)
@@ -284,7 +371,7 @@ func TestArticleDbHandler_FindOneById(t *testing.T) {
 		h, close := createColl(t)
 		defer close()
 
-		found, err := h.FindOneById(primitive.NewObjectID())
+		found, err := h.FindOneById(context.Background(), primitive.NewObjectID())
 		if err != nil {
 			t.Errorf("ArticleDbHandler.FindOneById() error = %v, wantErr %v", err, false)
 			return
@@ -314,14 +401,14 @@ func TestArticleDbHandler_FindAllTitles(t *testing.T) {
 				ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), // have to truncate, because mongo does not store microseconds
 				Description:    "Test_Description",
 			}
-			_, err := h.InsertOne(article)
+			_, err := h.InsertOne(context.Background(), article)
 			if err != nil {
 				t.Errorf("ArticleDbHandler.FindAllTitles() error = %v, wantErr %v", err, false)
 				return
 			}
 		}
 
-		found, err := h.FindAllTitles()
+		found, err := h.FindAllTitles(context.Background())
 		if err != nil {
 			t.Errorf("ArticleDbHandler.FindAllTitles() error = %v, wantErr %v", err, false)
 			return
@@ -346,7 +433,7 @@ func TestArticleDbHandler_FindTitlesByHasImage(t *testing.T) {
 				Description:    "Test_Description",
 				ImageFilePaths: []string{"image_path"},
 			}
-			_, err := h.InsertOne(article)
+			_, err := h.InsertOne(context.Background(), article)
 			if err != nil {
 				t.Errorf("ArticleDbHandler.FindTitlesByHasImage() error = %v, wantErr %v", err, false)
 				return
@@ -360,7 +447,7 @@ func TestArticleDbHandler_FindTitlesByHasImage(t *testing.T) {
 				ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), // have to truncate, because mongo does not store microseconds
 				Description:    "Test_Description",
 			}
-			_, err := h.InsertOne(article)
+			_, err := h.InsertOne(context.Background(), article)
 			if err != nil {
 				t.Errorf("ArticleDbHandler.FindTitlesByHasImage() error = %v, wantErr %v", err, false)
 				return
@@ -378,7 +465,7 @@ func TestArticleDbHandler_FindTitlesByHasImage(t *testing.T) {
 
 		titlesWithImages, _ := init(h)
 
-		found, err := h.FindTitlesByHasImage(true)
+		found, err := h.FindTitlesByHasImage(context.Background(), true)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.FindTitlesByHasImage() error = %v, wantErr %v", err, false)
 			return
@@ -398,7 +485,7 @@ func TestArticleDbHandler_FindTitlesByHasImage(t *testing.T) {
 
 		_, titlesWithoutImages := init(h)
 
-		found, err := h.FindTitlesByHasImage(false)
+		found, err := h.FindTitlesByHasImage(context.Background(), false)
 		if err != nil {
 			t.Errorf("ArticleDbHandler.FindTitlesByHasImage() error = %v, wantErr %v", err, false)
 			return
@@ -410,3 +497,407 @@ func TestArticleDbHandler_FindTitlesByHasImage(t *testing.T) {
 		}
 	})
 }
+
+func TestArticleDbHandler_TenantIsolation(t *testing.T) {
+	t.Parallel()
+
+	h, close := createColl(t)
+	defer close()
+
+	teamA := tenant.WithTenant(context.Background(), "team-a")
+	teamB := tenant.WithTenant(context.Background(), "team-b")
+
+	id, err := h.InsertOne(teamA, ArticleDb{
+		Title:          "Team_A_Title",
+		ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond), // have to truncate, because mongo does not store microseconds
+		Description:    "Test_Description",
+	})
+	if err != nil {
+		t.Fatalf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+	}
+
+	t.Run("Successfully hide the article from other tenants", func(t *testing.T) {
+		found, err := h.FindOneById(teamB, id)
+		if err != nil || found != nil {
+			t.Errorf("ArticleDbHandler.FindOneById() = %v, %v, want %v", found, err, nil)
+		}
+
+		titles, err := h.FindAllTitles(teamB)
+		if err != nil || len(titles) != 0 {
+			t.Errorf("ArticleDbHandler.FindAllTitles() = %v, %v, want none", titles, err)
+		}
+	})
+
+	t.Run("Prevent other tenants from appending images", func(t *testing.T) {
+		h.AppendImage(teamB, id, "team_b_path")
+
+		found, err := h.FindOneById(teamA, id)
+		if err != nil || found == nil || len(found.ImageFilePaths) != 0 {
+			t.Errorf("ArticleDbHandler.AppendImage() = %v, %v, want no images", found, err)
+		}
+	})
+
+	t.Run("Successfully count per tenant", func(t *testing.T) {
+		h.AppendImage(teamA, id, "team_a_path")
+
+		articles, err := h.CountArticles(teamA)
+		if err != nil || articles != 1 {
+			t.Errorf("ArticleDbHandler.CountArticles() = %v, %v, want %v", articles, err, 1)
+		}
+
+		images, err := h.CountImages(teamA)
+		if err != nil || images != 1 {
+			t.Errorf("ArticleDbHandler.CountImages() = %v, %v, want %v", images, err, 1)
+		}
+
+		articles, err = h.CountArticles(teamB)
+		if err != nil || articles != 0 {
+			t.Errorf("ArticleDbHandler.CountArticles() = %v, %v, want %v", articles, err, 0)
+		}
+	})
+}
+
+func TestArticleDbHandler_SoftDelete(t *testing.T) {
+	t.Parallel()
+
+	h, close := createColl(t)
+	defer close()
+
+	ctx := context.Background()
+	id, err := h.InsertOne(ctx, ArticleDb{
+		Title:          "Test_Title",
+		ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond),
+		Description:    "Test_Description",
+		ImageFilePaths: []string{"images/default/test"},
+	})
+	if err != nil {
+		t.Fatalf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+	}
+
+	if found, err := h.SoftDelete(ctx, id); err != nil || !found {
+		t.Fatalf("ArticleDbHandler.SoftDelete() = %v, %v, want %v", found, err, true)
+	}
+
+	t.Run("Successfully hide deleted articles", func(t *testing.T) {
+		if found, err := h.FindOneById(ctx, id); err != nil || found != nil {
+			t.Errorf("ArticleDbHandler.FindOneById() = %v, %v, want %v", found, err, nil)
+		}
+		if titles, err := h.FindAllTitles(ctx); err != nil || len(titles) != 0 {
+			t.Errorf("ArticleDbHandler.FindAllTitles() = %v, %v, want none", titles, err)
+		}
+		if titles, err := h.FindTitlesByHasImage(ctx, true); err != nil || len(titles) != 0 {
+			t.Errorf("ArticleDbHandler.FindTitlesByHasImage() = %v, %v, want none", titles, err)
+		}
+	})
+
+	t.Run("Successfully list the trash", func(t *testing.T) {
+		trash, err := h.FindTrash(ctx)
+		if err != nil || len(trash) != 1 || trash[0].DeletedAt == nil {
+			t.Errorf("ArticleDbHandler.FindTrash() = %v, %v, want the deleted article", trash, err)
+		}
+	})
+
+	t.Run("Prevent deleting twice", func(t *testing.T) {
+		if found, err := h.SoftDelete(ctx, id); err != nil || found {
+			t.Errorf("ArticleDbHandler.SoftDelete() = %v, %v, want %v", found, err, false)
+		}
+	})
+
+	t.Run("Successfully keep the trash within the retention", func(t *testing.T) {
+		purged, err := h.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+		if err != nil || len(purged) != 0 {
+			t.Errorf("ArticleDbHandler.PurgeTrash() = %v, %v, want none", purged, err)
+		}
+	})
+
+	t.Run("Successfully restore", func(t *testing.T) {
+		if found, err := h.Restore(ctx, id); err != nil || !found {
+			t.Fatalf("ArticleDbHandler.Restore() = %v, %v, want %v", found, err, true)
+		}
+		if found, err := h.FindOneById(ctx, id); err != nil || found == nil {
+			t.Errorf("ArticleDbHandler.FindOneById() = %v, %v, want the restored article", found, err)
+		}
+	})
+
+	t.Run("Successfully purge the trash", func(t *testing.T) {
+		if _, err := h.SoftDelete(ctx, id); err != nil {
+			t.Fatalf("ArticleDbHandler.SoftDelete() error = %v, wantErr %v", err, false)
+		}
+
+		purged, err := h.PurgeTrash(ctx, time.Now().Add(time.Second))
+		if err != nil || len(purged) != 1 || len(purged[0].ImageFilePaths) != 1 {
+			t.Fatalf("ArticleDbHandler.PurgeTrash() = %v, %v, want the deleted article", purged, err)
+		}
+		if found, err := h.FindOneInTrashById(ctx, id); err != nil || found != nil {
+			t.Errorf("ArticleDbHandler.FindOneInTrashById() = %v, %v, want %v", found, err, nil)
+		}
+	})
+}
+
+func TestArticleDbHandler_ExpiryPolicy(t *testing.T) {
+	t.Parallel()
+
+	database, close := createDb(t)
+	defer close()
+
+	h := ArticleDbHandler{}
+	if err := h.New(database); err != nil {
+		t.Fatalf("ArticleDbHandler.New() error = %v, wantErr %v", err, false)
+	}
+
+	ctx := context.Background()
+	expired := time.Now().Add(-time.Minute).UTC().Truncate(time.Millisecond)
+	ids := make(map[string]primitive.ObjectID)
+	for _, policy := range []string{ExpiryPolicyArchive, ExpiryPolicyHide} {
+		id, err := h.InsertOne(ctx, ArticleDb{Title: policy, ExpirationDate: expired, Description: "Test_Description", ExpiryPolicy: policy})
+		if err != nil {
+			t.Fatalf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+		}
+		ids[policy] = id
+	}
+
+	t.Run("Successfully hide expired articles from listings", func(t *testing.T) {
+		titles, err := h.FindAllTitles(ctx)
+		if err != nil || len(titles) != 0 {
+			t.Errorf("ArticleDbHandler.FindAllTitles() = %v, %v, want none", titles, err)
+		}
+		if found, err := h.FindOneById(ctx, ids[ExpiryPolicyHide]); err != nil || found == nil {
+			t.Errorf("ArticleDbHandler.FindOneById() = %v, %v, want the hidden article", found, err)
+		}
+	})
+
+	t.Run("Successfully archive expired articles", func(t *testing.T) {
+		archived, err := h.ArchiveExpired(ctx, time.Now())
+		if err != nil || len(archived) != 1 || archived[0].Id != ids[ExpiryPolicyArchive] {
+			t.Fatalf("ArticleDbHandler.ArchiveExpired() = %v, %v, want the archived article", archived, err)
+		}
+		if found, err := h.FindOneById(ctx, ids[ExpiryPolicyArchive]); err != nil || found != nil {
+			t.Errorf("ArticleDbHandler.FindOneById() = %v, %v, want %v", found, err, nil)
+		}
+		if count, err := database.Collection("articles_archive").CountDocuments(ctx, bson.M{"_id": ids[ExpiryPolicyArchive]}); err != nil || count != 1 {
+			t.Errorf("articles_archive count = %v, %v, want %v", count, err, 1)
+		}
+	})
+
+	t.Run("Successfully mark expired articles hidden once", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		if hidden, err := h.HideExpired(ctx, now); err != nil || hidden != 1 {
+			t.Fatalf("ArticleDbHandler.HideExpired() = %v, %v, want %v", hidden, err, 1)
+		}
+		if found, err := h.FindOneById(ctx, ids[ExpiryPolicyHide]); err != nil || found == nil || found.HiddenAt == nil || !found.HiddenAt.Equal(now) {
+			t.Errorf("ArticleDbHandler.FindOneById() = %+v, %v, want hiddenAt %v", found, err, now)
+		}
+		if hidden, err := h.HideExpired(ctx, now); err != nil || hidden != 0 {
+			t.Errorf("ArticleDbHandler.HideExpired() = %v, %v, want %v", hidden, err, 0)
+		}
+	})
+
+	t.Run("Successfully unmark hidden articles that are renewed", func(t *testing.T) {
+		if found, err := h.Renew(ctx, ids[ExpiryPolicyHide], time.Now().Add(time.Hour), 0); err != nil || !found {
+			t.Fatalf("ArticleDbHandler.Renew() = %v, %v, want %v", found, err, true)
+		}
+		if found, err := h.FindOneById(ctx, ids[ExpiryPolicyHide]); err != nil || found == nil || found.HiddenAt != nil {
+			t.Errorf("ArticleDbHandler.FindOneById() = %+v, %v, want no hiddenAt", found, err)
+		}
+	})
+
+	t.Run("Successfully backfill the expiry policy", func(t *testing.T) {
+		if _, err := database.Collection("articles").InsertOne(ctx, bson.M{"title": "Legacy", "expirationDate": time.Now().Add(time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+		backfilled, err := h.BackfillExpiryPolicy(ctx, ExpiryPolicyHide)
+		if err != nil || backfilled != 1 {
+			t.Errorf("ArticleDbHandler.BackfillExpiryPolicy() = %v, %v, want %v", backfilled, err, 1)
+		}
+	})
+
+	t.Run("Successfully recreate the indexes", func(t *testing.T) {
+		if err := h.New(database); err != nil {
+			t.Errorf("ArticleDbHandler.New() error = %v, wantErr %v", err, false)
+		}
+	})
+}
+
+func TestArticleDbHandler_Renew(t *testing.T) {
+	t.Parallel()
+
+	h, close := createColl(t)
+	defer close()
+
+	ctx := context.Background()
+	id, err := h.InsertOne(ctx, ArticleDb{
+		Title:          "Test_Title",
+		ExpirationDate: time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond),
+		Description:    "Test_Description",
+	})
+	if err != nil {
+		t.Fatalf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+	}
+
+	renewed := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if found, err := h.Renew(ctx, id, renewed, 2); err != nil || !found {
+			t.Fatalf("ArticleDbHandler.Renew() = %v, %v, want %v", found, err, true)
+		}
+	}
+
+	t.Run("Successfully count the renewals", func(t *testing.T) {
+		found, err := h.FindOneById(ctx, id)
+		if err != nil || found == nil || found.Renewals != 2 || !found.ExpirationDate.Equal(renewed) {
+			t.Errorf("ArticleDbHandler.FindOneById() = %v, %v, want 2 renewals", found, err)
+		}
+	})
+
+	t.Run("Prevent renewing more than the maximum", func(t *testing.T) {
+		if found, err := h.Renew(ctx, id, renewed, 2); err != nil || found {
+			t.Errorf("ArticleDbHandler.Renew() = %v, %v, want %v", found, err, false)
+		}
+	})
+}
+
+func TestArticleDbHandler_InsertMany(t *testing.T) {
+	t.Parallel()
+
+	h, close := createColl(t)
+	defer close()
+
+	teamA := tenant.WithTenant(context.Background(), "team-a")
+	expirationDate := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+
+	ids, err := h.InsertMany(teamA, []ArticleDb{
+		{Title: "First", ExpirationDate: expirationDate, Description: "Test_Description"},
+		{Title: "Second", ExpirationDate: expirationDate, Description: "Test_Description"},
+	})
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("ArticleDbHandler.InsertMany() = %v, %v, want %v ids", ids, err, 2)
+	}
+
+	for i, title := range []string{"First", "Second"} {
+		found, err := h.FindOneById(teamA, ids[i])
+		if err != nil || found == nil || found.Title != title || found.TenantId != "team-a" {
+			t.Errorf("ArticleDbHandler.FindOneById() = %v, %v, want %v", found, err, title)
+		}
+	}
+}
+
+func TestArticleDbHandler_Export(t *testing.T) {
+	t.Parallel()
+
+	h, close := createColl(t)
+	defer close()
+
+	ctx := context.Background()
+	expirationDate := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	ids, err := h.InsertMany(ctx, []ArticleDb{
+		{Title: "With_Image", ExpirationDate: expirationDate, Description: "Test_Description", ImageFilePaths: []string{"images/default/1"}},
+		{Title: "Without_Image", ExpirationDate: expirationDate.Add(time.Hour), Description: "Test_Description"},
+		{Title: "Deleted", ExpirationDate: expirationDate, Description: "Test_Description"},
+	})
+	if err != nil {
+		t.Fatalf("ArticleDbHandler.InsertMany() error = %v, wantErr %v", err, false)
+	}
+	if _, err := h.SoftDelete(ctx, ids[2]); err != nil {
+		t.Fatalf("ArticleDbHandler.SoftDelete() error = %v, wantErr %v", err, false)
+	}
+
+	withImage := true
+	tests := []struct {
+		name     string
+		filter   ExportFilter
+		expected []string
+	}{
+		{name: "Successfully export all but the trash", filter: ExportFilter{}, expected: []string{"With_Image", "Without_Image"}},
+		{name: "Successfully filter by image", filter: ExportFilter{WithImage: &withImage}, expected: []string{"With_Image"}},
+		{name: "Successfully filter by expiration", filter: ExportFilter{ExpiresFrom: expirationDate.Add(time.Minute)}, expected: []string{"Without_Image"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var titles []string
+			err := h.Export(ctx, tt.filter, func(article ArticleDb) error {
+				titles = append(titles, article.Title)
+				return nil
+			})
+			if err != nil || !reflect.DeepEqual(titles, tt.expected) {
+				t.Errorf("ArticleDbHandler.Export() = %v, %v, want %v", titles, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArticleDbHandler_FindPage(t *testing.T) {
+	t.Parallel()
+
+	h, close := createColl(t)
+	defer close()
+
+	ctx := context.Background()
+	expirationDate := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	ids, err := h.InsertMany(ctx, []ArticleDb{
+		{Title: "First", ExpirationDate: expirationDate, Description: "Test_Description", ImageFilePaths: []string{"images/default/1"}},
+		{Title: "Second", ExpirationDate: expirationDate, Description: "Test_Description"},
+		{Title: "Third", ExpirationDate: expirationDate, Description: "Test_Description", ImageFilePaths: []string{"images/default/2"}},
+	})
+	if err != nil {
+		t.Fatalf("ArticleDbHandler.InsertMany() error = %v, wantErr %v", err, false)
+	}
+
+	withImage := true
+	tests := []struct {
+		name     string
+		filter   ExportFilter
+		after    primitive.ObjectID
+		limit    int64
+		expected []string
+	}{
+		{name: "Successfully find the first page", limit: 2, expected: []string{"First", "Second"}},
+		{name: "Successfully find the page after an id", after: ids[1], limit: 2, expected: []string{"Third"}},
+		{name: "Successfully filter the page", filter: ExportFilter{WithImage: &withImage}, after: ids[0], limit: 2, expected: []string{"Third"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles, err := h.FindPage(ctx, tt.filter, tt.after, tt.limit)
+			titles := make([]string, 0)
+			for _, article := range articles {
+				titles = append(titles, article.Title)
+			}
+			if err != nil || !reflect.DeepEqual(titles, tt.expected) {
+				t.Errorf("ArticleDbHandler.FindPage() = %v, %v, want %v", titles, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArticleDbHandler_FindAllImagePaths(t *testing.T) {
+	t.Parallel()
+
+	database, close := createDb(t)
+	defer close()
+
+	h := ArticleDbHandler{}
+	if err := h.New(database); err != nil {
+		t.Fatalf("ArticleDbHandler.New() error = %v, wantErr %v", err, false)
+	}
+
+	ctx := context.Background()
+	expirationDate := time.Now().Add(time.Hour)
+	if _, err := h.InsertOne(ctx, ArticleDb{Title: "Default", ExpirationDate: expirationDate, Description: "Test_Description", ImageFilePaths: []string{"images/default/1"}}); err != nil {
+		t.Fatalf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+	}
+	deleted, err := h.InsertOne(tenant.WithTenant(ctx, "team-a"), ArticleDb{Title: "Deleted", ExpirationDate: expirationDate, Description: "Test_Description", ImageFilePaths: []string{"images/team-a/2"}})
+	if err != nil {
+		t.Fatalf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+	}
+	if _, err := h.SoftDelete(tenant.WithTenant(ctx, "team-a"), deleted); err != nil {
+		t.Fatalf("ArticleDbHandler.SoftDelete() error = %v, wantErr %v", err, false)
+	}
+	if _, err := database.Collection("articles_archive").InsertOne(ctx, bson.M{"title": "Archived", "imagePaths": bson.A{"images/default/3"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := h.FindAllImagePaths(ctx)
+	sort.Strings(paths)
+	expected := []string{"images/default/1", "images/default/3", "images/team-a/2"}
+	if err != nil || !reflect.DeepEqual(paths, expected) {
+		t.Errorf("ArticleDbHandler.FindAllImagePaths() = %v, %v, want %v", paths, err, expected)
+	}
+}
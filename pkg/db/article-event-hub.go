@@ -0,0 +1,228 @@
+package db
+
+import (
+	"article-management-service/pkg/tenant"
+	"context"
+	"errors"
+	"sync"
+)
+
+const (
+	// how many recent events the hub keeps, so reconnecting subscribers resume without a change stream of their
+	// own; also how many events are queued per subscriber before it is dropped
+	articleEventBacklog     = 256
+	defaultMaxResumeStreams = 16
+)
+
+// ErrTooManyStreams is returned by ArticleEventHub.Watch when a subscriber resumes from an event the hub no longer
+// keeps and every change stream for resuming subscribers is taken
+var ErrTooManyStreams = errors.New("too many subscribers resume from older article events")
+
+// ArticleEventWatcher streams the article events of the tenant of the context, like ArticleDbHandler.Watch
+type ArticleEventWatcher interface {
+	Watch(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error)
+}
+
+// ArticleEventHub fans one change stream of all tenants out to the subscribers of every tenant. The stream runs
+// while there are subscribers. Subscribers resume from the recent events of the hub; resuming from older events
+// takes a change stream of its own, of which there are at most MaxResumeStreams.
+type ArticleEventHub struct {
+	ArticleDbHandler ArticleDbHandlerInterface
+	MaxResumeStreams int // defaults to 16
+
+	mu            sync.Mutex
+	subscribers   map[chan ArticleEvent]string // the tenant of every subscriber
+	backlog       []ArticleEvent
+	stop          context.CancelFunc // nil while the stream does not run
+	resumeStreams int
+}
+
+// Watch subscribes to the article events of the tenant of the context, starting after the event with the given id
+// or, when empty, now. The channel is closed when the context is done, the change stream fails or the subscriber
+// falls too far behind.
+func (h *ArticleEventHub) Watch(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error) {
+	tenantId := tenant.FromContext(ctx)
+
+	h.mu.Lock()
+	if h.stop == nil {
+		if err := h.start(); err != nil {
+			h.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	if missed, ok := h.since(resumeAfter, tenantId); ok {
+		queue := make(chan ArticleEvent, articleEventBacklog)
+		for _, event := range missed {
+			queue <- event
+		}
+		if h.subscribers == nil {
+			h.subscribers = make(map[chan ArticleEvent]string)
+		}
+		h.subscribers[queue] = tenantId
+		h.mu.Unlock()
+
+		go func() {
+			<-ctx.Done()
+			h.unsubscribe(queue)
+		}()
+		return queue, nil
+	}
+
+	if len(h.subscribers) == 0 {
+		h.halt()
+	}
+	maxStreams := h.MaxResumeStreams
+	if maxStreams <= 0 {
+		maxStreams = defaultMaxResumeStreams
+	}
+	if h.resumeStreams >= maxStreams {
+		h.mu.Unlock()
+		return nil, ErrTooManyStreams
+	}
+	h.resumeStreams++
+	h.mu.Unlock()
+
+	return h.resume(ctx, resumeAfter)
+}
+
+// Starts the change stream after the newest event of the backlog, so the backlog has no gaps; starts from now with
+// an empty backlog when the stream can not be resumed. Called with the lock held.
+func (h *ArticleEventHub) start() error {
+	ctx, stop := context.WithCancel(context.Background())
+
+	resumeAfter := ""
+	if len(h.backlog) > 0 {
+		resumeAfter = h.backlog[len(h.backlog)-1].Id
+	}
+	events, err := h.ArticleDbHandler.WatchAllTenants(ctx, resumeAfter)
+	if errors.Is(err, ErrResumeFailed) {
+		h.backlog = nil
+		events, err = h.ArticleDbHandler.WatchAllTenants(ctx, "")
+	}
+	if err != nil {
+		stop()
+		return err
+	}
+
+	h.stop = stop
+	go h.broadcast(ctx, events)
+	return nil
+}
+
+// Stops the change stream and forgets the backlog, which would have gaps once the stream starts again. Called with
+// the lock held.
+func (h *ArticleEventHub) halt() {
+	if h.stop != nil {
+		h.stop()
+		h.stop = nil
+	}
+	h.backlog = nil
+}
+
+// The events of the tenant after the event with the given id; false when the backlog does not have the event
+func (h *ArticleEventHub) since(resumeAfter string, tenantId string) ([]ArticleEvent, bool) {
+	if resumeAfter == "" {
+		return nil, true
+	}
+	for i := len(h.backlog) - 1; i >= 0; i-- {
+		if h.backlog[i].Id != resumeAfter {
+			continue
+		}
+		var missed []ArticleEvent
+		for _, event := range h.backlog[i+1:] {
+			if event.TenantId == tenantId {
+				missed = append(missed, event)
+			}
+		}
+		return missed, true
+	}
+	return nil, false
+}
+
+func (h *ArticleEventHub) broadcast(ctx context.Context, events <-chan ArticleEvent) {
+	for event := range events {
+		h.mu.Lock()
+		// halted; the events of the next stream are broadcast by its own goroutine
+		if ctx.Err() != nil {
+			h.mu.Unlock()
+			return
+		}
+		if len(h.backlog) == articleEventBacklog {
+			copy(h.backlog, h.backlog[1:])
+			h.backlog = h.backlog[:len(h.backlog)-1]
+		}
+		h.backlog = append(h.backlog, event)
+
+		for queue, tenantId := range h.subscribers {
+			if tenantId != event.TenantId {
+				continue
+			}
+			select {
+			case queue <- event:
+			default:
+				// the subscriber resumes from the backlog or a stream of its own after reconnecting
+				delete(h.subscribers, queue)
+				close(queue)
+			}
+		}
+		h.mu.Unlock()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// halted, maybe started again since
+	if ctx.Err() != nil {
+		return
+	}
+	// the stream failed; the subscribers reconnect and resume from the backlog, which the next stream continues
+	h.stop()
+	h.stop = nil
+	for queue := range h.subscribers {
+		delete(h.subscribers, queue)
+		close(queue)
+	}
+}
+
+func (h *ArticleEventHub) unsubscribe(queue chan ArticleEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[queue]; !ok {
+		return
+	}
+	delete(h.subscribers, queue)
+	close(queue)
+	if len(h.subscribers) == 0 {
+		h.halt()
+	}
+}
+
+// Streams the events of a change stream of the subscriber's own and frees it for the next when it ends
+func (h *ArticleEventHub) resume(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error) {
+	release := func() {
+		h.mu.Lock()
+		h.resumeStreams--
+		h.mu.Unlock()
+	}
+
+	events, err := h.ArticleDbHandler.Watch(ctx, resumeAfter)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	forwarded := make(chan ArticleEvent)
+	go func() {
+		defer release()
+		defer close(forwarded)
+		for event := range events {
+			select {
+			case forwarded <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return forwarded, nil
+}
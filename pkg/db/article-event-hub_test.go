@@ -0,0 +1,145 @@
+package db
+
+import (
+	"article-management-service/pkg/tenant"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeChangeStreams serves the change stream of all tenants from a channel and counts the streams it opened
+type fakeChangeStreams struct {
+	ArticleDbHandlerInterface
+	events     chan ArticleEvent
+	shared     int
+	own        int
+	resumedAll []string
+}
+
+func (f *fakeChangeStreams) WatchAllTenants(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error) {
+	f.shared++
+	f.resumedAll = append(f.resumedAll, resumeAfter)
+	return f.events, nil
+}
+
+func (f *fakeChangeStreams) Watch(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error) {
+	f.own++
+	events := make(chan ArticleEvent)
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	return events, nil
+}
+
+func receive(t *testing.T, events <-chan ArticleEvent) ArticleEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return ArticleEvent{}
+	}
+}
+
+func TestArticleEventHub_Watch(t *testing.T) {
+	teamA := tenant.WithTenant(context.Background(), "teamA")
+	teamB := tenant.WithTenant(context.Background(), "teamB")
+
+	t.Run("Successfully fan one stream out to the subscribers of their tenant", func(t *testing.T) {
+		streams := &fakeChangeStreams{events: make(chan ArticleEvent)}
+		h := &ArticleEventHub{ArticleDbHandler: streams}
+
+		first, err := h.Watch(teamA, "")
+		if err != nil {
+			t.Fatalf("ArticleEventHub.Watch() error = %v", err)
+		}
+		second, _ := h.Watch(teamA, "")
+		other, _ := h.Watch(teamB, "")
+
+		streams.events <- ArticleEvent{Id: "1", TenantId: "teamA", Type: ArticleEventCreated}
+		if event := receive(t, first); event.Id != "1" {
+			t.Errorf("first subscriber received %+v, want event 1", event)
+		}
+		if event := receive(t, second); event.Id != "1" {
+			t.Errorf("second subscriber received %+v, want event 1", event)
+		}
+		if len(other) != 0 || streams.shared != 1 || streams.own != 0 {
+			t.Errorf("other tenant queued %d events; %d shared and %d own streams, want 0, 1 and 0", len(other), streams.shared, streams.own)
+		}
+	})
+
+	t.Run("Successfully resume from the backlog", func(t *testing.T) {
+		streams := &fakeChangeStreams{events: make(chan ArticleEvent)}
+		h := &ArticleEventHub{ArticleDbHandler: streams}
+
+		live, _ := h.Watch(teamA, "")
+		for i := 1; i <= 3; i++ {
+			streams.events <- ArticleEvent{Id: fmt.Sprint(i), TenantId: "teamA"}
+		}
+		for i := 1; i <= 3; i++ {
+			receive(t, live)
+		}
+
+		resumed, err := h.Watch(teamA, "1")
+		if err != nil {
+			t.Fatalf("ArticleEventHub.Watch() error = %v", err)
+		}
+		if first, second := receive(t, resumed), receive(t, resumed); first.Id != "2" || second.Id != "3" {
+			t.Errorf("resumed subscriber received %v and %v, want 2 and 3", first.Id, second.Id)
+		}
+		if streams.own != 0 {
+			t.Errorf("%d own streams, want 0", streams.own)
+		}
+	})
+
+	t.Run("Successfully continue the backlog after the stream failed", func(t *testing.T) {
+		streams := &fakeChangeStreams{events: make(chan ArticleEvent)}
+		h := &ArticleEventHub{ArticleDbHandler: streams}
+
+		live, _ := h.Watch(teamA, "")
+		streams.events <- ArticleEvent{Id: "1", TenantId: "teamA"}
+		receive(t, live)
+
+		failed := streams.events
+		streams.events = make(chan ArticleEvent)
+		close(failed)
+		if _, ok := <-live; ok {
+			t.Fatal("the subscription is open after the stream failed")
+		}
+
+		if _, err := h.Watch(teamA, "1"); err != nil || streams.own != 0 || streams.resumedAll[len(streams.resumedAll)-1] != "1" {
+			t.Errorf("ArticleEventHub.Watch() error = %v; %d own streams, shared resumed after %v, want 0 and 1", err, streams.own, streams.resumedAll)
+		}
+	})
+
+	t.Run("Prevent more own streams than the maximum", func(t *testing.T) {
+		streams := &fakeChangeStreams{events: make(chan ArticleEvent)}
+		h := &ArticleEventHub{ArticleDbHandler: streams, MaxResumeStreams: 1}
+
+		ctx, cancel := context.WithCancel(teamA)
+		if _, err := h.Watch(ctx, "old"); err != nil || streams.own != 1 {
+			t.Fatalf("ArticleEventHub.Watch() error = %v; %d own streams, want 1", err, streams.own)
+		}
+		if _, err := h.Watch(teamA, "old"); err != ErrTooManyStreams {
+			t.Errorf("ArticleEventHub.Watch() error = %v, want %v", err, ErrTooManyStreams)
+		}
+
+		cancel()
+		deadline := time.Now().Add(time.Second)
+		for {
+			h.mu.Lock()
+			released := h.resumeStreams == 0
+			h.mu.Unlock()
+			if released || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if _, err := h.Watch(teamA, "old"); err != nil {
+			t.Errorf("ArticleEventHub.Watch() error = %v after the own stream ended", err)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"article-management-service/pkg/tenant"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	ArticleEventCreated       = "created"
+	ArticleEventUpdated       = "updated"
+	ArticleEventImageAttached = "image-attached"
+	ArticleEventDeleted       = "deleted" // moved to the trash
+	ArticleEventRestored      = "restored"
+	ArticleEventExpired       = "expired" // removed by the TTL index, archived or hidden
+)
+
+// ErrResumeFailed is returned by Watch when the change stream can not be resumed from the given event,
+// e.g. because the oplog no longer holds it
+var ErrResumeFailed = errors.New("can not resume the article events")
+
+// ArticleEvent is a change of an article; the id is the resume token of the change stream
+type ArticleEvent struct {
+	Id        string             `json:"-"`
+	TenantId  string             `json:"-"`
+	Type      string             `json:"type"`
+	ArticleId primitive.ObjectID `json:"articleId"`
+	Title     string             `json:"title,omitempty"`
+	Time      time.Time          `json:"time"`
+}
+
+type articleChange struct {
+	Id struct {
+		Data string `bson:"_data"`
+	} `bson:"_id"`
+	OperationType string              `bson:"operationType"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	DocumentKey   struct {
+		Id primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument             *ArticleDb `bson:"fullDocument"`
+	FullDocumentBeforeChange *ArticleDb `bson:"fullDocumentBeforeChange"`
+	UpdateDescription        struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+// Records the articles before they are deleted, so deletions can be assigned to a tenant.
+// Change streams require a replica set.
+func (h *ArticleDbHandler) EnableChangeEvents(ctx context.Context) error {
+	command := bson.D{{Key: "collMod", Value: h.coll.Name()}, {Key: "changeStreamPreAndPostImages", Value: bson.M{"enabled": true}}}
+	return h.coll.Database().RunCommand(ctx, command).Err()
+}
+
+// Watch streams the changes of the articles of the tenant of the context, starting after the event with the
+// given id or, when empty, now. The channel is closed when the context is done or the change stream fails.
+func (h *ArticleDbHandler) Watch(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error) {
+	return h.watch(ctx, resumeAfter, tenant.FromContext(ctx))
+}
+
+// WatchAllTenants streams the changes of the articles of every tenant, like Watch
+func (h *ArticleDbHandler) WatchAllTenants(ctx context.Context, resumeAfter string) (<-chan ArticleEvent, error) {
+	return h.watch(ctx, resumeAfter, "")
+}
+
+// An empty tenant watches every tenant
+func (h *ArticleDbHandler) watch(ctx context.Context, resumeAfter string, tenantId string) (<-chan ArticleEvent, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}}}}},
+	}
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+	if resumeAfter != "" {
+		opts.SetResumeAfter(bson.M{"_data": resumeAfter})
+	}
+
+	stream, err := h.coll.Watch(ctx, pipeline, opts)
+	if err != nil {
+		var commandErr mongo.CommandError
+		if resumeAfter != "" && errors.As(err, &commandErr) {
+			return nil, errors.Join(ErrResumeFailed, err)
+		}
+		h.logError(ctx, "Watch", err)
+		return nil, err
+	}
+
+	events := make(chan ArticleEvent)
+	go func() {
+		defer close(events)
+		defer stream.Close(context.Background())
+
+		for stream.Next(ctx) {
+			var change articleChange
+			if err := stream.Decode(&change); err != nil {
+				h.logError(ctx, "Watch", err)
+				return
+			}
+
+			event, ok := articleEventOf(change, tenantId)
+			if !ok {
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			h.logError(ctx, "Watch", err)
+		}
+	}()
+	return events, nil
+}
+
+// Converts a change to an event; false when it is not of the tenant or not worth an event. An empty tenant
+// matches every tenant.
+func articleEventOf(change articleChange, tenantId string) (ArticleEvent, bool) {
+	article := change.FullDocument
+	if article == nil {
+		article = change.FullDocumentBeforeChange
+	}
+	// without the article the tenant is unknown, so the change can not be shown to anyone
+	if article == nil {
+		return ArticleEvent{}, false
+	}
+
+	articleTenant := article.TenantId
+	if articleTenant == "" {
+		articleTenant = tenant.Default
+	}
+	if tenantId != "" && articleTenant != tenantId {
+		return ArticleEvent{}, false
+	}
+
+	event := ArticleEvent{
+		Id:        change.Id.Data,
+		TenantId:  articleTenant,
+		ArticleId: change.DocumentKey.Id,
+		Title:     article.Title,
+		Time:      time.Unix(int64(change.ClusterTime.T), 0).UTC(),
+	}
+
+	switch change.OperationType {
+	case "insert":
+		event.Type = ArticleEventCreated
+	case "replace":
+		event.Type = ArticleEventUpdated
+	case "update":
+		event.Type = updateEventType(change)
+	case "delete":
+		// articles purged from the trash were already announced as deleted
+		if article.DeletedAt != nil {
+			return ArticleEvent{}, false
+		}
+		event.Type = ArticleEventExpired
+	default:
+		return ArticleEvent{}, false
+	}
+	return event, true
+}
+
+func updateEventType(change articleChange) string {
+	if _, ok := change.UpdateDescription.UpdatedFields["deletedAt"]; ok {
+		return ArticleEventDeleted
+	}
+	if _, ok := change.UpdateDescription.UpdatedFields["hiddenAt"]; ok {
+		return ArticleEventExpired
+	}
+	for _, field := range change.UpdateDescription.RemovedFields {
+		if field == "deletedAt" {
+			return ArticleEventRestored
+		}
+	}
+	for field := range change.UpdateDescription.UpdatedFields {
+		if strings.HasPrefix(field, "imagePaths") {
+			return ArticleEventImageAttached
+		}
+	}
+	return ArticleEventUpdated
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"article-management-service/pkg/tenant"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestArticleDbHandler_Watch(t *testing.T) {
+	t.Parallel()
+
+	database, close := createDbWithReplica(t, true)
+	defer close()
+
+	h := ArticleDbHandler{}
+	if err := h.New(database); err != nil {
+		t.Fatalf("ArticleDbHandler.New() error = %v, wantErr %v", err, false)
+	}
+	if err := h.EnableChangeEvents(context.Background()); err != nil {
+		t.Fatalf("ArticleDbHandler.EnableChangeEvents() error = %v, wantErr %v", err, false)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	events, err := h.Watch(ctx, "")
+	if err != nil {
+		t.Fatalf("ArticleDbHandler.Watch() error = %v, wantErr %v", err, false)
+	}
+
+	article := ArticleDb{Title: "Test_Title", ExpirationDate: time.Now().Add(time.Hour), Description: "Test_Description"}
+	if _, err := h.InsertOne(tenant.WithTenant(ctx, "teamA"), article); err != nil {
+		t.Fatalf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+	}
+	id, err := h.InsertOne(ctx, article)
+	if err != nil {
+		t.Fatalf("ArticleDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+	}
+	if _, err := h.AppendImage(ctx, id, "images/default/test"); err != nil {
+		t.Fatalf("ArticleDbHandler.AppendImage() error = %v, wantErr %v", err, false)
+	}
+
+	created := <-events
+	if created.Type != ArticleEventCreated || created.ArticleId != id {
+		t.Errorf("ArticleDbHandler.Watch() = %+v, want the created article of the tenant", created)
+	}
+	attached := <-events
+	if attached.Type != ArticleEventImageAttached {
+		t.Errorf("ArticleDbHandler.Watch() = %+v, want %v", attached, ArticleEventImageAttached)
+	}
+
+	t.Run("Successfully resume after an event", func(t *testing.T) {
+		resumed, err := h.Watch(ctx, created.Id)
+		if err != nil {
+			t.Fatalf("ArticleDbHandler.Watch() error = %v, wantErr %v", err, false)
+		}
+		if event := <-resumed; event.Id != attached.Id {
+			t.Errorf("ArticleDbHandler.Watch() = %+v, want %+v", event, attached)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArticleEventOf(t *testing.T) {
+	deletedAt := time.Now()
+	change := func(operationType string, article *ArticleDb, before *ArticleDb, updated bson.M, removed []string) articleChange {
+		c := articleChange{OperationType: operationType, FullDocument: article, FullDocumentBeforeChange: before}
+		c.Id.Data = "token"
+		c.DocumentKey.Id = primitive.NewObjectID()
+		c.UpdateDescription.UpdatedFields = updated
+		c.UpdateDescription.RemovedFields = removed
+		return c
+	}
+	legacy := &ArticleDb{Title: "Legacy"}
+	teamA := &ArticleDb{Title: "Team_A", TenantId: "teamA"}
+
+	tests := []struct {
+		name         string
+		change       articleChange
+		tenantId     string
+		expectedType string
+		expectedOk   bool
+	}{
+		{name: "Successfully announce created articles", change: change("insert", legacy, nil, nil, nil), tenantId: "default", expectedType: ArticleEventCreated, expectedOk: true},
+		{name: "Successfully announce updated articles", change: change("update", teamA, nil, bson.M{"description": "changed"}, nil), tenantId: "teamA", expectedType: ArticleEventUpdated, expectedOk: true},
+		{name: "Successfully announce attached images", change: change("update", teamA, nil, bson.M{"imagePaths.1": "images/teamA/1"}, nil), tenantId: "teamA", expectedType: ArticleEventImageAttached, expectedOk: true},
+		{name: "Successfully announce deleted articles", change: change("update", teamA, nil, bson.M{"deletedAt": deletedAt}, nil), tenantId: "teamA", expectedType: ArticleEventDeleted, expectedOk: true},
+		{name: "Successfully announce restored articles", change: change("update", teamA, nil, bson.M{}, []string{"deletedAt"}), tenantId: "teamA", expectedType: ArticleEventRestored, expectedOk: true},
+		{name: "Successfully announce hidden articles", change: change("update", teamA, nil, bson.M{"hiddenAt": deletedAt}, nil), tenantId: "teamA", expectedType: ArticleEventExpired, expectedOk: true},
+		{name: "Successfully announce expired articles", change: change("delete", nil, teamA, nil, nil), tenantId: "teamA", expectedType: ArticleEventExpired, expectedOk: true},
+		{name: "Successfully announce articles of every tenant", change: change("insert", teamA, nil, nil, nil), tenantId: "", expectedType: ArticleEventCreated, expectedOk: true},
+		{name: "Prevent announcing articles of other tenants", change: change("insert", teamA, nil, nil, nil), tenantId: "default", expectedOk: false},
+		{name: "Prevent announcing deletions of unknown tenant", change: change("delete", nil, nil, nil, nil), tenantId: "default", expectedOk: false},
+		{name: "Prevent announcing purged articles twice", change: change("delete", nil, &ArticleDb{DeletedAt: &deletedAt}, nil, nil), tenantId: "default", expectedOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, ok := articleEventOf(tt.change, tt.tenantId)
+			if ok != tt.expectedOk || event.Type != tt.expectedType {
+				t.Errorf("articleEventOf() = %v, %v, want %v, %v", event.Type, ok, tt.expectedType, tt.expectedOk)
+			}
+			if ok && (event.Id != "token" || event.ArticleId != tt.change.DocumentKey.Id) {
+				t.Errorf("articleEventOf() = %+v, want the id and article id of the change", event)
+			}
+		})
+	}
+}
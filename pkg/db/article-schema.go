@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The maximum amount of images of an article
+const maxImagePaths = 3
+
+// The $jsonSchema validators of the articles collection, one per version of ArticleDb. A change of ArticleDb gets
+// a new version and a migration that applies it; the old versions stay for the down steps of the migrations.
+// The limits mirror the validation of the controller, so documents written by other tools decode as articles.
+var articleSchemaV1 = bson.M{
+	"bsonType": "object",
+	"required": bson.A{"title"},
+	"properties": bson.M{
+		"_id":            bson.M{"bsonType": "objectId"},
+		"tenantId":       bson.M{"bsonType": "string"},
+		"authorId":       bson.M{"bsonType": "string"},
+		"title":          bson.M{"bsonType": "string", "minLength": 1},
+		"expirationDate": bson.M{"bsonType": "date"},
+		"description":    bson.M{"bsonType": "string", "maxLength": 4000},
+		"imagePaths":     bson.M{"bsonType": "array", "maxItems": maxImagePaths, "items": bson.M{"bsonType": "string"}},
+		"deletedAt":      bson.M{"bsonType": "date"},
+		"expiryPolicy":   bson.M{"enum": bson.A{ExpiryPolicyDelete, ExpiryPolicyArchive, ExpiryPolicyHide}},
+		"renewals":       bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+	},
+}
+
+// Version 2 adds hiddenAt
+var articleSchemaV2 = withProperties(articleSchemaV1, bson.M{
+	"hiddenAt": bson.M{"bsonType": "date"},
+})
+
+// articleSchema is the validator new articles collections are created with
+var articleSchema = articleSchemaV2
+
+// Copies the schema with more properties
+func withProperties(schema bson.M, properties bson.M) bson.M {
+	copied := bson.M{}
+	for key, value := range schema {
+		copied[key] = value
+	}
+	merged := bson.M{}
+	for name, property := range schema["properties"].(bson.M) {
+		merged[name] = property
+	}
+	for name, property := range properties {
+		merged[name] = property
+	}
+	copied["properties"] = merged
+	return copied
+}
+
+// Creates the articles collection with the validator; false when the collection exists already
+func createArticles(ctx context.Context, database *mongo.Database, schema bson.M) (bool, error) {
+	opts := options.CreateCollection().
+		SetValidator(bson.M{"$jsonSchema": schema}).
+		SetValidationLevel("moderate").
+		SetValidationAction("error")
+	err := database.CreateCollection(ctx, "articles", opts)
+	if isNamespaceExists(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Replaces the validator of the articles collection, creating the collection when missing. The moderate level
+// validates inserts and updates of valid articles, so invalid legacy articles stay updatable; nil removes the validator.
+func setArticleSchema(ctx context.Context, database *mongo.Database, schema bson.M) error {
+	if schema != nil {
+		created, err := createArticles(ctx, database, schema)
+		if err != nil || created {
+			return err
+		}
+	}
+
+	validator := bson.M{}
+	if schema != nil {
+		validator = bson.M{"$jsonSchema": schema}
+	}
+	command := bson.D{
+		{Key: "collMod", Value: "articles"},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: "error"},
+	}
+	err := database.RunCommand(ctx, command).Err()
+	if schema == nil && isIndexNotFound(err) {
+		// there is no collection to remove the validator from
+		return nil
+	}
+	return err
+}
+
+func isNamespaceExists(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && (commandErr.Code == 48 || commandErr.Name == "NamespaceExists")
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestArticleSchema_ExpiryPolicy(t *testing.T) {
+	for _, policy := range []string{ExpiryPolicyDelete, ExpiryPolicyArchive, ExpiryPolicyHide} {
+		t.Run("Successfully accept "+policy, func(t *testing.T) {
+			found := false
+			for _, value := range articleSchema["properties"].(bson.M)["expiryPolicy"].(bson.M)["enum"].(bson.A) {
+				found = found || value == policy
+			}
+			if !found || !IsValidExpiryPolicy(policy) {
+				t.Errorf("expiryPolicy enum accepts %v = %v, IsValidExpiryPolicy = %v; want both", policy, found, IsValidExpiryPolicy(policy))
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	AuditActionCreate      = "create"
+	AuditActionAttachImage = "attach-image"
+	AuditActionDelete      = "delete"
+	AuditActionRestore     = "restore"
+	AuditActionUpdate      = "update"
+	AuditActionRenew       = "renew"
+	AuditActionPurge       = "purge"
+	AuditActionArchive     = "archive"
+)
+
+// AuditActorSystem is the actor of the entries of the background jobs
+const AuditActorSystem = "system"
+
+// the maximum amount of audit entries returned by one query
+const maxAuditLimit = 1000
+
+// AuditDbHandler only inserts and reads; the audit log is append-only
+type AuditDbHandler struct {
+	Logger *logrus.Logger
+	coll   *mongo.Collection
+}
+
+type AuditDbHandlerInterface interface {
+	New(database *mongo.Database) error
+	InsertOne(ctx context.Context, new AuditEntryDb) error
+	Find(ctx context.Context, filter AuditFilter) ([]AuditEntryDb, error)
+}
+
+// AuditEntryDb is one mutation of an article with the article before and after it
+type AuditEntryDb struct {
+	Id        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantId  string             `bson:"tenantId" json:"tenantId"`
+	Time      time.Time          `bson:"time" json:"time"`
+	ActorId   string             `bson:"actorId,omitempty" json:"actorId,omitempty"` // empty for anonymous requests
+	Action    string             `bson:"action" json:"action"`
+	ArticleId primitive.ObjectID `bson:"articleId" json:"articleId"`
+	Before    *ArticleDb         `bson:"before,omitempty" json:"before,omitempty"`
+	After     *ArticleDb         `bson:"after,omitempty" json:"after,omitempty"`
+	ClientIp  string             `bson:"clientIp,omitempty" json:"clientIp,omitempty"`
+	RequestId string             `bson:"requestId,omitempty" json:"requestId,omitempty"`
+}
+
+// AuditFilter narrows the audit entries down; zero values do not filter
+type AuditFilter struct {
+	ArticleId primitive.ObjectID
+	ActorId   string
+	From      time.Time // inclusive
+	To        time.Time // exclusive
+	Limit     int64
+}
+
+// Binds the audit collection; the indexes for the filters are created by the migrations
+func (h *AuditDbHandler) New(database *mongo.Database) error {
+	h.coll = database.Collection("audit")
+	return nil
+}
+
+func (h *AuditDbHandler) logError(ctx context.Context, operation string, err error) {
+	logging.FromContext(ctx, h.Logger).WithError(err).WithFields(logrus.Fields{
+		"collection": "audit",
+		"operation":  operation,
+	}).Error("db operation failed")
+}
+
+// Appends an entry to the audit log of the tenant of the context
+func (h *AuditDbHandler) InsertOne(ctx context.Context, new AuditEntryDb) error {
+	new.TenantId = tenant.FromContext(ctx)
+	if new.Time.IsZero() {
+		new.Time = time.Now()
+	}
+
+	if _, err := h.coll.InsertOne(ctx, new); err != nil {
+		h.logError(ctx, "InsertOne", err)
+		return err
+	}
+	return nil
+}
+
+// Finds the audit entries of the tenant of the context, newest first
+func (h *AuditDbHandler) Find(ctx context.Context, filter AuditFilter) ([]AuditEntryDb, error) {
+	query := bson.M{"tenantId": tenant.FromContext(ctx)}
+	if !filter.ArticleId.IsZero() {
+		query["articleId"] = filter.ArticleId
+	}
+	if filter.ActorId != "" {
+		query["actorId"] = filter.ActorId
+	}
+
+	timeRange := bson.M{}
+	if !filter.From.IsZero() {
+		timeRange["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		timeRange["$lt"] = filter.To
+	}
+	if len(timeRange) > 0 {
+		query["time"] = timeRange
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}}).SetLimit(limit)
+	cursor, err := h.coll.Find(ctx, query, opts)
+	if err != nil {
+		h.logError(ctx, "Find", err)
+		return nil, err
+	}
+
+	entries := make([]AuditEntryDb, 0)
+	if err := cursor.All(ctx, &entries); err != nil {
+		h.logError(ctx, "Find", err)
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"article-management-service/pkg/tenant"
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAuditDbHandler_Find(t *testing.T) {
+	t.Parallel()
+
+	database, close := createDb(t)
+	defer close()
+
+	h := AuditDbHandler{}
+	if err := h.New(database); err != nil {
+		t.Fatalf("AuditDbHandler.New() error = %v, wantErr %v", err, false)
+	}
+
+	articleId := primitive.NewObjectID()
+	start := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+	teamA := tenant.WithTenant(context.Background(), "teamA")
+	for _, insert := range []struct {
+		ctx   context.Context
+		entry AuditEntryDb
+	}{
+		{ctx: context.Background(), entry: AuditEntryDb{Time: start, ActorId: "editor-1", Action: AuditActionCreate, ArticleId: articleId}},
+		{ctx: context.Background(), entry: AuditEntryDb{Time: start.Add(time.Hour), ActorId: "editor-2", Action: AuditActionAttachImage, ArticleId: articleId}},
+		{ctx: context.Background(), entry: AuditEntryDb{Time: start.Add(2 * time.Hour), ActorId: "editor-1", Action: AuditActionCreate, ArticleId: primitive.NewObjectID()}},
+		{ctx: teamA, entry: AuditEntryDb{Time: start, ActorId: "editor-1", Action: AuditActionCreate, ArticleId: articleId}},
+	} {
+		if err := h.InsertOne(insert.ctx, insert.entry); err != nil {
+			t.Fatalf("AuditDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		filter   AuditFilter
+		expected int
+	}{
+		{name: "Successfully find all of the tenant", ctx: context.Background(), filter: AuditFilter{}, expected: 3},
+		{name: "Successfully filter by article", ctx: context.Background(), filter: AuditFilter{ArticleId: articleId}, expected: 2},
+		{name: "Successfully filter by actor", ctx: context.Background(), filter: AuditFilter{ActorId: "editor-1"}, expected: 2},
+		{name: "Successfully filter by time", ctx: context.Background(), filter: AuditFilter{From: start.Add(time.Hour), To: start.Add(2 * time.Hour)}, expected: 1},
+		{name: "Successfully limit", ctx: context.Background(), filter: AuditFilter{Limit: 1}, expected: 1},
+		{name: "Successfully isolate tenants", ctx: teamA, filter: AuditFilter{}, expected: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := h.Find(tt.ctx, tt.filter)
+			if err != nil || len(found) != tt.expected {
+				t.Errorf("AuditDbHandler.Find() = %v, %v, want %v entries", len(found), err, tt.expected)
+			}
+		})
+	}
+}
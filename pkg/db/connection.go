@@ -1,9 +1,10 @@
 package db
 
 import (
+	"article-management-service/pkg/logging"
 	"context"
-	"fmt"
 
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -11,6 +12,7 @@ import (
 
 // Connection represents a connection to the MongoDB database.
 type Connection struct {
+	Logger   *logrus.Logger
 	client   *mongo.Client
 	Database *mongo.Database
 	Close    func()
@@ -18,6 +20,8 @@ type Connection struct {
 
 // Connect initializes the MongoDB connection and sets up the database.
 func (c *Connection) Connect(mongoUri string) error {
+	logger := logging.OrDefault(c.Logger)
+
 	// Configure MongoDB client options.
 	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
 	opts := options.Client().ApplyURI(mongoUri).SetServerAPIOptions(serverAPI)
@@ -26,19 +30,23 @@ func (c *Connection) Connect(mongoUri string) error {
 	var err error
 	c.client, err = mongo.Connect(context.TODO(), opts)
 	if err != nil {
+		logger.WithError(err).Error("failed to connect to MongoDB")
 		return err
 	}
 
 	// Send a ping to confirm a successful connection.
 	var result bson.M
 	if err := c.client.Database("admin").RunCommand(context.TODO(), bson.D{{Key: "ping", Value: 1}}).Decode(&result); err != nil {
+		logger.WithError(err).Error("failed to ping MongoDB")
 		return err
 	}
-	fmt.Println("Pinged your deployment. You successfully connected to MongoDB!")
+	logger.Info("pinged your deployment; successfully connected to MongoDB")
 
 	// Define the function to close the connection and stop the server.
 	c.Close = func() {
-		c.client.Disconnect(context.TODO())
+		if err := c.client.Disconnect(context.TODO()); err != nil {
+			logger.WithError(err).Warn("failed to disconnect from MongoDB")
+		}
 	}
 
 	// Set the database to use.
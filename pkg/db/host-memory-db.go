@@ -1,19 +1,27 @@
 package db
 
 import (
-	"fmt"
+	"article-management-service/pkg/logging"
+	"log"
 
+	"github.com/sirupsen/logrus"
 	"github.com/tryvium-travels/memongo"
 )
 
 type MockMongo struct {
-	Close func()
+	Logger  *logrus.Logger
+	Replica bool // runs a single node replica set, which change streams require
+	Close   func()
 }
 
 func (mm *MockMongo) HostMemoryDb(mongodPath string) (string, error) {
-	// Start a temporary MongoDB server using memongo.
+	logger := logging.OrDefault(mm.Logger)
+
+	// Start a temporary MongoDB server using memongo; its output is routed through the structured logger.
 	server, err := memongo.StartWithOptions(&memongo.Options{
-		MongodBin: mongodPath,
+		MongodBin:        mongodPath,
+		Logger:           log.New(logger.WriterLevel(logrus.DebugLevel), "", 0),
+		ShouldUseReplica: mm.Replica,
 	})
 	if err != nil {
 		return "", err
@@ -23,7 +31,6 @@ func (mm *MockMongo) HostMemoryDb(mongodPath string) (string, error) {
 		server.Stop()
 	}
 
-	// Print the MongoDB server URI.
-	fmt.Printf("Mongo deployed at: %s\n", server.URI())
+	logger.WithField("uri", server.URI()).Info("memory mongo deployed")
 	return server.URI(), nil
 }
@@ -0,0 +1,372 @@
+package db
+
+import (
+	"article-management-service/pkg/logging"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// the lock of a crashed instance is taken over after
+	defaultMigrationLockTTL = 5 * time.Minute
+	// how often a lock held by another instance is tried again
+	defaultMigrationLockRetry = time.Second
+	// the number of documents a backfill updates at once
+	backfillBatchSize = 1000
+	migrationLockId   = "migrations"
+)
+
+var (
+	// ErrIrreversible is a migration without down step that was asked to be reverted
+	ErrIrreversible = errors.New("migration can not be reverted")
+	// ErrLockLost is a migration lock that expired, e.g. as the database was unreachable, and may be held by
+	// another instance; the running step is canceled
+	ErrLockLost = errors.New("lost the migration lock")
+)
+
+// Migration is a version of the schema. Up and Down may run again after a failure, as MongoDB has no transactional
+// DDL, so they have to be idempotent; Down is nil for migrations that can not be reverted, e.g. lossy backfills.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, database *mongo.Database) error
+	Down        func(ctx context.Context, database *mongo.Database) error
+}
+
+// MigrationRecord is an applied migration
+type MigrationRecord struct {
+	Version     int       `bson:"_id" json:"version"`
+	Description string    `bson:"description" json:"description"`
+	AppliedAt   time.Time `bson:"appliedAt" json:"appliedAt"`
+}
+
+// Migrator applies the migrations in the order of their versions and records the applied versions in the migrations
+// collection. A lock in the migrationLock collection makes sure only one instance migrates at a time; the others
+// wait for it and find the migrations applied.
+type Migrator struct {
+	Logger     *logrus.Logger
+	Migrations []Migration
+	Owner      string        // identifies the instance in the lock; defaults to a random id
+	LockTTL    time.Duration // the lock is renewed every third of it while a step runs, and after every step
+	LockRetry  time.Duration
+	database   *mongo.Database
+	versions   *mongo.Collection
+	lock       *mongo.Collection
+}
+
+// Checks the versions of the migrations and binds the collections of the migrator
+func (m *Migrator) New(database *mongo.Database) error {
+	if err := validateMigrations(m.Migrations); err != nil {
+		return err
+	}
+	if m.Owner == "" {
+		m.Owner = primitive.NewObjectID().Hex()
+	}
+	if m.LockTTL == 0 {
+		m.LockTTL = defaultMigrationLockTTL
+	}
+	if m.LockRetry == 0 {
+		m.LockRetry = defaultMigrationLockRetry
+	}
+
+	m.database = database
+	m.versions = database.Collection("migrations")
+	m.lock = database.Collection("migrationLock")
+	return nil
+}
+
+func validateMigrations(migrations []Migration) error {
+	for i, migration := range migrations {
+		if migration.Version <= 0 || migration.Up == nil {
+			return fmt.Errorf("migration %d needs a positive version and an up step", migration.Version)
+		}
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			return fmt.Errorf("migration %d is not in ascending order", migration.Version)
+		}
+	}
+	return nil
+}
+
+// Returns the applied migrations in the order of their versions
+func (m *Migrator) Applied(ctx context.Context) ([]MigrationRecord, error) {
+	cur, err := m.versions.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		m.logError(ctx, "Applied", err)
+		return nil, err
+	}
+
+	applied := make([]MigrationRecord, 0)
+	if err := cur.All(ctx, &applied); err != nil {
+		m.logError(ctx, "Applied", err)
+		return nil, err
+	}
+	return applied, nil
+}
+
+// Returns the migrations that are not applied yet
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Migration, 0)
+	for _, migration := range m.Migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Applies the pending migrations up to the target version, all when the target is 0, and returns their versions
+func (m *Migrator) Up(ctx context.Context, target int) ([]int, error) {
+	done := make([]int, 0)
+	err := m.locked(ctx, func() error {
+		applied, err := m.appliedVersions(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			if target > 0 && migration.Version > target {
+				break
+			}
+			if applied[migration.Version] {
+				continue
+			}
+
+			logger := logging.FromContext(ctx, m.Logger).WithFields(logrus.Fields{"version": migration.Version, "description": migration.Description})
+			logger.Info("applying migration")
+			err := m.step(ctx, func(ctx context.Context) error {
+				if err := migration.Up(ctx, m.database); err != nil {
+					return err
+				}
+				record := MigrationRecord{Version: migration.Version, Description: migration.Description, AppliedAt: time.Now()}
+				if _, err := m.versions.InsertOne(ctx, record); err != nil {
+					m.logError(ctx, "Up", err)
+					return err
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d: %w", migration.Version, err)
+			}
+			done = append(done, migration.Version)
+
+			if err := m.renewLock(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Reverts the applied migrations above the target version, newest first, and returns their versions
+func (m *Migrator) Down(ctx context.Context, target int) ([]int, error) {
+	done := make([]int, 0)
+	err := m.locked(ctx, func() error {
+		applied, err := m.appliedVersions(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0; i-- {
+			migration := m.Migrations[i]
+			if migration.Version <= target {
+				break
+			}
+			if !applied[migration.Version] {
+				continue
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migration %d: %w", migration.Version, ErrIrreversible)
+			}
+
+			logger := logging.FromContext(ctx, m.Logger).WithFields(logrus.Fields{"version": migration.Version, "description": migration.Description})
+			logger.Info("reverting migration")
+			err := m.step(ctx, func(ctx context.Context) error {
+				if err := migration.Down(ctx, m.database); err != nil {
+					return err
+				}
+				if _, err := m.versions.DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+					m.logError(ctx, "Down", err)
+					return err
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d: %w", migration.Version, err)
+			}
+			done = append(done, migration.Version)
+
+			if err := m.renewLock(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int]bool, error) {
+	records, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(records))
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+	return applied, nil
+}
+
+// Runs migrate while holding the lock; waits while another instance holds it
+func (m *Migrator) locked(ctx context.Context, migrate func() error) error {
+	for {
+		err := m.acquireLock(ctx)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			m.logError(ctx, "Lock", err)
+			return err
+		}
+
+		logging.FromContext(ctx, m.Logger).Info("waiting for another instance to migrate")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.LockRetry):
+		}
+	}
+
+	defer func() {
+		// also released when the context is done
+		if _, err := m.lock.DeleteOne(context.Background(), bson.M{"_id": migrationLockId, "owner": m.Owner}); err != nil {
+			m.logError(ctx, "Unlock", err)
+		}
+	}()
+	return migrate()
+}
+
+// Runs a step and its record while renewing the lock in the background. The step is canceled when the lock can
+// not be renewed, so it does not run on while another instance takes the lock over.
+func (m *Migrator) step(ctx context.Context, run func(ctx context.Context) error) error {
+	stepCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var renewErr error
+	done := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(m.LockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-stepCtx.Done():
+				return
+			case <-ticker.C:
+				if err := m.renewLock(stepCtx); err != nil && stepCtx.Err() == nil {
+					renewErr = err
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err := run(stepCtx)
+	close(done)
+	<-renewed
+	if renewErr != nil {
+		return renewErr
+	}
+	return err
+}
+
+// Takes the lock when it is free or expired; a lock held by another instance fails with a duplicate key error
+func (m *Migrator) acquireLock(ctx context.Context) error {
+	now := time.Now()
+	_, err := m.lock.UpdateOne(ctx,
+		bson.M{"_id": migrationLockId, "$or": bson.A{bson.M{"owner": m.Owner}, bson.M{"expiresAt": bson.M{"$lte": now}}}},
+		bson.M{"$set": bson.M{"owner": m.Owner, "expiresAt": now.Add(m.LockTTL)}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Extends the lock; ErrLockLost when another instance took it over or it is gone
+func (m *Migrator) renewLock(ctx context.Context) error {
+	result, err := m.lock.UpdateOne(ctx,
+		bson.M{"_id": migrationLockId, "owner": m.Owner},
+		bson.M{"$set": bson.M{"expiresAt": time.Now().Add(m.LockTTL)}},
+	)
+	if err != nil {
+		m.logError(ctx, "Lock", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (m *Migrator) logError(ctx context.Context, operation string, err error) {
+	logging.FromContext(ctx, m.Logger).WithError(err).WithFields(logrus.Fields{
+		"collection": "migrations",
+		"operation":  operation,
+	}).Error("db operation failed")
+}
+
+// Applies the update to the documents of the filter in batches, so a backfill of a large collection does not hold
+// a single long running operation; returns the number of modified documents
+func backfill(ctx context.Context, coll *mongo.Collection, filter bson.M, update bson.M) (int64, error) {
+	var modified int64
+	after := primitive.NilObjectID
+	for {
+		batchFilter := bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$gt": after}}}}
+		cur, err := coll.Find(ctx, batchFilter, options.Find().
+			SetProjection(bson.M{"_id": 1}).
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetLimit(backfillBatchSize))
+		if err != nil {
+			return modified, err
+		}
+
+		var batch []struct {
+			Id primitive.ObjectID `bson:"_id"`
+		}
+		if err := cur.All(ctx, &batch); err != nil {
+			return modified, err
+		}
+		if len(batch) == 0 {
+			return modified, nil
+		}
+
+		ids := make(bson.A, 0, len(batch))
+		for _, document := range batch {
+			ids = append(ids, document.Id)
+		}
+		// the filter is repeated, as the documents may have changed since they were found
+		result, err := coll.UpdateMany(ctx, bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$in": ids}}}}, update)
+		if err != nil {
+			return modified, err
+		}
+		modified += result.ModifiedCount
+		after = batch[len(batch)-1].Id
+	}
+}
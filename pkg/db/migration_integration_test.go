@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrator(t *testing.T) {
+	t.Parallel()
+	db, close := createDb(t)
+	defer close()
+
+	// the database of the service is migrated already
+	database := db.Client().Database("MigratorTest")
+	var steps []string
+	step := func(name string, err error) func(ctx context.Context, database *mongo.Database) error {
+		return func(ctx context.Context, database *mongo.Database) error {
+			steps = append(steps, name)
+			return err
+		}
+	}
+	failing := errors.New("failing step")
+	migrations := []Migration{
+		{Version: 1, Description: "one", Up: step("up 1", nil), Down: step("down 1", nil)},
+		{Version: 2, Description: "two", Up: step("up 2", nil), Down: step("down 2", nil)},
+		{Version: 3, Description: "backfill", Up: step("up 3", nil)},
+	}
+	reversible := []Migration{migrations[0], migrations[1], {Version: 3, Up: step("up 3", nil), Down: step("down 3", nil)}}
+	newMigrator := func(t *testing.T, migrations []Migration) *Migrator {
+		m := &Migrator{Migrations: migrations, LockRetry: 10 * time.Millisecond}
+		if err := m.New(database); err != nil {
+			t.Fatalf("Migrator.New() error = %v", err)
+		}
+		return m
+	}
+
+	// the cases run in order on the same database
+	tests := []struct {
+		name          string
+		migrations    []Migration
+		down          bool
+		target        int
+		expected      []int
+		expectedSteps []string
+		wantErr       error
+	}{
+		{name: "Successfully apply up to the target", migrations: migrations, target: 2, expected: []int{1, 2}, expectedSteps: []string{"up 1", "up 2"}},
+		{name: "Successfully apply the pending migrations", migrations: migrations, expected: []int{3}, expectedSteps: []string{"up 3"}},
+		{name: "Successfully skip applied migrations", migrations: migrations, expected: []int{}},
+		{name: "Prevent reverting irreversible migrations", migrations: migrations, down: true, target: 1, expected: []int{}, wantErr: ErrIrreversible},
+		{name: "Successfully revert newest first", migrations: reversible, down: true, expected: []int{3, 2, 1}, expectedSteps: []string{"down 3", "down 2", "down 1"}},
+		{name: "Prevent recording failed migrations", migrations: []Migration{migrations[0], {Version: 2, Up: step("up 2", failing)}}, expected: []int{1}, expectedSteps: []string{"up 1", "up 2"}, wantErr: failing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps = nil
+			m := newMigrator(t, tt.migrations)
+
+			migrate := m.Up
+			if tt.down {
+				migrate = m.Down
+			}
+			versions, err := migrate(context.Background(), tt.target)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(versions, tt.expected) {
+				t.Fatalf("Migrator() = %v, %v, want %v, %v", versions, err, tt.expected, tt.wantErr)
+			}
+			if !reflect.DeepEqual(steps, tt.expectedSteps) {
+				t.Errorf("Migrator() steps = %v, want %v", steps, tt.expectedSteps)
+			}
+		})
+	}
+
+	t.Run("Successfully record the applied migrations", func(t *testing.T) {
+		applied, err := newMigrator(t, migrations).Applied(context.Background())
+		if err != nil || len(applied) != 1 || applied[0].Version != 1 || applied[0].Description != "one" {
+			t.Errorf("Migrator.Applied() = %v, %v, want version 1", applied, err)
+		}
+
+		pending, err := newMigrator(t, migrations).Pending(context.Background())
+		if err != nil || len(pending) != 2 || pending[0].Version != 2 {
+			t.Errorf("Migrator.Pending() = %v, %v, want versions 2 and 3", pending, err)
+		}
+	})
+
+	t.Run("Wait for the lock of another instance", func(t *testing.T) {
+		lock := database.Collection("migrationLock")
+		if _, err := lock.InsertOne(context.Background(), bson.M{"_id": migrationLockId, "owner": "other", "expiresAt": time.Now().Add(time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		if _, err := newMigrator(t, migrations).Up(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Migrator.Up() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+
+		// the lock of a crashed instance expires
+		if _, err := lock.UpdateOne(context.Background(), bson.M{"_id": migrationLockId}, bson.M{"$set": bson.M{"expiresAt": time.Now()}}); err != nil {
+			t.Fatal(err)
+		}
+		versions, err := newMigrator(t, migrations).Up(context.Background(), 0)
+		if err != nil || !reflect.DeepEqual(versions, []int{2, 3}) {
+			t.Errorf("Migrator.Up() = %v, %v, want %v", versions, err, []int{2, 3})
+		}
+		if count, err := lock.CountDocuments(context.Background(), bson.M{}); err != nil || count != 0 {
+			t.Errorf("Migrator.Up() left %v locks, %v", count, err)
+		}
+	})
+	t.Run("Successfully keep the lock during long steps", func(t *testing.T) {
+		lock := database.Collection("migrationLock")
+		m := newMigrator(t, []Migration{{Version: 4, Up: func(ctx context.Context, database *mongo.Database) error {
+			time.Sleep(300 * time.Millisecond)
+			// the lock is renewed, so another instance can not take it over
+			var held struct {
+				ExpiresAt time.Time `bson:"expiresAt"`
+			}
+			if err := lock.FindOne(ctx, bson.M{"_id": migrationLockId}).Decode(&held); err != nil {
+				return err
+			}
+			if !held.ExpiresAt.After(time.Now()) {
+				return errors.New("the lock expired")
+			}
+			return nil
+		}}})
+		m.LockTTL = 100 * time.Millisecond
+
+		if versions, err := m.Up(context.Background(), 0); err != nil || !reflect.DeepEqual(versions, []int{4}) {
+			t.Errorf("Migrator.Up() = %v, %v, want %v", versions, err, []int{4})
+		}
+	})
+
+	t.Run("Prevent running on when the lock is lost", func(t *testing.T) {
+		lock := database.Collection("migrationLock")
+		m := newMigrator(t, []Migration{{Version: 5, Up: func(ctx context.Context, database *mongo.Database) error {
+			// another instance takes the lock over
+			if _, err := lock.UpdateOne(ctx, bson.M{"_id": migrationLockId}, bson.M{"$set": bson.M{"owner": "other"}}); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}}})
+		m.LockTTL = 100 * time.Millisecond
+
+		if versions, err := m.Up(context.Background(), 0); !errors.Is(err, ErrLockLost) || len(versions) != 0 {
+			t.Errorf("Migrator.Up() = %v, %v, want %v", versions, err, ErrLockLost)
+		}
+		if _, err := lock.DeleteMany(context.Background(), bson.M{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
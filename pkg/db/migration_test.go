@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestValidateMigrations(t *testing.T) {
+	up := func(ctx context.Context, database *mongo.Database) error { return nil }
+
+	tests := []struct {
+		name       string
+		migrations []Migration
+		wantErr    bool
+	}{
+		{name: "Successfully validate the migrations of the service", migrations: Migrations(ExpiryPolicyDelete)},
+		{name: "Successfully validate gaps between versions", migrations: []Migration{{Version: 1, Up: up}, {Version: 3, Up: up}}},
+		{name: "Prevent versions out of order", migrations: []Migration{{Version: 2, Up: up}, {Version: 1, Up: up}}, wantErr: true},
+		{name: "Prevent duplicate versions", migrations: []Migration{{Version: 1, Up: up}, {Version: 1, Up: up}}, wantErr: true},
+		{name: "Prevent version 0", migrations: []Migration{{Version: 0, Up: up}}, wantErr: true},
+		{name: "Prevent migrations without up step", migrations: []Migration{{Version: 1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMigrations(tt.migrations); (err != nil) != tt.wantErr {
+				t.Errorf("validateMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIndexName(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    mongo.IndexModel
+		expected string
+	}{
+		{name: "Successfully name indexes after their keys", model: mongo.IndexModel{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "time", Value: -1}}}, expected: "tenantId_1_time_-1"},
+		{name: "Successfully use the given name", model: mongo.IndexModel{Keys: bson.D{{Key: "expirationDate", Value: 1}}, Options: options.Index().SetName("expirationDate_ttl")}, expected: "expirationDate_ttl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if name := indexName(tt.model); name != tt.expected {
+				t.Errorf("indexName() = %v, want %v", name, tt.expected)
+			}
+		})
+	}
+}
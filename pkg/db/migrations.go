@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrations are the versions of the schema of the service. Append new versions; applied versions do not run
+// again, so they must not change. The expiry policy is the one that articles created before expiry policies get.
+func Migrations(expiryPolicy string) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "drop the TTL index that applied to every article",
+			Up: func(ctx context.Context, database *mongo.Database) error {
+				// the expiry policies replaced it; the partial TTL index of version 2 can not be created next to it
+				if _, err := database.Collection("articles").Indexes().DropOne(ctx, "expirationDate_1"); err != nil && !isIndexNotFound(err) {
+					return err
+				}
+				return nil
+			},
+		},
+		indexMigration(2, "add the article indexes", "articles",
+			// makes the expirationDate the TTL of the articles with the delete policy
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "expirationDate", Value: 1}},
+				Options: options.Index().
+					SetName("expirationDate_ttl").
+					SetExpireAfterSeconds(0).
+					SetPartialFilterExpression(bson.M{"expiryPolicy": ExpiryPolicyDelete}),
+			},
+			// finds the expired articles to archive
+			mongo.IndexModel{Keys: bson.D{{Key: "expiryPolicy", Value: 1}, {Key: "expirationDate", Value: 1}}},
+			// every query is scoped to a tenant
+			mongo.IndexModel{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "_id", Value: 1}}},
+			// finds the trash to purge
+			mongo.IndexModel{Keys: bson.D{{Key: "deletedAt", Value: 1}}, Options: options.Index().SetSparse(true)},
+		),
+		indexMigration(3, "add the audit indexes", "audit",
+			mongo.IndexModel{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "articleId", Value: 1}, {Key: "time", Value: -1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "actorId", Value: 1}, {Key: "time", Value: -1}}},
+			mongo.IndexModel{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "time", Value: -1}}},
+		),
+		indexMigration(4, "add the unique revision number per article", "revisions",
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "tenantId", Value: 1}, {Key: "articleId", Value: 1}, {Key: "number", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		),
+		indexMigration(5, "add the unique api key hash", "apiKeys",
+			mongo.IndexModel{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		),
+		indexMigration(6, "add the webhook index", "webhooks",
+			mongo.IndexModel{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "events", Value: 1}}},
+		),
+		indexMigration(7, "add the webhook delivery indexes", "webhookDeliveries",
+			// every event is delivered once per webhook
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "webhookId", Value: 1}, {Key: "eventId", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}},
+			// the history of final deliveries is removed at their expireAt
+			mongo.IndexModel{Keys: bson.D{{Key: "expireAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		),
+		indexMigration(8, "remove full rate limit buckets", "rateLimits",
+			mongo.IndexModel{Keys: bson.D{{Key: "expireAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		),
+		{
+			Version:     9,
+			Description: "backfill the expiry policy of articles created before expiry policies",
+			Up: func(ctx context.Context, database *mongo.Database) error {
+				h := &ArticleDbHandler{}
+				if err := h.New(database); err != nil {
+					return err
+				}
+				_, err := h.BackfillExpiryPolicy(ctx, expiryPolicy)
+				return err
+			},
+			// irreversible, the backfilled articles can not be told apart from the others
+		},
+		{
+			Version:     10,
+			Description: "validate the articles with the $jsonSchema of version 1",
+			Up: func(ctx context.Context, database *mongo.Database) error {
+				return setArticleSchema(ctx, database, articleSchemaV1)
+			},
+			Down: func(ctx context.Context, database *mongo.Database) error {
+				return setArticleSchema(ctx, database, nil)
+			},
+		},
+		{
+			Version:     11,
+			Description: "validate the articles with the $jsonSchema of version 2",
+			Up: func(ctx context.Context, database *mongo.Database) error {
+				return setArticleSchema(ctx, database, articleSchemaV2)
+			},
+			Down: func(ctx context.Context, database *mongo.Database) error {
+				return setArticleSchema(ctx, database, articleSchemaV1)
+			},
+		},
+	}
+}
+
+// A migration that creates the indexes on the collection; reverted by dropping them
+func indexMigration(version int, description string, collection string, models ...mongo.IndexModel) Migration {
+	return Migration{
+		Version:     version,
+		Description: description,
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			_, err := database.Collection(collection).Indexes().CreateMany(ctx, models)
+			return err
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			for _, model := range models {
+				name := indexName(model)
+				if _, err := database.Collection(collection).Indexes().DropOne(ctx, name); err != nil && !isIndexNotFound(err) {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// The name of the index; MongoDB names indexes without name after their keys, e.g. tenantId_1_time_-1
+func indexName(model mongo.IndexModel) string {
+	if model.Options != nil && model.Options.Name != nil {
+		return *model.Options.Name
+	}
+
+	parts := make([]string, 0)
+	for _, key := range model.Keys.(bson.D) {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"article-management-service/pkg/logging"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RateLimitDbHandler stores token buckets in MongoDB, so every instance shares the same limits.
+// It implements ratelimit.Store.
+type RateLimitDbHandler struct {
+	Logger *logrus.Logger
+	coll   *mongo.Collection
+}
+
+type RateLimitBucketDb struct {
+	Key       string    `bson:"_id"`
+	Tokens    float64   `bson:"tokens"`
+	Allowed   bool      `bson:"allowed"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+	ExpireAt  time.Time `bson:"expireAt"`
+}
+
+// Binds the rateLimits collection; buckets are removed by a ttl index of the migrations once they are full again
+func (h *RateLimitDbHandler) New(database *mongo.Database) error {
+	h.coll = database.Collection("rateLimits")
+	return nil
+}
+
+// Refills the bucket and takes a token in one atomic update, so concurrent instances can not overspend
+func (h *RateLimitDbHandler) Take(ctx context.Context, key string, burst float64, ratePerSecond float64, now time.Time) (float64, bool, error) {
+	now = now.UTC().Truncate(time.Millisecond)
+
+	// seconds since the last update; a new bucket starts full
+	elapsed := bson.M{"$divide": bson.A{
+		bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updatedAt", now}}}}}},
+		1000,
+	}}
+	refilled := bson.M{"$min": bson.A{
+		burst,
+		bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$tokens", burst}}, bson.M{"$multiply": bson.A{elapsed, ratePerSecond}}}},
+	}}
+
+	var expireAt time.Time
+	if ratePerSecond > 0 {
+		expireAt = now.Add(time.Duration(burst / ratePerSecond * float64(time.Second)))
+	} else {
+		expireAt = now.Add(24 * time.Hour)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"refilled": refilled}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed":   bson.M{"$gte": bson.A{"$refilled", 1}},
+			"tokens":    bson.M{"$cond": bson.A{bson.M{"$gte": bson.A{"$refilled", 1}}, bson.M{"$subtract": bson.A{"$refilled", 1}}, "$refilled"}},
+			"updatedAt": now,
+			"expireAt":  expireAt,
+		}}},
+		{{Key: "$unset", Value: "refilled"}},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var bucket RateLimitBucketDb
+	err := h.coll.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&bucket)
+	if err != nil {
+		logging.FromContext(ctx, h.Logger).WithError(err).WithFields(logrus.Fields{
+			"collection": "rateLimits",
+			"operation":  "Take",
+		}).Error("db operation failed")
+		return 0, false, err
+	}
+
+	return bucket.Tokens, bucket.Allowed, nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimitDbHandler_Take(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Share one bucket and refill it over time", func(t *testing.T) {
+		t.Parallel()
+
+		database, close := createDb(t)
+		defer close()
+
+		h := RateLimitDbHandler{}
+		if err := h.New(database); err != nil {
+			t.Fatalf("RateLimitDbHandler.New() error = %v, wantErr %v", err, false)
+		}
+
+		now := time.Now()
+		for i := 0; i < 2; i++ {
+			_, allowed, err := h.Take(context.Background(), "read:ip:127.0.0.1", 2, 1, now)
+			if err != nil || !allowed {
+				t.Fatalf("RateLimitDbHandler.Take() = %v, %v, want %v on take %d", allowed, err, true, i)
+			}
+		}
+
+		tokens, allowed, err := h.Take(context.Background(), "read:ip:127.0.0.1", 2, 1, now)
+		if err != nil || allowed || tokens != 0 {
+			t.Errorf("RateLimitDbHandler.Take() = %v, %v, %v, want %v, %v", tokens, allowed, err, 0, false)
+		}
+
+		_, allowed, err = h.Take(context.Background(), "read:ip:127.0.0.1", 2, 1, now.Add(time.Second))
+		if err != nil || !allowed {
+			t.Errorf("RateLimitDbHandler.Take() = %v, %v, want %v after refill", allowed, err, true)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// how often inserting a revision is retried when a concurrent revision took the number
+const maxRevisionRetries = 3
+
+type RevisionDbHandler struct {
+	Logger *logrus.Logger
+	coll   *mongo.Collection
+}
+
+type RevisionDbHandlerInterface interface {
+	New(database *mongo.Database) error
+	InsertOne(ctx context.Context, new RevisionDb) (int, error)
+	FindByArticleId(ctx context.Context, articleId primitive.ObjectID) ([]RevisionDb, error)
+	FindOne(ctx context.Context, articleId primitive.ObjectID, number int) (*RevisionDb, error)
+	DeleteByArticleIds(ctx context.Context, articleIds []primitive.ObjectID) (int64, error)
+}
+
+// RevisionDb is a version of an article; the versions of an article are numbered from 1
+type RevisionDb struct {
+	Id             primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	TenantId       string             `bson:"tenantId" json:"-"`
+	ArticleId      primitive.ObjectID `bson:"articleId" json:"articleId"`
+	Number         int                `bson:"number" json:"number"`
+	Time           time.Time          `bson:"time" json:"time"`
+	ActorId        string             `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	Title          string             `bson:"title" json:"title"`
+	ExpirationDate time.Time          `bson:"expirationDate" json:"expirationDate"`
+	Description    string             `bson:"description" json:"description"`
+	ImageFilePaths []string           `bson:"imagePaths,omitempty" json:"imagePaths,omitempty"`
+}
+
+// Binds the revisions collection; the unique index on the number per article is created by the migrations
+func (h *RevisionDbHandler) New(database *mongo.Database) error {
+	h.coll = database.Collection("revisions")
+	return nil
+}
+
+func (h *RevisionDbHandler) logError(ctx context.Context, operation string, err error) {
+	logging.FromContext(ctx, h.Logger).WithError(err).WithFields(logrus.Fields{
+		"collection": "revisions",
+		"operation":  operation,
+	}).Error("db operation failed")
+}
+
+// Inserts the revision as the next version of the article; returns the number of the revision
+func (h *RevisionDbHandler) InsertOne(ctx context.Context, new RevisionDb) (int, error) {
+	new.TenantId = tenant.FromContext(ctx)
+	if new.Time.IsZero() {
+		new.Time = time.Now()
+	}
+
+	var err error
+	for i := 0; i < maxRevisionRetries; i++ {
+		var latest *RevisionDb
+		if latest, err = h.findLatest(ctx, new.ArticleId); err != nil {
+			break
+		}
+
+		new.Number = 1
+		if latest != nil {
+			new.Number = latest.Number + 1
+		}
+
+		if _, err = h.coll.InsertOne(ctx, new); err == nil {
+			return new.Number, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+
+	h.logError(ctx, "InsertOne", err)
+	return 0, err
+}
+
+func (h *RevisionDbHandler) findLatest(ctx context.Context, articleId primitive.ObjectID) (*RevisionDb, error) {
+	filter := bson.M{"tenantId": tenant.FromContext(ctx), "articleId": articleId}
+	opts := options.FindOne().SetSort(bson.D{{Key: "number", Value: -1}})
+
+	var revision RevisionDb
+	if err := h.coll.FindOne(ctx, filter, opts).Decode(&revision); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Finds all revisions of the article, oldest first
+func (h *RevisionDbHandler) FindByArticleId(ctx context.Context, articleId primitive.ObjectID) ([]RevisionDb, error) {
+	filter := bson.M{"tenantId": tenant.FromContext(ctx), "articleId": articleId}
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: 1}})
+
+	cur, err := h.coll.Find(ctx, filter, opts)
+	if err != nil {
+		h.logError(ctx, "FindByArticleId", err)
+		return nil, err
+	}
+
+	revisions := make([]RevisionDb, 0)
+	if err := cur.All(ctx, &revisions); err != nil {
+		h.logError(ctx, "FindByArticleId", err)
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// Finds one revision of the article by its number
+func (h *RevisionDbHandler) FindOne(ctx context.Context, articleId primitive.ObjectID, number int) (*RevisionDb, error) {
+	filter := bson.M{"tenantId": tenant.FromContext(ctx), "articleId": articleId, "number": number}
+
+	var revision RevisionDb
+	if err := h.coll.FindOne(ctx, filter).Decode(&revision); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		h.logError(ctx, "FindOne", err)
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Deletes the revisions of the articles of every tenant, e.g. of purged articles; returns the number of deleted revisions
+func (h *RevisionDbHandler) DeleteByArticleIds(ctx context.Context, articleIds []primitive.ObjectID) (int64, error) {
+	if len(articleIds) == 0 {
+		return 0, nil
+	}
+
+	result, err := h.coll.DeleteMany(ctx, bson.M{"articleId": bson.M{"$in": articleIds}})
+	if err != nil {
+		h.logError(ctx, "DeleteByArticleIds", err)
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRevisionDbHandler_InsertOne(t *testing.T) {
+	t.Parallel()
+
+	database, close := createDb(t)
+	defer close()
+
+	h := RevisionDbHandler{}
+	if err := h.New(database); err != nil {
+		t.Fatalf("RevisionDbHandler.New() error = %v, wantErr %v", err, false)
+	}
+
+	ctx := context.Background()
+	articleId := primitive.NewObjectID()
+	for i, description := range []string{"First", "Second", "Third"} {
+		number, err := h.InsertOne(ctx, RevisionDb{ArticleId: articleId, Title: "Title", Description: description})
+		if err != nil || number != i+1 {
+			t.Fatalf("RevisionDbHandler.InsertOne() = %v, %v, want %v", number, err, i+1)
+		}
+	}
+
+	t.Run("Successfully number the revisions per article", func(t *testing.T) {
+		number, err := h.InsertOne(ctx, RevisionDb{ArticleId: primitive.NewObjectID(), Title: "Other"})
+		if err != nil || number != 1 {
+			t.Errorf("RevisionDbHandler.InsertOne() = %v, %v, want %v", number, err, 1)
+		}
+	})
+
+	t.Run("Successfully find the revisions oldest first", func(t *testing.T) {
+		revisions, err := h.FindByArticleId(ctx, articleId)
+		if err != nil || len(revisions) != 3 || revisions[0].Description != "First" || revisions[2].Number != 3 {
+			t.Errorf("RevisionDbHandler.FindByArticleId() = %v, %v, want 3 revisions", revisions, err)
+		}
+	})
+
+	t.Run("Successfully find one revision", func(t *testing.T) {
+		revision, err := h.FindOne(ctx, articleId, 2)
+		if err != nil || revision == nil || revision.Description != "Second" {
+			t.Errorf("RevisionDbHandler.FindOne() = %v, %v, want %v", revision, err, "Second")
+		}
+	})
+
+	t.Run("Successfully find no unknown revision", func(t *testing.T) {
+		revision, err := h.FindOne(ctx, articleId, 4)
+		if err != nil || revision != nil {
+			t.Errorf("RevisionDbHandler.FindOne() = %v, %v, want %v", revision, err, nil)
+		}
+	})
+	t.Run("Successfully delete the revisions of purged articles", func(t *testing.T) {
+		deleted, err := h.DeleteByArticleIds(ctx, []primitive.ObjectID{articleId})
+		if err != nil || deleted != 3 {
+			t.Errorf("RevisionDbHandler.DeleteByArticleIds() = %v, %v, want %v", deleted, err, 3)
+		}
+		if revisions, err := h.FindByArticleId(ctx, articleId); err != nil || len(revisions) != 0 {
+			t.Errorf("RevisionDbHandler.FindByArticleId() = %v, %v, want none", revisions, err)
+		}
+	})
+}
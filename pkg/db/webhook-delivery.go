@@ -0,0 +1,177 @@
+package db
+
+import (
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead" // gave up after the maximum attempts
+)
+
+// the maximum amount of deliveries returned by one query
+const maxDeliveries = 1000
+
+type WebhookDeliveryDbHandler struct {
+	Logger *logrus.Logger
+	coll   *mongo.Collection
+}
+
+type WebhookDeliveryDbHandlerInterface interface {
+	New(database *mongo.Database) error
+	Enqueue(ctx context.Context, new WebhookDeliveryDb) error
+	Claim(ctx context.Context, now time.Time, lease time.Duration, skipWebhookIds []primitive.ObjectID) (*WebhookDeliveryDb, error)
+	Finish(ctx context.Context, delivery WebhookDeliveryDb, attempt WebhookAttemptDb) error
+	FindByWebhookId(ctx context.Context, webhookId primitive.ObjectID, status string) ([]WebhookDeliveryDb, error)
+	Retry(ctx context.Context, webhookId primitive.ObjectID, id primitive.ObjectID) (bool, error)
+}
+
+// WebhookDeliveryDb is one event to deliver to one webhook, with the history of its attempts
+type WebhookDeliveryDb struct {
+	Id            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WebhookId     primitive.ObjectID `bson:"webhookId" json:"webhookId"`
+	TenantId      string             `bson:"tenantId" json:"-"`
+	EventId       string             `bson:"eventId" json:"eventId"`
+	EventType     string             `bson:"eventType" json:"eventType"`
+	Payload       string             `bson:"payload" json:"payload"`
+	Status        string             `bson:"status" json:"status"`
+	Attempts      []WebhookAttemptDb `bson:"attempts,omitempty" json:"attempts,omitempty"`
+	RoundAttempts int                `bson:"roundAttempts" json:"roundAttempts"` // the attempts since the delivery was enqueued or retried
+	NextAttemptAt time.Time          `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LockedUntil   time.Time          `bson:"lockedUntil,omitempty" json:"-"`
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+	ExpireAt      *time.Time         `bson:"expireAt,omitempty" json:"-"` // set once the delivery is final
+}
+
+// WebhookAttemptDb is one try to deliver; the status code is 0 when no response was received
+type WebhookAttemptDb struct {
+	Time       time.Time     `bson:"time" json:"time"`
+	StatusCode int           `bson:"statusCode,omitempty" json:"statusCode,omitempty"`
+	Error      string        `bson:"error,omitempty" json:"error,omitempty"`
+	Duration   time.Duration `bson:"duration" json:"duration"`
+}
+
+// Binds the webhookDeliveries collection. The indexes of the migrations deliver every event once per webhook
+// and remove the history of final deliveries at their expireAt.
+func (h *WebhookDeliveryDbHandler) New(database *mongo.Database) error {
+	h.coll = database.Collection("webhookDeliveries")
+	return nil
+}
+
+func (h *WebhookDeliveryDbHandler) logError(ctx context.Context, operation string, err error) {
+	logging.FromContext(ctx, h.Logger).WithError(err).WithFields(logrus.Fields{
+		"collection": "webhookDeliveries",
+		"operation":  operation,
+	}).Error("db operation failed")
+}
+
+// Enqueues a pending delivery for the tenant of the context; enqueuing the same event for the same webhook
+// again is ignored
+func (h *WebhookDeliveryDbHandler) Enqueue(ctx context.Context, new WebhookDeliveryDb) error {
+	new.TenantId = tenant.FromContext(ctx)
+	new.Status = DeliveryStatusPending
+	if new.CreatedAt.IsZero() {
+		new.CreatedAt = time.Now()
+	}
+	if new.NextAttemptAt.IsZero() {
+		new.NextAttemptAt = new.CreatedAt
+	}
+
+	if _, err := h.coll.InsertOne(ctx, new); err != nil && !mongo.IsDuplicateKeyError(err) {
+		h.logError(ctx, "Enqueue", err)
+		return err
+	}
+	return nil
+}
+
+// Claims a due delivery of any tenant for the lease, so other instances skip it meanwhile; nil when none is due.
+// The deliveries of the skipped webhooks are left, e.g. as the instance is delivering to them already.
+func (h *WebhookDeliveryDbHandler) Claim(ctx context.Context, now time.Time, lease time.Duration, skipWebhookIds []primitive.ObjectID) (*WebhookDeliveryDb, error) {
+	filter := bson.M{
+		"status":        DeliveryStatusPending,
+		"nextAttemptAt": bson.M{"$lte": now},
+		"$or":           bson.A{bson.M{"lockedUntil": bson.M{"$exists": false}}, bson.M{"lockedUntil": bson.M{"$lte": now}}},
+	}
+	if len(skipWebhookIds) > 0 {
+		filter["webhookId"] = bson.M{"$nin": skipWebhookIds}
+	}
+	update := bson.M{"$set": bson.M{"lockedUntil": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).SetReturnDocument(options.After)
+
+	var delivery WebhookDeliveryDb
+	if err := h.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		h.logError(ctx, "Claim", err)
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// Records the attempt and stores the status, attempts of the round and next attempt of the delivery; releases the claim
+func (h *WebhookDeliveryDbHandler) Finish(ctx context.Context, delivery WebhookDeliveryDb, attempt WebhookAttemptDb) error {
+	set := bson.M{"status": delivery.Status, "roundAttempts": delivery.RoundAttempts, "nextAttemptAt": delivery.NextAttemptAt}
+	if delivery.ExpireAt != nil {
+		set["expireAt"] = delivery.ExpireAt
+	}
+	update := bson.M{
+		"$set":   set,
+		"$push":  bson.M{"attempts": attempt},
+		"$unset": bson.M{"lockedUntil": ""},
+	}
+
+	if _, err := h.coll.UpdateOne(ctx, bson.M{"_id": delivery.Id}, update); err != nil {
+		h.logError(ctx, "Finish", err)
+		return err
+	}
+	return nil
+}
+
+// Finds the deliveries of a webhook of the tenant of the context, newest first; an empty status finds all
+func (h *WebhookDeliveryDbHandler) FindByWebhookId(ctx context.Context, webhookId primitive.ObjectID, status string) ([]WebhookDeliveryDb, error) {
+	filter := bson.M{"tenantId": tenant.FromContext(ctx), "webhookId": webhookId}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	cur, err := h.coll.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(maxDeliveries))
+	if err != nil {
+		h.logError(ctx, "FindByWebhookId", err)
+		return nil, err
+	}
+
+	deliveries := make([]WebhookDeliveryDb, 0)
+	if err := cur.All(ctx, &deliveries); err != nil {
+		h.logError(ctx, "FindByWebhookId", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Moves a dead delivery of the tenant of the context back to pending with a new round of attempts; the attempts
+// of the previous rounds stay in the history. False when there is no such dead delivery.
+func (h *WebhookDeliveryDbHandler) Retry(ctx context.Context, webhookId primitive.ObjectID, id primitive.ObjectID) (bool, error) {
+	filter := bson.M{"_id": id, "webhookId": webhookId, "tenantId": tenant.FromContext(ctx), "status": DeliveryStatusDead}
+	update := bson.M{
+		"$set":   bson.M{"status": DeliveryStatusPending, "roundAttempts": 0, "nextAttemptAt": time.Now()},
+		"$unset": bson.M{"expireAt": "", "lockedUntil": ""},
+	}
+
+	result, err := h.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		h.logError(ctx, "Retry", err)
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
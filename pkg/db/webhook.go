@@ -0,0 +1,145 @@
+package db
+
+import (
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookDbHandler struct {
+	Logger *logrus.Logger
+	coll   *mongo.Collection
+	state  *mongo.Collection
+}
+
+type WebhookDbHandlerInterface interface {
+	New(database *mongo.Database) error
+	InsertOne(ctx context.Context, new WebhookDb) (primitive.ObjectID, error)
+	FindAll(ctx context.Context) ([]WebhookDb, error)
+	FindOneById(ctx context.Context, id primitive.ObjectID) (*WebhookDb, error)
+	FindByEvent(ctx context.Context, eventType string) ([]WebhookDb, error)
+	DeleteOne(ctx context.Context, id primitive.ObjectID) (bool, error)
+	LoadResumeToken(ctx context.Context) (string, error)
+	SaveResumeToken(ctx context.Context, token string) error
+}
+
+// WebhookDb is a subscription of a url to article events; the secret signs the deliveries
+type WebhookDb struct {
+	Id        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantId  string             `bson:"tenantId" json:"-"`
+	Url       string             `bson:"url" json:"url"`
+	Events    []string           `bson:"events" json:"events"`
+	Secret    string             `bson:"secret" json:"-"`
+	CreatedBy string             `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// Binds the webhooks collection and the collection the dispatcher keeps its position in the change stream in
+func (h *WebhookDbHandler) New(database *mongo.Database) error {
+	h.coll = database.Collection("webhooks")
+	h.state = database.Collection("webhookState")
+	return nil
+}
+
+func (h *WebhookDbHandler) logError(ctx context.Context, operation string, err error) {
+	logging.FromContext(ctx, h.Logger).WithError(err).WithFields(logrus.Fields{
+		"collection": "webhooks",
+		"operation":  operation,
+	}).Error("db operation failed")
+}
+
+// Inserts one webhook for the tenant of the context
+func (h *WebhookDbHandler) InsertOne(ctx context.Context, new WebhookDb) (primitive.ObjectID, error) {
+	new.TenantId = tenant.FromContext(ctx)
+	if new.CreatedAt.IsZero() {
+		new.CreatedAt = time.Now()
+	}
+
+	result, err := h.coll.InsertOne(ctx, new)
+	if err != nil {
+		h.logError(ctx, "InsertOne", err)
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// Finds the webhooks of the tenant of the context
+func (h *WebhookDbHandler) FindAll(ctx context.Context) ([]WebhookDb, error) {
+	return h.find(ctx, "FindAll", bson.M{"tenantId": tenant.FromContext(ctx)})
+}
+
+// Finds the webhooks of the tenant of the context that subscribed to the event type
+func (h *WebhookDbHandler) FindByEvent(ctx context.Context, eventType string) ([]WebhookDb, error) {
+	return h.find(ctx, "FindByEvent", bson.M{"tenantId": tenant.FromContext(ctx), "events": eventType})
+}
+
+func (h *WebhookDbHandler) find(ctx context.Context, operation string, filter bson.M) ([]WebhookDb, error) {
+	cur, err := h.coll.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		h.logError(ctx, operation, err)
+		return nil, err
+	}
+
+	webhooks := make([]WebhookDb, 0)
+	if err := cur.All(ctx, &webhooks); err != nil {
+		h.logError(ctx, operation, err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Finds one webhook of the tenant of the context
+func (h *WebhookDbHandler) FindOneById(ctx context.Context, id primitive.ObjectID) (*WebhookDb, error) {
+	var webhook WebhookDb
+	err := h.coll.FindOne(ctx, bson.M{"_id": id, "tenantId": tenant.FromContext(ctx)}).Decode(&webhook)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		h.logError(ctx, "FindOneById", err)
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// Deletes one webhook of the tenant of the context; its delivery history is kept
+func (h *WebhookDbHandler) DeleteOne(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	result, err := h.coll.DeleteOne(ctx, bson.M{"_id": id, "tenantId": tenant.FromContext(ctx)})
+	if err != nil {
+		h.logError(ctx, "DeleteOne", err)
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// Loads the id of the last article event the dispatcher handled; empty when there is none
+func (h *WebhookDbHandler) LoadResumeToken(ctx context.Context) (string, error) {
+	var state struct {
+		ResumeToken string `bson:"resumeToken"`
+	}
+	if err := h.state.FindOne(ctx, bson.M{"_id": "dispatcher"}).Decode(&state); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
+		h.logError(ctx, "LoadResumeToken", err)
+		return "", err
+	}
+	return state.ResumeToken, nil
+}
+
+// Saves the id of the last article event the dispatcher handled
+func (h *WebhookDbHandler) SaveResumeToken(ctx context.Context, token string) error {
+	update := bson.M{"$set": bson.M{"resumeToken": token, "updatedAt": time.Now()}}
+	if _, err := h.state.UpdateOne(ctx, bson.M{"_id": "dispatcher"}, update, options.Update().SetUpsert(true)); err != nil {
+		h.logError(ctx, "SaveResumeToken", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"article-management-service/pkg/tenant"
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWebhookDbHandler_FindByEvent(t *testing.T) {
+	t.Parallel()
+
+	database, close := createDb(t)
+	defer close()
+
+	h := WebhookDbHandler{}
+	if err := h.New(database); err != nil {
+		t.Fatalf("WebhookDbHandler.New() error = %v, wantErr %v", err, false)
+	}
+
+	teamA := tenant.WithTenant(context.Background(), "teamA")
+	for _, insert := range []struct {
+		ctx     context.Context
+		webhook WebhookDb
+	}{
+		{ctx: context.Background(), webhook: WebhookDb{Url: "https://example.com/1", Events: []string{ArticleEventCreated, ArticleEventDeleted}}},
+		{ctx: context.Background(), webhook: WebhookDb{Url: "https://example.com/2", Events: []string{ArticleEventDeleted}}},
+		{ctx: teamA, webhook: WebhookDb{Url: "https://example.com/3", Events: []string{ArticleEventCreated}}},
+	} {
+		if _, err := h.InsertOne(insert.ctx, insert.webhook); err != nil {
+			t.Fatalf("WebhookDbHandler.InsertOne() error = %v, wantErr %v", err, false)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		eventType string
+		expected  int
+	}{
+		{name: "Successfully find the subscribers of an event", ctx: context.Background(), eventType: ArticleEventDeleted, expected: 2},
+		{name: "Successfully isolate tenants", ctx: teamA, eventType: ArticleEventCreated, expected: 1},
+		{name: "Successfully find no subscribers", ctx: context.Background(), eventType: ArticleEventExpired, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := h.FindByEvent(tt.ctx, tt.eventType)
+			if err != nil || len(found) != tt.expected {
+				t.Errorf("WebhookDbHandler.FindByEvent() = %v, %v, want %v webhooks", len(found), err, tt.expected)
+			}
+		})
+	}
+
+	token, err := h.LoadResumeToken(context.Background())
+	if err != nil || token != "" {
+		t.Errorf("WebhookDbHandler.LoadResumeToken() = %v, %v, want none", token, err)
+	}
+	for _, saved := range []string{"first", "second"} {
+		if err := h.SaveResumeToken(context.Background(), saved); err != nil {
+			t.Fatalf("WebhookDbHandler.SaveResumeToken() error = %v, wantErr %v", err, false)
+		}
+	}
+	if token, err := h.LoadResumeToken(context.Background()); err != nil || token != "second" {
+		t.Errorf("WebhookDbHandler.LoadResumeToken() = %v, %v, want %v", token, err, "second")
+	}
+}
+
+func TestWebhookDeliveryDbHandler_Claim(t *testing.T) {
+	t.Parallel()
+
+	database, close := createDb(t)
+	defer close()
+
+	h := WebhookDeliveryDbHandler{}
+	if err := h.New(database); err != nil {
+		t.Fatalf("WebhookDeliveryDbHandler.New() error = %v, wantErr %v", err, false)
+	}
+
+	ctx := tenant.WithTenant(context.Background(), "teamA")
+	now := time.Now().Truncate(time.Millisecond)
+	webhookId := primitive.NewObjectID()
+	delivery := WebhookDeliveryDb{WebhookId: webhookId, EventId: "token", EventType: ArticleEventCreated, Payload: "{}", NextAttemptAt: now}
+
+	// the same event is only delivered once
+	for i := 0; i < 2; i++ {
+		if err := h.Enqueue(ctx, delivery); err != nil {
+			t.Fatalf("WebhookDeliveryDbHandler.Enqueue() error = %v, wantErr %v", err, false)
+		}
+	}
+
+	if skipped, err := h.Claim(context.Background(), now, time.Minute, []primitive.ObjectID{webhookId}); err != nil || skipped != nil {
+		t.Errorf("WebhookDeliveryDbHandler.Claim() = %v, %v, want none of skipped webhooks", skipped, err)
+	}
+	claimed, err := h.Claim(context.Background(), now, time.Minute, nil)
+	if err != nil || claimed == nil || claimed.TenantId != "teamA" {
+		t.Fatalf("WebhookDeliveryDbHandler.Claim() = %v, %v, want the delivery", claimed, err)
+	}
+	if again, err := h.Claim(context.Background(), now, time.Minute, nil); err != nil || again != nil {
+		t.Errorf("WebhookDeliveryDbHandler.Claim() = %v, %v, want none while claimed", again, err)
+	}
+
+	claimed.Status = DeliveryStatusDead
+	claimed.RoundAttempts = 1
+	expireAt := now.Add(time.Hour)
+	claimed.ExpireAt = &expireAt
+	if err := h.Finish(context.Background(), *claimed, WebhookAttemptDb{Time: now, StatusCode: 500, Error: "failed"}); err != nil {
+		t.Fatalf("WebhookDeliveryDbHandler.Finish() error = %v, wantErr %v", err, false)
+	}
+
+	dead, err := h.FindByWebhookId(ctx, webhookId, DeliveryStatusDead)
+	if err != nil || len(dead) != 1 || len(dead[0].Attempts) != 1 || dead[0].Attempts[0].StatusCode != 500 {
+		t.Fatalf("WebhookDeliveryDbHandler.FindByWebhookId() = %+v, %v, want the dead delivery", dead, err)
+	}
+	if other, err := h.FindByWebhookId(context.Background(), webhookId, ""); err != nil || len(other) != 0 {
+		t.Errorf("WebhookDeliveryDbHandler.FindByWebhookId() = %v, %v, want none of other tenants", len(other), err)
+	}
+
+	if found, err := h.Retry(ctx, webhookId, claimed.Id); err != nil || !found {
+		t.Fatalf("WebhookDeliveryDbHandler.Retry() = %v, %v, want %v", found, err, true)
+	}
+	if found, err := h.Retry(ctx, webhookId, claimed.Id); err != nil || found {
+		t.Errorf("WebhookDeliveryDbHandler.Retry() = %v, %v, want %v for pending deliveries", found, err, false)
+	}
+	retried, err := h.Claim(context.Background(), time.Now().Add(time.Second), time.Minute, nil)
+	if err != nil || retried == nil || retried.Id != claimed.Id {
+		t.Fatalf("WebhookDeliveryDbHandler.Claim() = %v, %v, want the retried delivery", retried, err)
+	}
+	if retried.RoundAttempts != 0 || len(retried.Attempts) != 1 {
+		t.Errorf("WebhookDeliveryDbHandler.Claim() = %+v, want a new round that keeps the history", retried)
+	}
+}
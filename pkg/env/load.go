@@ -1,16 +1,101 @@
 package env
 
-import "github.com/caarlos0/env/v10"
+import (
+	"errors"
+	"time"
 
-type config struct {
-	MongodPath string `env:"MONGOD_PATH" envDefault:"/usr/local/bin/mongod"` // TODO: find solution for this for testing
+	"github.com/caarlos0/env/v10"
+)
+
+// Config is the configuration of the service and its commands, read from the environment
+type Config struct {
+	MongodPath     string `env:"MONGOD_PATH" envDefault:"/usr/local/bin/mongod"` // TODO: find solution for this for testing
+	MongoUri       string `env:"MONGO_URI"`                                      // when empty, a memory db is hosted with MONGOD_PATH
+	MigrateOnStart bool   `env:"MIGRATE_ON_START" envDefault:"true"`             // when disabled, serve requires the migrate command to have run
+	LogLevel       string `env:"LOG_LEVEL" envDefault:"info"`
+	LogFormat      string `env:"LOG_FORMAT" envDefault:"json"` // json or logfmt
+
+	TraceExporter string `env:"TRACE_EXPORTER" envDefault:"none"` // none, otlp or file
+	TraceFile     string `env:"TRACE_FILE" envDefault:"traces.json"`
+
+	ImageDirectory string `env:"IMAGE_DIRECTORY" envDefault:"images"` // where the images are stored, one directory per tenant
+
+	ListenAddr          string        `env:"LISTEN_ADDR" envDefault:":5000"`
+	TLSCertFile         string        `env:"TLS_CERT_FILE"` // HTTPS is served when both cert and key are set; one alone is an error
+	TLSKeyFile          string        `env:"TLS_KEY_FILE"`
+	TLSClientCAFile     string        `env:"TLS_CLIENT_CA_FILE"`
+	TLSClientAuth       string        `env:"TLS_CLIENT_AUTH" envDefault:"none"` // none, request or require
+	TLSReloadInterval   time.Duration `env:"TLS_RELOAD_INTERVAL" envDefault:"30s"`
+	PlainHTTPListenAddr string        `env:"PLAIN_HTTP_LISTEN_ADDR"`                // only used with TLS
+	PlainHTTPMode       string        `env:"PLAIN_HTTP_MODE" envDefault:"redirect"` // redirect or reject
+	GrpcListenAddr      string        `env:"GRPC_LISTEN_ADDR"`                      // the gRPC api is served when set, e.g. :5001
+
+	RateLimitEnabled         bool   `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	RateLimitStore           string `env:"RATE_LIMIT_STORE" envDefault:"memory"` // memory or mongo
+	RateLimitKeyBy           string `env:"RATE_LIMIT_KEY_BY" envDefault:"ip"`    // ip or identity
+	RateLimitReadPerMinute   int    `env:"RATE_LIMIT_READ_PER_MINUTE" envDefault:"600"`
+	RateLimitReadBurst       int    `env:"RATE_LIMIT_READ_BURST" envDefault:"60"`
+	RateLimitCreatePerMinute int    `env:"RATE_LIMIT_CREATE_PER_MINUTE" envDefault:"60"`
+	RateLimitCreateBurst     int    `env:"RATE_LIMIT_CREATE_BURST" envDefault:"10"`
+	RateLimitUploadPerMinute int    `env:"RATE_LIMIT_UPLOAD_PER_MINUTE" envDefault:"12"`
+	RateLimitUploadBurst     int    `env:"RATE_LIMIT_UPLOAD_BURST" envDefault:"3"`
+
+	AuthEnabled         bool     `env:"AUTH_ENABLED" envDefault:"false"` // when enabled, creating articles and uploading images require credentials
+	AuthApiKeysEnabled  bool     `env:"AUTH_API_KEYS_ENABLED" envDefault:"true"`
+	JWTHMACSecret       string   `env:"JWT_HMAC_SECRET"`
+	JWTRSAPublicKeyFile string   `env:"JWT_RSA_PUBLIC_KEY_FILE"`
+	JWTIssuer           string   `env:"JWT_ISSUER"`
+	JWTAudience         string   `env:"JWT_AUDIENCE"`
+	AuthDefaultRoles    []string `env:"AUTH_DEFAULT_ROLES" envDefault:"editor"` // roles of credentials that do not carry any
+
+	TenantHeaderEnabled bool   `env:"TENANT_HEADER_ENABLED" envDefault:"false"` // allow X-Tenant-ID for identities without tenant that may switch tenants
+	TenantMaxArticles   int64  `env:"TENANT_MAX_ARTICLES" envDefault:"0"`       // 0 is unlimited
+	TenantMaxImages     int64  `env:"TENANT_MAX_IMAGES" envDefault:"0"`
+	TenantQuotas        string `env:"TENANT_QUOTAS"` // per tenant overrides, e.g. "teamA=100/300,teamB=50/0"
+
+	TrashRetention     time.Duration `env:"TRASH_RETENTION" envDefault:"720h"` // how long deleted articles can be restored
+	TrashPurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"1h"`
+
+	ExpiryPolicy          string        `env:"EXPIRY_POLICY" envDefault:"delete"` // delete, archive or hide
+	ExpiryArchiveInterval time.Duration `env:"EXPIRY_ARCHIVE_INTERVAL" envDefault:"1m"`
+
+	RenewMaxHorizon  time.Duration `env:"RENEW_MAX_HORIZON" envDefault:"8760h"` // 0 is unlimited
+	RenewMaxRenewals int           `env:"RENEW_MAX_RENEWALS" envDefault:"10"`   // 0 is unlimited
+
+	EventsEnabled          bool `env:"EVENTS_ENABLED" envDefault:"false"`         // needs MongoDB as replica set
+	EventsMaxResumeStreams int  `env:"EVENTS_MAX_RESUME_STREAMS" envDefault:"16"` // change streams of clients resuming from older events
+
+	WebhooksEnabled     bool          `env:"WEBHOOKS_ENABLED" envDefault:"true"` // needs the article events
+	WebhookMaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookBackoffBase  time.Duration `env:"WEBHOOK_BACKOFF_BASE" envDefault:"30s"` // doubled after every failed attempt
+	WebhookBackoffMax   time.Duration `env:"WEBHOOK_BACKOFF_MAX" envDefault:"6h"`
+	WebhookPollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+	WebhookTimeout      time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	WebhookConcurrency  int           `env:"WEBHOOK_CONCURRENCY" envDefault:"8"`               // how many webhooks are delivered to at once
+	WebhookRetention    time.Duration `env:"WEBHOOK_RETENTION" envDefault:"720h"`              // how long the delivery history is kept
+	WebhookAllowPrivate bool          `env:"WEBHOOK_ALLOW_PRIVATE_TARGETS" envDefault:"false"` // lets webhooks reach private, loopback and link-local addresses
+
+	// the routes at the root serve v1 for clients from before the versions
+	UnversionedDeprecation time.Time `env:"API_UNVERSIONED_DEPRECATION" envDefault:"2024-01-01T00:00:00Z"`
+	UnversionedSunset      time.Time `env:"API_UNVERSIONED_SUNSET"` // not announced when empty
+
+	GraphqlMaxDepth      int `env:"GRAPHQL_MAX_DEPTH" envDefault:"10"`        // the deepest nesting of fields of a query
+	GraphqlMaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY" envDefault:"2000"` // the most fields of a query, counted per article of a page
+	GraphqlMaxMutations  int `env:"GRAPHQL_MAX_MUTATIONS" envDefault:"10"`    // the most mutation fields of a request, aliases included
+
+	OpenApiValidation        bool `env:"OPENAPI_VALIDATION" envDefault:"true"`
+	OpenApiValidateResponses bool `env:"OPENAPI_VALIDATE_RESPONSES" envDefault:"false"` // for tests; copies the JSON responses
 }
 
-func Load() (*config, error) {
-	cfg := config{}
+func Load() (*Config, error) {
+	cfg := Config{}
 	if err := env.Parse(&cfg); err != nil {
 		return nil, err
 	}
+	// either alone would silently serve plain HTTP
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, errors.New("TLS_CERT_FILE and TLS_KEY_FILE have to be set together")
+	}
 
 	return &cfg, nil
 }
@@ -0,0 +1,27 @@
+package env
+
+import "testing"
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		certFile string
+		keyFile  string
+		wantErr  bool
+	}{
+		{name: "Successfully load without TLS"},
+		{name: "Successfully load with TLS", certFile: "cert.pem", keyFile: "key.pem"},
+		{name: "Prevent a certificate without key", certFile: "cert.pem", wantErr: true},
+		{name: "Prevent a key without certificate", keyFile: "key.pem", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TLS_CERT_FILE", tt.certFile)
+			t.Setenv("TLS_KEY_FILE", tt.keyFile)
+
+			if _, err := Load(); (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Archiver moves expired articles with the archive policy to the archive collection and marks expired articles
+// with the hide policy as hidden, so both are announced as expired. Articles with the delete policy are removed
+// by the TTL index.
+type Archiver struct {
+	ArticleDbHandler db.ArticleDbHandlerInterface
+	AuditDbHandler   db.AuditDbHandlerInterface // records the archive moves; nil records nothing
+	Logger           *logrus.Logger
+	Now              func() time.Time // defaults to time.Now
+}
+
+// Run archives and hides every interval until the context is done
+func (a *Archiver) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.Archive(ctx); err != nil {
+			logging.OrDefault(a.Logger).WithError(err).Error("failed to archive expired articles")
+		}
+		if _, err := a.Hide(ctx); err != nil {
+			logging.OrDefault(a.Logger).WithError(err).Error("failed to hide expired articles")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Archive moves the expired articles once and returns the amount of archived articles
+func (a *Archiver) Archive(ctx context.Context) (int, error) {
+	archived, err := a.ArticleDbHandler.ArchiveExpired(ctx, a.now())
+	// also the articles archived before a failure
+	for _, article := range archived {
+		auditSystem(ctx, a.AuditDbHandler, db.AuditActionArchive, article)
+	}
+	if len(archived) > 0 {
+		logging.OrDefault(a.Logger).WithField("articles", len(archived)).Info("archived expired articles")
+	}
+	return len(archived), err
+}
+
+// Hide marks the expired articles with the hide policy once and returns the amount of hidden articles
+func (a *Archiver) Hide(ctx context.Context) (int64, error) {
+	hidden, err := a.ArticleDbHandler.HideExpired(ctx, a.now())
+	if hidden > 0 {
+		logging.OrDefault(a.Logger).WithField("articles", hidden).Info("hid expired articles")
+	}
+	return hidden, err
+}
+
+func (a *Archiver) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now()
+}
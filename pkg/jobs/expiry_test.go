@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"article-management-service/pkg/tenant"
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArchiver_Archive(t *testing.T) {
+	now := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	archivedId := primitive.NewObjectID()
+	var foundNow time.Time
+	var entries []db.AuditEntryDb
+	var entryTenants []string
+	a := &Archiver{
+		ArticleDbHandler: &mocks.MockArticleDbHandler{ArchiveExpiredFunc: func(ctx context.Context, now time.Time) ([]db.ArticleDb, error) {
+			foundNow = now
+			return []db.ArticleDb{{Id: archivedId, TenantId: "tenant-a", Title: "Expired"}, {Title: "Also expired"}}, nil
+		}},
+		AuditDbHandler: &mocks.MockAuditDbHandler{InsertOneFunc: func(ctx context.Context, new db.AuditEntryDb) error {
+			entries = append(entries, new)
+			entryTenants = append(entryTenants, tenant.FromContext(ctx))
+			return nil
+		}},
+		Now: func() time.Time { return now },
+	}
+
+	archived, err := a.Archive(context.Background())
+	if err != nil || archived != 2 {
+		t.Fatalf("Archiver.Archive() = %v, %v, want %v", archived, err, 2)
+	}
+	if !foundNow.Equal(now) {
+		t.Errorf("Archiver.Archive() now = %v, want %v", foundNow, now)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Archiver.Archive() audit entries = %v, want %v", len(entries), 2)
+	}
+	entry := entries[0]
+	if entry.Action != db.AuditActionArchive || entry.ActorId != db.AuditActorSystem || entry.ArticleId != archivedId || entry.Before == nil || entryTenants[0] != "tenant-a" {
+		t.Errorf("Archiver.Archive() audit entry = %+v in tenant %v", entry, entryTenants[0])
+	}
+}
+
+func TestArchiver_Hide(t *testing.T) {
+	now := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	var foundNow time.Time
+	a := &Archiver{
+		ArticleDbHandler: &mocks.MockArticleDbHandler{HideExpiredFunc: func(ctx context.Context, now time.Time) (int64, error) {
+			foundNow = now
+			return 2, nil
+		}},
+		Now: func() time.Time { return now },
+	}
+
+	hidden, err := a.Hide(context.Background())
+	if err != nil || hidden != 2 {
+		t.Fatalf("Archiver.Hide() = %v, %v, want %v", hidden, err, 2)
+	}
+	if !foundNow.Equal(now) {
+		t.Errorf("Archiver.Hide() now = %v, want %v", foundNow, now)
+	}
+}
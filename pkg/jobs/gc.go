@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNoReferencedImages is an image directory that holds none of the referenced images although there are some,
+// e.g. as it is not the directory of the server; nothing is removed
+var ErrNoReferencedImages = errors.New("the image directory holds none of the referenced images")
+
+// ImageCollector removes the images of the image directory that no article references anymore, e.g. the
+// images of failed uploads and imports
+type ImageCollector struct {
+	ArticleDbHandler db.ArticleDbHandlerInterface
+	ImageDirectory   string        // like the image directory of the server, as the paths of the articles start with it
+	MinAge           time.Duration // younger images are kept, as uploads save the image before they reference it
+	Logger           *logrus.Logger
+	Now              func() time.Time // defaults to time.Now
+}
+
+// Collect removes the orphaned images once and returns their paths; with dryRun they are only returned.
+// Images that can not be removed are logged and left out. Only files of the layout of the uploads,
+// <tenant>/<uuid> below the image directory, are removed; the paths are compared as absolute paths.
+func (c *ImageCollector) Collect(ctx context.Context, dryRun bool) ([]string, error) {
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+
+	referenced, err := c.ArticleDbHandler.FindAllImagePaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(referenced))
+	for _, path := range referenced {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		keep[abs] = true
+	}
+	directory, err := filepath.Abs(c.ImageDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	// the orphans are removed after the walk, once it is certain the directory holds the referenced images
+	candidates := make([]string, 0)
+	found := 0
+	err = filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isImagePath(directory, path) {
+			return nil
+		}
+		if keep[path] {
+			found++
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if now().Sub(info.ModTime()) < c.MinAge {
+			return nil
+		}
+		candidates = append(candidates, path)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		// nothing was uploaded yet
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(keep) > 0 && found == 0 && len(candidates) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoReferencedImages, directory)
+	}
+
+	logger := logging.OrDefault(c.Logger)
+	orphans := make([]string, 0, len(candidates))
+	for _, path := range candidates {
+		if !dryRun {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				logger.WithError(err).WithField("path", path).Error("failed to remove an orphaned image")
+				continue
+			}
+		}
+		orphans = append(orphans, path)
+	}
+
+	if len(orphans) > 0 && !dryRun {
+		logger.WithField("images", len(orphans)).Info("removed orphaned images")
+	}
+	return orphans, nil
+}
+
+// Whether the path is an upload: a file named by a uuid in the directory of a tenant
+func isImagePath(directory string, path string) bool {
+	rel, err := filepath.Rel(directory, path)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) != 2 || !tenant.IsValid(parts[0]) {
+		return false
+	}
+	_, err = uuid.Parse(parts[1])
+	return err == nil
+}
@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"article-management-service/pkg/mocks"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImageCollector_Collect(t *testing.T) {
+	now := time.Now()
+	directory := filepath.Join(t.TempDir(), "images")
+	if err := os.MkdirAll(filepath.Join(directory, "default"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	referenced := filepath.Join(directory, "default", "0b4e9a4e-3c1f-4a57-9a55-43bbd2b4d8a1")
+	orphaned := filepath.Join(directory, "default", "5f0c2e1d-8f6b-4c2a-b5a4-7d0e6f3c9b12")
+	uploading := filepath.Join(directory, "default", "9d7a3b2c-1e4f-4d6a-8b9c-0a1b2c3d4e5f")
+	// not uploads, so they are kept
+	foreign := []string{filepath.Join(directory, "default", "notes.txt"), filepath.Join(directory, "5f0c2e1d-8f6b-4c2a-b5a4-7d0e6f3c9b13")}
+	for _, path := range append([]string{referenced, orphaned, uploading}, foreign...) {
+		if err := os.WriteFile(path, []byte("image"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := now.Add(-2 * time.Hour)
+	for _, path := range append([]string{referenced, orphaned}, foreign...) {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		directory  string
+		referenced string
+		dryRun     bool
+		expected   []string
+		wantErr    error
+		removed    bool // the cases run in order, so the orphan stays removed
+	}{
+		{name: "Successfully find orphaned images in a dry run", directory: directory, referenced: referenced, dryRun: true, expected: []string{orphaned}},
+		{name: "Successfully collect without image directory", directory: filepath.Join(t.TempDir(), "missing"), referenced: referenced, expected: []string{}},
+		{name: "Prevent removing when no referenced image is found", directory: directory, referenced: filepath.Join(t.TempDir(), "default", "0b4e9a4e-3c1f-4a57-9a55-43bbd2b4d8a1"), wantErr: ErrNoReferencedImages},
+		{name: "Successfully remove orphaned images", directory: directory + "/default/..", referenced: directory + "/default/../default/0b4e9a4e-3c1f-4a57-9a55-43bbd2b4d8a1", expected: []string{orphaned}, removed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ImageCollector{
+				ArticleDbHandler: &mocks.MockArticleDbHandler{FindAllImagePathsFunc: func(ctx context.Context) ([]string, error) {
+					return []string{tt.referenced}, nil
+				}},
+				ImageDirectory: tt.directory,
+				MinAge:         time.Hour,
+				Now:            func() time.Time { return now },
+			}
+
+			orphans, err := c.Collect(context.Background(), tt.dryRun)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(orphans, tt.expected) {
+				t.Fatalf("ImageCollector.Collect() = %v, %v, want %v, %v", orphans, err, tt.expected, tt.wantErr)
+			}
+			if _, err := os.Stat(orphaned); os.IsNotExist(err) != tt.removed {
+				t.Errorf("ImageCollector.Collect() removed = %v, want %v", os.IsNotExist(err), tt.removed)
+			}
+			for _, path := range append([]string{referenced, uploading}, foreign...) {
+				if _, err := os.Stat(path); err != nil {
+					t.Errorf("ImageCollector.Collect() removed %v: %v", path, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Purger permanently removes the articles that are longer in the trash than the retention, including their images
+// and revisions
+type Purger struct {
+	ArticleDbHandler  db.ArticleDbHandlerInterface
+	RevisionDbHandler db.RevisionDbHandlerInterface // nil keeps the revisions
+	AuditDbHandler    db.AuditDbHandlerInterface    // records the purges; nil records nothing
+	Retention         time.Duration
+	Logger            *logrus.Logger
+	Now               func() time.Time // defaults to time.Now
+}
+
+// Run purges every interval until the context is done
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Purge(ctx); err != nil {
+			logging.OrDefault(p.Logger).WithError(err).Error("failed to purge the trash")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes the expired trash once and returns the amount of purged articles. Images and revisions that can
+// not be removed are logged; the articles are gone by then, so the job does not retry them.
+func (p *Purger) Purge(ctx context.Context) (int, error) {
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+
+	articles, err := p.ArticleDbHandler.PurgeTrash(ctx, now().Add(-p.Retention))
+	if err != nil {
+		return 0, err
+	}
+
+	logger := logging.OrDefault(p.Logger)
+	if p.RevisionDbHandler != nil && len(articles) > 0 {
+		ids := make([]primitive.ObjectID, 0, len(articles))
+		for _, article := range articles {
+			ids = append(ids, article.Id)
+		}
+		if _, err := p.RevisionDbHandler.DeleteByArticleIds(ctx, ids); err != nil {
+			logger.WithError(err).Error("failed to remove the revisions of purged articles")
+		}
+	}
+	for _, article := range articles {
+		for _, path := range article.ImageFilePaths {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				logger.WithError(err).WithField("path", path).Error("failed to remove the image of a purged article")
+			}
+		}
+		auditSystem(ctx, p.AuditDbHandler, db.AuditActionPurge, article)
+	}
+
+	if len(articles) > 0 {
+		logger.WithField("articles", len(articles)).Info("purged the trash")
+	}
+	return len(articles), nil
+}
+
+// Records the removal of the article by a job in the audit log of its tenant
+func auditSystem(ctx context.Context, auditDbHandler db.AuditDbHandlerInterface, action string, article db.ArticleDb) {
+	if auditDbHandler == nil {
+		return
+	}
+
+	// the jobs run across tenants
+	_ = auditDbHandler.InsertOne(tenant.WithTenant(ctx, article.TenantId), db.AuditEntryDb{
+		Time:      time.Now(),
+		ActorId:   db.AuditActorSystem,
+		Action:    action,
+		ArticleId: article.Id,
+		Before:    &article,
+	})
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPurger_Purge(t *testing.T) {
+	now := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+	image := filepath.Join(t.TempDir(), "image")
+	if err := os.WriteFile(image, []byte("image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var foundDeletedBefore time.Time
+	var entries []db.AuditEntryDb
+	var revised []primitive.ObjectID
+	purgedId := primitive.NewObjectID()
+	p := &Purger{
+		ArticleDbHandler: &mocks.MockArticleDbHandler{PurgeTrashFunc: func(ctx context.Context, deletedBefore time.Time) ([]db.ArticleDb, error) {
+			foundDeletedBefore = deletedBefore
+			return []db.ArticleDb{
+				{Id: purgedId, Title: "With image", ImageFilePaths: []string{image}},
+				{Title: "With missing image", ImageFilePaths: []string{filepath.Join(t.TempDir(), "missing")}},
+			}, nil
+		}},
+		RevisionDbHandler: &mocks.MockRevisionDbHandler{DeleteByArticleIdsFunc: func(ctx context.Context, articleIds []primitive.ObjectID) (int64, error) {
+			revised = articleIds
+			return int64(len(articleIds)), nil
+		}},
+		AuditDbHandler: &mocks.MockAuditDbHandler{InsertOneFunc: func(ctx context.Context, new db.AuditEntryDb) error {
+			entries = append(entries, new)
+			return nil
+		}},
+		Retention: 24 * time.Hour,
+		Now:       func() time.Time { return now },
+	}
+
+	purged, err := p.Purge(context.Background())
+	if err != nil || purged != 2 {
+		t.Fatalf("Purger.Purge() = %v, %v, want %v", purged, err, 2)
+	}
+	if want := now.Add(-24 * time.Hour); !foundDeletedBefore.Equal(want) {
+		t.Errorf("Purger.Purge() deletedBefore = %v, want %v", foundDeletedBefore, want)
+	}
+	if _, err := os.Stat(image); !os.IsNotExist(err) {
+		t.Errorf("Purger.Purge() did not remove the image, stat error = %v", err)
+	}
+	if len(revised) != 2 || revised[0] != purgedId {
+		t.Errorf("Purger.Purge() deleted the revisions of %v, want the purged articles", revised)
+	}
+	if len(entries) != 2 || entries[0].Action != db.AuditActionPurge || entries[0].ActorId != db.AuditActorSystem || entries[0].Before == nil || entries[0].Before.Title != "With image" {
+		t.Errorf("Purger.Purge() audit entries = %+v", entries)
+	}
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+type requestIdKey struct{}
+
+// New creates a structured logger with the given level (e.g. "debug", "info") and format ("json" or "logfmt").
+func New(level string, format string) (*logrus.Logger, error) {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(lvl)
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case FormatLogfmt:
+		logger.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return logger, nil
+}
+
+// OrDefault returns the given logger or the logrus standard logger when none was injected.
+func OrDefault(logger *logrus.Logger) *logrus.Logger {
+	if logger == nil {
+		return logrus.StandardLogger()
+	}
+	return logger
+}
+
+// WithRequestId stores the request id in the context so that every layer can annotate its log lines.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestId returns the request id stored in the context; empty if there is none
+func RequestId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
+
+// FromContext returns a log entry annotated with the request id and trace id of the context.
+func FromContext(ctx context.Context, logger *logrus.Logger) *logrus.Entry {
+	entry := logrus.NewEntry(OrDefault(logger))
+	if requestId := RequestId(ctx); requestId != "" {
+		entry = entry.WithField("request_id", requestId)
+	}
+	if ctx != nil {
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			entry = entry.WithFields(logrus.Fields{
+				"trace_id": spanContext.TraceID().String(),
+				"span_id":  spanContext.SpanID().String(),
+			})
+		}
+	}
+	return entry
+}
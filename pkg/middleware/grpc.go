@@ -0,0 +1,257 @@
+package middleware
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/ratelimit"
+	"article-management-service/pkg/tenant"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Grpc applies the gin middleware of the HTTP api to gRPC calls: request ids, tracing, access logs,
+// authentication, tenants and rate limits. The metadata keys are the header names of the HTTP api in
+// lower case, e.g. x-api-key, authorization and x-tenant-id.
+type Grpc struct {
+	Authenticator       *auth.Authenticator         // nil accepts no credentials
+	AuthDisabled        bool                        // marks the calls like auth.Disabled, so anonymous callers may act
+	Required            map[string]bool             // the full methods that reject anonymous calls, like auth.Required
+	TenantHeaderAllowed bool                        // lets identities that may switch tenants select one with x-tenant-id
+	Limiter             *ratelimit.Limiter          // nil does not limit the calls
+	Budgets             map[string]ratelimit.Budget // the budget of the full methods, like the kinds of the routes
+	Logger              *logrus.Logger
+}
+
+// Unary returns the interceptor of calls with one request
+func (g *Grpc) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, done := g.start(ctx, info.FullMethod)
+		ctx, err := g.identify(ctx, info.FullMethod)
+		if err == nil {
+			err = g.limit(ctx, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+		}
+		if err != nil {
+			done(err)
+			return nil, err
+		}
+
+		response, err := handler(ctx, request)
+		done(err)
+		return response, err
+	}
+}
+
+// Stream returns the interceptor of streaming calls
+func (g *Grpc) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := g.start(stream.Context(), info.FullMethod)
+		ctx, err := g.identify(ctx, info.FullMethod)
+		if err == nil {
+			err = g.limit(ctx, info.FullMethod, stream.SetHeader)
+		}
+		if err == nil {
+			err = handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+		}
+		done(err)
+		return err
+	}
+}
+
+// Assigns the request id and starts the server span of the call, like RequestId and Tracing; done ends
+// the span and writes the access log line, like Logger
+func (g *Grpc) start(ctx context.Context, method string) (context.Context, func(err error)) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	start := time.Now()
+
+	requestId := firstValue(md, HeaderRequestId)
+	if !isValidRequestId(requestId) {
+		requestId = uuid.New().String()
+	}
+	ctx = logging.WithRequestId(ctx, requestId)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(HeaderRequestId, requestId))
+
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(name), attribute.String("rpc.request_id", requestId)),
+	)
+
+	return ctx, func(err error) {
+		code := status.Code(err)
+		span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+		if isServerError(code) {
+			span.SetStatus(otelcodes.Error, code.String())
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+
+		entry := logging.FromContext(ctx, g.Logger).WithFields(logrus.Fields{
+			"method":     method,
+			"code":       code.String(),
+			"latency_ms": time.Since(start).Milliseconds(),
+		})
+		switch {
+		case isServerError(code):
+			entry.Error("call completed")
+		case code != codes.OK:
+			entry.Warn("call completed")
+		default:
+			entry.Info("call completed")
+		}
+	}
+}
+
+// Resolves the identity and the tenant of the call, like auth.Authenticate, auth.Required and Tenant
+func (g *Grpc) identify(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if g.Authenticator != nil {
+		identity, err := g.Authenticator.Identify(ctx, firstValue(md, auth.HeaderApiKey), firstValue(md, auth.HeaderAuthorization))
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidCredentials) {
+				logging.FromContext(ctx, g.Logger).WithError(err).Error("failed to authenticate")
+				return ctx, status.Error(codes.Internal, "failed to authenticate")
+			}
+			logging.FromContext(ctx, g.Logger).Info("rejected invalid credentials")
+			// guessing credentials is charged to the ip, like auth.Authenticator.OnInvalidCredentials
+			if limitErr := g.take(ctx, method, ratelimit.IpKey(peerIp(ctx)), nil); limitErr != nil {
+				return ctx, limitErr
+			}
+			return ctx, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if identity != nil {
+			ctx = auth.WithIdentity(ctx, identity)
+		}
+	}
+
+	if g.AuthDisabled {
+		ctx = auth.WithAuthenticationDisabled(ctx)
+	}
+	identity := auth.FromContext(ctx)
+	if identity == nil && g.Required[method] {
+		return ctx, status.Error(codes.Unauthenticated, "credentials are required")
+	}
+
+	identityTenant := ""
+	if identity != nil {
+		identityTenant = identity.Tenant
+	}
+	tenantId, err := tenant.Resolve(identityTenant, firstValue(md, HeaderTenantId), maySwitchTenant(g.TenantHeaderAllowed, identity))
+	if err != nil {
+		if errors.Is(err, tenant.ErrInvalidIdentity) {
+			return ctx, status.Error(codes.PermissionDenied, err.Error())
+		}
+		if errors.Is(err, tenant.ErrInvalid) {
+			return ctx, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return ctx, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if tenantId != "" {
+		ctx = tenant.WithTenant(ctx, tenantId)
+	}
+	return ctx, nil
+}
+
+// Takes a token of the budget of the method for the client of the call, like ratelimit.Limiter.Middleware;
+// a spent budget fails with ResourceExhausted and a retry-after header
+func (g *Grpc) limit(ctx context.Context, method string, setHeader func(md metadata.MD) error) error {
+	if g.Limiter == nil {
+		return nil
+	}
+	return g.take(ctx, method, g.Limiter.ClientKey(ctx, peerIp(ctx)), setHeader)
+}
+
+func (g *Grpc) take(ctx context.Context, method string, clientKey string, setHeader func(md metadata.MD) error) error {
+	budget, ok := g.Budgets[method]
+	if g.Limiter == nil || !ok {
+		return nil
+	}
+
+	allowed, retryAfter := g.Limiter.Allow(ctx, budget, clientKey)
+	if allowed {
+		return nil
+	}
+	if setHeader != nil {
+		_ = setHeader(metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+	}
+	return status.Error(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded, retry after %d seconds", retryAfter))
+}
+
+// The ip of the client of the call; empty when unknown
+func peerIp(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// The codes that are the fault of the server, like a 5xx status
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// serverStream passes the context of the interceptor to the handler of a streaming call
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier reads the trace context of the traceparent and tracestate metadata
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	return firstValue(metadata.MD(c), key)
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/ratelimit"
+	"article-management-service/pkg/tenant"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpc_Unary(t *testing.T) {
+	const method = "/article.v1.ArticleService/CreateArticle"
+	authenticator := &auth.Authenticator{JWT: &auth.JWTConfig{HMACSecret: []byte("secret")}}
+	adminToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "admin-1", "exp": time.Now().Add(time.Hour).Unix(), "roles": []string{"admin"},
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	badTenantToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "editor-1", "exp": time.Now().Add(time.Hour).Unix(), "tenant": "../../etc",
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name              string
+		authenticator     *auth.Authenticator
+		required          bool
+		metadata          metadata.MD
+		headerAllowed     bool
+		authDisabled      bool
+		expectedCode      codes.Code
+		expectedTenant    string
+		expectedRequestId string
+	}{
+		{name: "Successfully pass anonymous calls", expectedCode: codes.OK, expectedTenant: tenant.Default},
+		{name: "Successfully mark calls without authentication", authDisabled: true, expectedCode: codes.OK, expectedTenant: tenant.Default},
+		{name: "Propagate request id", metadata: metadata.Pairs("x-request-id", "abc-123"), expectedCode: codes.OK, expectedTenant: tenant.Default, expectedRequestId: "abc-123"},
+		{name: "Tenant from metadata", authenticator: authenticator, metadata: metadata.Pairs("x-tenant-id", "team-a", "authorization", "Bearer "+adminToken), headerAllowed: true, expectedCode: codes.OK, expectedTenant: "team-a"},
+		{name: "Prevent tenant when not allowed", authenticator: authenticator, metadata: metadata.Pairs("x-tenant-id", "team-a", "authorization", "Bearer "+adminToken), expectedCode: codes.PermissionDenied},
+		{name: "Prevent tenant of anonymous calls", metadata: metadata.Pairs("x-tenant-id", "team-a"), headerAllowed: true, expectedCode: codes.PermissionDenied},
+		{name: "Prevent invalid tenant", authenticator: authenticator, metadata: metadata.Pairs("x-tenant-id", "../team-a", "authorization", "Bearer "+adminToken), headerAllowed: true, expectedCode: codes.InvalidArgument},
+		{name: "Prevent invalid tenant claims", authenticator: authenticator, metadata: metadata.Pairs("authorization", "Bearer "+badTenantToken), expectedCode: codes.PermissionDenied},
+		{name: "Prevent anonymous calls of required methods", authenticator: authenticator, required: true, expectedCode: codes.Unauthenticated},
+		{name: "Prevent invalid credentials", authenticator: authenticator, metadata: metadata.Pairs("authorization", "Bearer invalid"), expectedCode: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Grpc{Authenticator: tt.authenticator, AuthDisabled: tt.authDisabled, Required: map[string]bool{method: tt.required}, TenantHeaderAllowed: tt.headerAllowed}
+
+			var foundTenant, foundRequestId string
+			var foundDisabled bool
+			handler := func(ctx context.Context, request interface{}) (interface{}, error) {
+				foundTenant = tenant.FromContext(ctx)
+				foundDisabled = auth.AuthenticationDisabled(ctx)
+				foundRequestId = logging.RequestId(ctx)
+				return nil, nil
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), tt.metadata)
+			_, err := g.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("Unary() code = %v, want %v (%v)", code, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if foundTenant != tt.expectedTenant {
+				t.Errorf("Unary() tenant = %v, want %v", foundTenant, tt.expectedTenant)
+			}
+			if foundDisabled != tt.authDisabled {
+				t.Errorf("Unary() authentication disabled = %v, want %v", foundDisabled, tt.authDisabled)
+			}
+			if foundRequestId == "" || (tt.expectedRequestId != "" && foundRequestId != tt.expectedRequestId) {
+				t.Errorf("Unary() request id = %q, want %q", foundRequestId, tt.expectedRequestId)
+			}
+		})
+	}
+}
+
+func TestGrpc_Unary_RateLimit(t *testing.T) {
+	const method = "/article.v1.ArticleService/GetArticle"
+	now := time.Now()
+	newGrpc := func() *Grpc {
+		return &Grpc{
+			Authenticator: &auth.Authenticator{JWT: &auth.JWTConfig{HMACSecret: []byte("secret")}},
+			Limiter:       &ratelimit.Limiter{Store: &ratelimit.MemoryStore{}, KeyBy: ratelimit.KeyByIp, Now: func() time.Time { return now }},
+			Budgets:       map[string]ratelimit.Budget{method: {Name: "read", PerMinute: 6, Burst: 1}},
+		}
+	}
+	call := func(g *Grpc, ip string, md metadata.MD) codes.Code {
+		ctx := peer.NewContext(metadata.NewIncomingContext(context.Background(), md), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 5000}})
+		_, err := g.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, request interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		return status.Code(err)
+	}
+
+	t.Run("Successfully limit the calls of a client", func(t *testing.T) {
+		g := newGrpc()
+		if code := call(g, "10.0.0.1", nil); code != codes.OK {
+			t.Errorf("Unary() code = %v, want %v", code, codes.OK)
+		}
+		if code := call(g, "10.0.0.1", nil); code != codes.ResourceExhausted {
+			t.Errorf("Unary() code = %v, want %v", code, codes.ResourceExhausted)
+		}
+		if code := call(g, "10.0.0.2", nil); code != codes.OK {
+			t.Errorf("Unary() code of another client = %v, want %v", code, codes.OK)
+		}
+	})
+
+	t.Run("Successfully charge invalid credentials to the ip", func(t *testing.T) {
+		g := newGrpc()
+		invalid := metadata.Pairs("authorization", "Bearer invalid")
+		if code := call(g, "10.0.0.1", invalid); code != codes.Unauthenticated {
+			t.Errorf("Unary() code = %v, want %v", code, codes.Unauthenticated)
+		}
+		if code := call(g, "10.0.0.1", invalid); code != codes.ResourceExhausted {
+			t.Errorf("Unary() code = %v, want %v", code, codes.ResourceExhausted)
+		}
+		if code := call(g, "10.0.0.1", nil); code != codes.ResourceExhausted {
+			t.Errorf("Unary() code of anonymous calls = %v, want %v", code, codes.ResourceExhausted)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"article-management-service/pkg/logging"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Logger replaces the default gin logger with one structured access log line per request.
+// It should be registered after RequestId so the line carries the request id.
+func Logger(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		entry := logging.FromContext(c.Request.Context(), logger).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"path":       path,
+			"route":      c.FullPath(),
+			"status":     c.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  c.ClientIP(),
+			"size":       c.Writer.Size(),
+		})
+
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("errors", c.Errors.String())
+		}
+
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			entry.Error("request completed")
+		case status >= 400:
+			entry.Warn("request completed")
+		default:
+			entry.Info("request completed")
+		}
+	}
+}
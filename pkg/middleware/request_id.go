@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"article-management-service/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const HeaderRequestId = "X-Request-ID"
+
+// longer ids are replaced, so a client can not flood the logs through the header
+const maxRequestIdLength = 128
+
+// RequestId assigns every request an id; an incoming X-Request-ID is propagated as is.
+// The id is stored in the request context and echoed in the response header.
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(HeaderRequestId)
+		if !isValidRequestId(requestId) {
+			requestId = uuid.New().String()
+		}
+
+		c.Request = c.Request.WithContext(logging.WithRequestId(c.Request.Context(), requestId))
+		c.Header(HeaderRequestId, requestId)
+
+		c.Next()
+	}
+}
+
+func isValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+
+	// only printable ascii, so the id can be safely written to logs and headers
+	for _, r := range requestId {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"article-management-service/pkg/logging"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		incoming   string
+		expectSame bool
+	}{
+		{name: "generate id when missing", incoming: "", expectSame: false},
+		{name: "propagate incoming id", incoming: "gateway-1234", expectSame: true},
+		{name: "replace id with whitespace", incoming: "bad id", expectSame: false},
+		{name: "replace too long id", incoming: strings.Repeat("a", maxRequestIdLength+1), expectSame: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(RequestId())
+
+			var fromContext string
+			engine.GET("/", func(c *gin.Context) {
+				fromContext = logging.RequestId(c.Request.Context())
+			})
+
+			req, _ := http.NewRequest("GET", "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(HeaderRequestId, tt.incoming)
+			}
+			response := httptest.NewRecorder()
+			engine.ServeHTTP(response, req)
+
+			responseId := response.Header().Get(HeaderRequestId)
+			if responseId == "" {
+				t.Errorf("RequestId() response header is empty")
+				return
+			}
+
+			if responseId != fromContext {
+				t.Errorf("RequestId() context = %v, want %v", fromContext, responseId)
+				return
+			}
+
+			if (responseId == tt.incoming) != tt.expectSame {
+				t.Errorf("RequestId() = %v, incoming %v, expect same %v", responseId, tt.incoming, tt.expectSame)
+			}
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Access log line carries the request id", func(t *testing.T) {
+		logger, err := logging.New("info", logging.FormatJSON)
+		if err != nil {
+			t.Fatalf("logging.New() error = %v", err)
+		}
+		out := &bytes.Buffer{}
+		logger.SetOutput(out)
+
+		engine := gin.New()
+		engine.Use(RequestId(), Logger(logger))
+		engine.GET("/article", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req, _ := http.NewRequest("GET", "/article", nil)
+		req.Header.Set(HeaderRequestId, "req-1")
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+
+		var line map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+			t.Fatalf("Failed to parse log line %q: %v", out.String(), err)
+		}
+
+		if line["request_id"] != "req-1" || line["route"] != "/article" || line["status"] != float64(http.StatusOK) {
+			t.Errorf("Logger() = %v", line)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/tenant"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const HeaderTenantId = "X-Tenant-ID"
+
+// Tenant resolves the tenant of the request and stores it in the request context. The tenant of the
+// authenticated identity wins; the X-Tenant-ID header is only used when allowed and may not contradict
+// the identity. Requests without a tenant belong to tenant.Default.
+// It should be registered after the authentication middleware.
+func Tenant(headerAllowed bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity := auth.FromContext(c.Request.Context())
+		identityTenant := ""
+		if identity != nil {
+			identityTenant = identity.Tenant
+		}
+
+		tenantId, err := tenant.Resolve(identityTenant, c.GetHeader(HeaderTenantId), maySwitchTenant(headerAllowed, identity))
+		if err != nil {
+			// the identity is not the caller's to fix
+			if errors.Is(err, tenant.ErrInvalidIdentity) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			if errors.Is(err, tenant.ErrInvalid) {
+				c.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if tenantId != "" {
+			c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), tenantId))
+		}
+		c.Next()
+	}
+}
+
+// Reports whether the identity may select the tenant with the X-Tenant-ID header; only authenticated
+// identities that are allowed to switch tenants may, anonymous callers never
+func maySwitchTenant(headerAllowed bool, identity *auth.Identity) bool {
+	return headerAllowed && identity != nil && authz.Allowed(true, identity, authz.ActionSwitchTenant, "")
+}
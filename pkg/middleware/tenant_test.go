@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/tenant"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := &auth.Identity{Subject: "admin", Roles: []string{authz.RoleAdmin}}
+	editor := &auth.Identity{Subject: "editor", Roles: []string{authz.RoleEditor}}
+
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		header         string
+		headerAllowed  bool
+		expectedStatus int
+		expectedTenant string
+	}{
+		{name: "Default tenant for anonymous requests", expectedStatus: http.StatusOK, expectedTenant: tenant.Default},
+		{name: "Tenant from header", identity: admin, header: "team-a", headerAllowed: true, expectedStatus: http.StatusOK, expectedTenant: "team-a"},
+		{name: "Prevent header when not allowed", identity: admin, header: "team-a", headerAllowed: false, expectedStatus: http.StatusForbidden},
+		{name: "Prevent header for anonymous requests", header: "team-a", headerAllowed: true, expectedStatus: http.StatusForbidden},
+		{name: "Prevent header for identities that may not switch tenants", identity: editor, header: "team-a", headerAllowed: true, expectedStatus: http.StatusForbidden},
+		{name: "Prevent invalid tenant", identity: admin, header: "../team-a", headerAllowed: true, expectedStatus: http.StatusBadRequest},
+		{name: "Tenant from identity", identity: &auth.Identity{Subject: "editor", Tenant: "team-b"}, expectedStatus: http.StatusOK, expectedTenant: "team-b"},
+		{name: "Prevent header contradicting the identity", identity: &auth.Identity{Subject: "editor", Tenant: "team-b"}, header: "team-a", headerAllowed: true, expectedStatus: http.StatusForbidden},
+		{name: "Prevent invalid tenant of the identity", identity: &auth.Identity{Subject: "editor", Tenant: "../../etc"}, expectedStatus: http.StatusForbidden},
+		{name: "Header matching the identity", identity: &auth.Identity{Subject: "editor", Tenant: "team-b"}, header: "team-b", expectedStatus: http.StatusOK, expectedTenant: "team-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) {
+				if tt.identity != nil {
+					c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), tt.identity))
+				}
+			}, Tenant(tt.headerAllowed))
+
+			var found string
+			engine.GET("/article", func(c *gin.Context) {
+				found = tenant.FromContext(c.Request.Context())
+			})
+
+			req, _ := http.NewRequest("GET", "/article", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderTenantId, tt.header)
+			}
+			response := httptest.NewRecorder()
+			engine.ServeHTTP(response, req)
+
+			if response.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, response.Code)
+				return
+			}
+			if found != tt.expectedTenant {
+				t.Errorf("Tenant() = %v, want %v", found, tt.expectedTenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	engine := gin.New()
+	engine.Use(Tracing())
+
+	var childSpanContext trace.SpanContext
+	engine.GET("/article", func(c *gin.Context) {
+		_, span := otel.Tracer("test").Start(c.Request.Context(), "child")
+		childSpanContext = span.SpanContext()
+		span.End()
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("Continue the trace of an incoming traceparent", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/article", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		server := spans[len(spans)-1]
+
+		if server.Name() != "GET /article" {
+			t.Errorf("Tracing() span name = %v, want %v", server.Name(), "GET /article")
+		}
+
+		if server.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("Tracing() trace id = %v, want the incoming trace id", server.SpanContext().TraceID())
+		}
+
+		if server.Parent().SpanID().String() != "00f067aa0ba902b7" {
+			t.Errorf("Tracing() parent span = %v, want the incoming span", server.Parent().SpanID())
+		}
+
+		if childSpanContext.TraceID() != server.SpanContext().TraceID() {
+			t.Errorf("Tracing() handler span is not part of the request trace")
+		}
+	})
+
+	t.Run("Start a new trace without traceparent", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/article", nil)
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		server := spans[len(spans)-1]
+
+		if !server.SpanContext().IsValid() || server.Parent().IsValid() {
+			t.Errorf("Tracing() expected a new root span, got parent %v", server.Parent())
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"article-management-service/pkg/db"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockApiKeyDbHandler struct {
+	NewFunc           func(database *mongo.Database) error
+	InsertOneFunc     func(ctx context.Context, new db.ApiKeyDb) (primitive.ObjectID, error)
+	FindOneByHashFunc func(ctx context.Context, hash string) (*db.ApiKeyDb, error)
+}
+
+func (m *MockApiKeyDbHandler) New(database *mongo.Database) error {
+	if m.NewFunc != nil {
+		return m.NewFunc(database)
+	}
+	return nil
+}
+
+func (m *MockApiKeyDbHandler) InsertOne(ctx context.Context, new db.ApiKeyDb) (primitive.ObjectID, error) {
+	if m.InsertOneFunc != nil {
+		return m.InsertOneFunc(ctx, new)
+	}
+	return primitive.NilObjectID, nil
+}
+
+func (m *MockApiKeyDbHandler) FindOneByHash(ctx context.Context, hash string) (*db.ApiKeyDb, error) {
+	if m.FindOneByHashFunc != nil {
+		return m.FindOneByHashFunc(ctx, hash)
+	}
+	return nil, nil
+}
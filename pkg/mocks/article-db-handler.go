@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"article-management-service/pkg/db"
+	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -9,11 +11,30 @@ import (
 
 type MockArticleDbHandler struct {
 	NewFunc                  func(database *mongo.Database) error
-	InsertOneFunc            func(new db.ArticleDb) (primitive.ObjectID, error)
-	AppendImageFunc          func(id primitive.ObjectID, path string) error
-	FindOneByIdFunc          func(id primitive.ObjectID) (*db.ArticleDb, error)
-	FindAllTitlesFunc        func() ([]string, error)
-	FindTitlesByHasImageFunc func(withImage bool) ([]string, error)
+	InsertOneFunc            func(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error)
+	InsertManyFunc           func(ctx context.Context, new []db.ArticleDb) ([]primitive.ObjectID, error)
+	AppendImageFunc          func(ctx context.Context, id primitive.ObjectID, path string) (bool, error)
+	UpdateOneFunc            func(ctx context.Context, id primitive.ObjectID, update db.ArticleDb) (bool, error)
+	RenewFunc                func(ctx context.Context, id primitive.ObjectID, expirationDate time.Time, maxRenewals int) (bool, error)
+	FindOneByIdFunc          func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error)
+	FindAllTitlesFunc        func(ctx context.Context) ([]string, error)
+	FindTitlesByHasImageFunc func(ctx context.Context, withImage bool) ([]string, error)
+	ExportFunc               func(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error
+	FindPageFunc             func(ctx context.Context, filter db.ExportFilter, after primitive.ObjectID, limit int64) ([]db.ArticleDb, error)
+	CountArticlesFunc        func(ctx context.Context) (int64, error)
+	CountImagesFunc          func(ctx context.Context) (int64, error)
+	FindAllImagePathsFunc    func(ctx context.Context) ([]string, error)
+	SoftDeleteFunc           func(ctx context.Context, id primitive.ObjectID) (bool, error)
+	RestoreFunc              func(ctx context.Context, id primitive.ObjectID) (bool, error)
+	FindOneInTrashByIdFunc   func(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error)
+	FindTrashFunc            func(ctx context.Context) ([]db.ArticleDb, error)
+	PurgeTrashFunc           func(ctx context.Context, deletedBefore time.Time) ([]db.ArticleDb, error)
+	ArchiveExpiredFunc       func(ctx context.Context, now time.Time) ([]db.ArticleDb, error)
+	HideExpiredFunc          func(ctx context.Context, now time.Time) (int64, error)
+	BackfillExpiryPolicyFunc func(ctx context.Context, policy string) (int64, error)
+	EnableChangeEventsFunc   func(ctx context.Context) error
+	WatchFunc                func(ctx context.Context, resumeAfter string) (<-chan db.ArticleEvent, error)
+	WatchAllTenantsFunc      func(ctx context.Context, resumeAfter string) (<-chan db.ArticleEvent, error)
 }
 
 func (m *MockArticleDbHandler) New(database *mongo.Database) error {
@@ -23,37 +44,170 @@ func (m *MockArticleDbHandler) New(database *mongo.Database) error {
 	return nil
 }
 
-func (m *MockArticleDbHandler) InsertOne(new db.ArticleDb) (primitive.ObjectID, error) {
+func (m *MockArticleDbHandler) InsertOne(ctx context.Context, new db.ArticleDb) (primitive.ObjectID, error) {
 	if m.InsertOneFunc != nil {
-		return m.InsertOneFunc(new)
+		return m.InsertOneFunc(ctx, new)
 	}
 	return primitive.NilObjectID, nil
 }
 
-func (m *MockArticleDbHandler) AppendImage(id primitive.ObjectID, path string) error {
+func (m *MockArticleDbHandler) InsertMany(ctx context.Context, new []db.ArticleDb) ([]primitive.ObjectID, error) {
+	if m.InsertManyFunc != nil {
+		return m.InsertManyFunc(ctx, new)
+	}
+	return make([]primitive.ObjectID, len(new)), nil
+}
+
+func (m *MockArticleDbHandler) AppendImage(ctx context.Context, id primitive.ObjectID, path string) (bool, error) {
 	if m.AppendImageFunc != nil {
-		return m.AppendImageFunc(id, path)
+		return m.AppendImageFunc(ctx, id, path)
 	}
-	return nil
+	return true, nil
 }
 
-func (m *MockArticleDbHandler) FindOneById(id primitive.ObjectID) (*db.ArticleDb, error) {
+func (m *MockArticleDbHandler) FindOneById(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
 	if m.FindOneByIdFunc != nil {
-		return m.FindOneByIdFunc(id)
+		return m.FindOneByIdFunc(ctx, id)
 	}
 	return nil, nil
 }
 
-func (m *MockArticleDbHandler) FindAllTitles() ([]string, error) {
+func (m *MockArticleDbHandler) FindAllTitles(ctx context.Context) ([]string, error) {
 	if m.FindAllTitlesFunc != nil {
-		return m.FindAllTitlesFunc()
+		return m.FindAllTitlesFunc(ctx)
 	}
 	return nil, nil
 }
 
-func (m *MockArticleDbHandler) FindTitlesByHasImage(withImage bool) ([]string, error) {
+func (m *MockArticleDbHandler) FindTitlesByHasImage(ctx context.Context, withImage bool) ([]string, error) {
 	if m.FindTitlesByHasImageFunc != nil {
-		return m.FindTitlesByHasImageFunc(withImage)
+		return m.FindTitlesByHasImageFunc(ctx, withImage)
+	}
+	return nil, nil
+}
+
+func (m *MockArticleDbHandler) Export(ctx context.Context, filter db.ExportFilter, handle func(article db.ArticleDb) error) error {
+	if m.ExportFunc != nil {
+		return m.ExportFunc(ctx, filter, handle)
+	}
+	return nil
+}
+
+func (m *MockArticleDbHandler) FindPage(ctx context.Context, filter db.ExportFilter, after primitive.ObjectID, limit int64) ([]db.ArticleDb, error) {
+	if m.FindPageFunc != nil {
+		return m.FindPageFunc(ctx, filter, after, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockArticleDbHandler) CountArticles(ctx context.Context) (int64, error) {
+	if m.CountArticlesFunc != nil {
+		return m.CountArticlesFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *MockArticleDbHandler) CountImages(ctx context.Context) (int64, error) {
+	if m.CountImagesFunc != nil {
+		return m.CountImagesFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *MockArticleDbHandler) FindAllImagePaths(ctx context.Context) ([]string, error) {
+	if m.FindAllImagePathsFunc != nil {
+		return m.FindAllImagePathsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockArticleDbHandler) SoftDelete(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	if m.SoftDeleteFunc != nil {
+		return m.SoftDeleteFunc(ctx, id)
+	}
+	return false, nil
+}
+
+func (m *MockArticleDbHandler) Restore(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	if m.RestoreFunc != nil {
+		return m.RestoreFunc(ctx, id)
+	}
+	return false, nil
+}
+
+func (m *MockArticleDbHandler) FindOneInTrashById(ctx context.Context, id primitive.ObjectID) (*db.ArticleDb, error) {
+	if m.FindOneInTrashByIdFunc != nil {
+		return m.FindOneInTrashByIdFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockArticleDbHandler) FindTrash(ctx context.Context) ([]db.ArticleDb, error) {
+	if m.FindTrashFunc != nil {
+		return m.FindTrashFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockArticleDbHandler) PurgeTrash(ctx context.Context, deletedBefore time.Time) ([]db.ArticleDb, error) {
+	if m.PurgeTrashFunc != nil {
+		return m.PurgeTrashFunc(ctx, deletedBefore)
+	}
+	return nil, nil
+}
+
+func (m *MockArticleDbHandler) UpdateOne(ctx context.Context, id primitive.ObjectID, update db.ArticleDb) (bool, error) {
+	if m.UpdateOneFunc != nil {
+		return m.UpdateOneFunc(ctx, id, update)
+	}
+	return false, nil
+}
+
+func (m *MockArticleDbHandler) ArchiveExpired(ctx context.Context, now time.Time) ([]db.ArticleDb, error) {
+	if m.ArchiveExpiredFunc != nil {
+		return m.ArchiveExpiredFunc(ctx, now)
+	}
+	return nil, nil
+}
+
+func (m *MockArticleDbHandler) HideExpired(ctx context.Context, now time.Time) (int64, error) {
+	if m.HideExpiredFunc != nil {
+		return m.HideExpiredFunc(ctx, now)
+	}
+	return 0, nil
+}
+
+func (m *MockArticleDbHandler) BackfillExpiryPolicy(ctx context.Context, policy string) (int64, error) {
+	if m.BackfillExpiryPolicyFunc != nil {
+		return m.BackfillExpiryPolicyFunc(ctx, policy)
+	}
+	return 0, nil
+}
+
+func (m *MockArticleDbHandler) Renew(ctx context.Context, id primitive.ObjectID, expirationDate time.Time, maxRenewals int) (bool, error) {
+	if m.RenewFunc != nil {
+		return m.RenewFunc(ctx, id, expirationDate, maxRenewals)
+	}
+	return false, nil
+}
+
+func (m *MockArticleDbHandler) EnableChangeEvents(ctx context.Context) error {
+	if m.EnableChangeEventsFunc != nil {
+		return m.EnableChangeEventsFunc(ctx)
+	}
+	return nil
+}
+
+func (m *MockArticleDbHandler) Watch(ctx context.Context, resumeAfter string) (<-chan db.ArticleEvent, error) {
+	if m.WatchFunc != nil {
+		return m.WatchFunc(ctx, resumeAfter)
+	}
+	return nil, nil
+}
+
+func (m *MockArticleDbHandler) WatchAllTenants(ctx context.Context, resumeAfter string) (<-chan db.ArticleEvent, error) {
+	if m.WatchAllTenantsFunc != nil {
+		return m.WatchAllTenantsFunc(ctx, resumeAfter)
 	}
 	return nil, nil
 }
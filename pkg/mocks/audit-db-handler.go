@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"article-management-service/pkg/db"
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockAuditDbHandler struct {
+	NewFunc       func(database *mongo.Database) error
+	InsertOneFunc func(ctx context.Context, new db.AuditEntryDb) error
+	FindFunc      func(ctx context.Context, filter db.AuditFilter) ([]db.AuditEntryDb, error)
+}
+
+func (m *MockAuditDbHandler) New(database *mongo.Database) error {
+	if m.NewFunc != nil {
+		return m.NewFunc(database)
+	}
+	return nil
+}
+
+func (m *MockAuditDbHandler) InsertOne(ctx context.Context, new db.AuditEntryDb) error {
+	if m.InsertOneFunc != nil {
+		return m.InsertOneFunc(ctx, new)
+	}
+	return nil
+}
+
+func (m *MockAuditDbHandler) Find(ctx context.Context, filter db.AuditFilter) ([]db.AuditEntryDb, error) {
+	if m.FindFunc != nil {
+		return m.FindFunc(ctx, filter)
+	}
+	return []db.AuditEntryDb{}, nil
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"article-management-service/pkg/db"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockRevisionDbHandler struct {
+	NewFunc                func(database *mongo.Database) error
+	InsertOneFunc          func(ctx context.Context, new db.RevisionDb) (int, error)
+	FindByArticleIdFunc    func(ctx context.Context, articleId primitive.ObjectID) ([]db.RevisionDb, error)
+	FindOneFunc            func(ctx context.Context, articleId primitive.ObjectID, number int) (*db.RevisionDb, error)
+	DeleteByArticleIdsFunc func(ctx context.Context, articleIds []primitive.ObjectID) (int64, error)
+}
+
+func (m *MockRevisionDbHandler) New(database *mongo.Database) error {
+	if m.NewFunc != nil {
+		return m.NewFunc(database)
+	}
+	return nil
+}
+
+func (m *MockRevisionDbHandler) InsertOne(ctx context.Context, new db.RevisionDb) (int, error) {
+	if m.InsertOneFunc != nil {
+		return m.InsertOneFunc(ctx, new)
+	}
+	return 0, nil
+}
+
+func (m *MockRevisionDbHandler) FindByArticleId(ctx context.Context, articleId primitive.ObjectID) ([]db.RevisionDb, error) {
+	if m.FindByArticleIdFunc != nil {
+		return m.FindByArticleIdFunc(ctx, articleId)
+	}
+	return nil, nil
+}
+
+func (m *MockRevisionDbHandler) FindOne(ctx context.Context, articleId primitive.ObjectID, number int) (*db.RevisionDb, error) {
+	if m.FindOneFunc != nil {
+		return m.FindOneFunc(ctx, articleId, number)
+	}
+	return nil, nil
+}
+
+func (m *MockRevisionDbHandler) DeleteByArticleIds(ctx context.Context, articleIds []primitive.ObjectID) (int64, error) {
+	if m.DeleteByArticleIdsFunc != nil {
+		return m.DeleteByArticleIdsFunc(ctx, articleIds)
+	}
+	return 0, nil
+}
@@ -0,0 +1,76 @@
+package mocks
+
+import (
+	"article-management-service/pkg/db"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockWebhookDbHandler struct {
+	NewFunc             func(database *mongo.Database) error
+	InsertOneFunc       func(ctx context.Context, new db.WebhookDb) (primitive.ObjectID, error)
+	FindAllFunc         func(ctx context.Context) ([]db.WebhookDb, error)
+	FindOneByIdFunc     func(ctx context.Context, id primitive.ObjectID) (*db.WebhookDb, error)
+	FindByEventFunc     func(ctx context.Context, eventType string) ([]db.WebhookDb, error)
+	DeleteOneFunc       func(ctx context.Context, id primitive.ObjectID) (bool, error)
+	LoadResumeTokenFunc func(ctx context.Context) (string, error)
+	SaveResumeTokenFunc func(ctx context.Context, token string) error
+}
+
+func (m *MockWebhookDbHandler) New(database *mongo.Database) error {
+	if m.NewFunc != nil {
+		return m.NewFunc(database)
+	}
+	return nil
+}
+
+func (m *MockWebhookDbHandler) InsertOne(ctx context.Context, new db.WebhookDb) (primitive.ObjectID, error) {
+	if m.InsertOneFunc != nil {
+		return m.InsertOneFunc(ctx, new)
+	}
+	return primitive.NilObjectID, nil
+}
+
+func (m *MockWebhookDbHandler) FindAll(ctx context.Context) ([]db.WebhookDb, error) {
+	if m.FindAllFunc != nil {
+		return m.FindAllFunc(ctx)
+	}
+	return []db.WebhookDb{}, nil
+}
+
+func (m *MockWebhookDbHandler) FindOneById(ctx context.Context, id primitive.ObjectID) (*db.WebhookDb, error) {
+	if m.FindOneByIdFunc != nil {
+		return m.FindOneByIdFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookDbHandler) FindByEvent(ctx context.Context, eventType string) ([]db.WebhookDb, error) {
+	if m.FindByEventFunc != nil {
+		return m.FindByEventFunc(ctx, eventType)
+	}
+	return []db.WebhookDb{}, nil
+}
+
+func (m *MockWebhookDbHandler) DeleteOne(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	if m.DeleteOneFunc != nil {
+		return m.DeleteOneFunc(ctx, id)
+	}
+	return false, nil
+}
+
+func (m *MockWebhookDbHandler) LoadResumeToken(ctx context.Context) (string, error) {
+	if m.LoadResumeTokenFunc != nil {
+		return m.LoadResumeTokenFunc(ctx)
+	}
+	return "", nil
+}
+
+func (m *MockWebhookDbHandler) SaveResumeToken(ctx context.Context, token string) error {
+	if m.SaveResumeTokenFunc != nil {
+		return m.SaveResumeTokenFunc(ctx, token)
+	}
+	return nil
+}
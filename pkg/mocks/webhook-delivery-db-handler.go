@@ -0,0 +1,61 @@
+package mocks
+
+import (
+	"article-management-service/pkg/db"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockWebhookDeliveryDbHandler struct {
+	NewFunc             func(database *mongo.Database) error
+	EnqueueFunc         func(ctx context.Context, new db.WebhookDeliveryDb) error
+	ClaimFunc           func(ctx context.Context, now time.Time, lease time.Duration, skipWebhookIds []primitive.ObjectID) (*db.WebhookDeliveryDb, error)
+	FinishFunc          func(ctx context.Context, delivery db.WebhookDeliveryDb, attempt db.WebhookAttemptDb) error
+	FindByWebhookIdFunc func(ctx context.Context, webhookId primitive.ObjectID, status string) ([]db.WebhookDeliveryDb, error)
+	RetryFunc           func(ctx context.Context, webhookId primitive.ObjectID, id primitive.ObjectID) (bool, error)
+}
+
+func (m *MockWebhookDeliveryDbHandler) New(database *mongo.Database) error {
+	if m.NewFunc != nil {
+		return m.NewFunc(database)
+	}
+	return nil
+}
+
+func (m *MockWebhookDeliveryDbHandler) Enqueue(ctx context.Context, new db.WebhookDeliveryDb) error {
+	if m.EnqueueFunc != nil {
+		return m.EnqueueFunc(ctx, new)
+	}
+	return nil
+}
+
+func (m *MockWebhookDeliveryDbHandler) Claim(ctx context.Context, now time.Time, lease time.Duration, skipWebhookIds []primitive.ObjectID) (*db.WebhookDeliveryDb, error) {
+	if m.ClaimFunc != nil {
+		return m.ClaimFunc(ctx, now, lease, skipWebhookIds)
+	}
+	return nil, nil
+}
+
+func (m *MockWebhookDeliveryDbHandler) Finish(ctx context.Context, delivery db.WebhookDeliveryDb, attempt db.WebhookAttemptDb) error {
+	if m.FinishFunc != nil {
+		return m.FinishFunc(ctx, delivery, attempt)
+	}
+	return nil
+}
+
+func (m *MockWebhookDeliveryDbHandler) FindByWebhookId(ctx context.Context, webhookId primitive.ObjectID, status string) ([]db.WebhookDeliveryDb, error) {
+	if m.FindByWebhookIdFunc != nil {
+		return m.FindByWebhookIdFunc(ctx, webhookId, status)
+	}
+	return []db.WebhookDeliveryDb{}, nil
+}
+
+func (m *MockWebhookDeliveryDbHandler) Retry(ctx context.Context, webhookId primitive.ObjectID, id primitive.ObjectID) (bool, error) {
+	if m.RetryFunc != nil {
+		return m.RetryFunc(ctx, webhookId, id)
+	}
+	return false, nil
+}
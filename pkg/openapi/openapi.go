@@ -0,0 +1,301 @@
+package openapi
+
+import (
+	"article-management-service/pkg/auth"
+	"embed"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SecurityBearer = "bearer"
+	SecurityApiKey = "apiKey"
+)
+
+// DocsPage is an interactive page for the document served at /openapi.json
+//
+//go:embed docs.html
+var DocsPage []byte
+
+// DocsAssets are the scripts and styles of the docs page, vendored from swagger-ui-dist 5.18.2 so the page
+// loads nothing from other origins
+//
+//go:embed swagger-ui
+var DocsAssets embed.FS
+
+// Operation documents one route
+type Operation struct {
+	Id         string // unique per document, e.g. createArticle
+	Method     string
+	Path       string // in gin syntax, e.g. /article/:articleId
+	Summary    string
+	Tag        string
+	Params     []Parameter // path parameters that are not plain strings; the others are added from the path
+	Query      []Parameter
+	Headers    []Parameter
+	Body       interface{}      // a value of the JSON body; nil when the route takes none
+	Content    openapi3.Content // bodies other than JSON, e.g. multipart/form-data
+	Responses  []Response
+	Secured    bool // needs credentials when authentication is enabled
+	Deprecated bool
+}
+
+// Parameter is a path, query or header parameter; the type is a JSON schema type
+type Parameter struct {
+	Name        string
+	Description string
+	Type        string // string, boolean or integer; defaults to string
+	Format      string
+	Pattern     string
+	Required    bool
+	Enum        []string
+}
+
+// Response is one possible response; without body, content types and content the response has no body
+type Response struct {
+	Status       int
+	Description  string
+	Body         interface{}      // a value of the JSON body
+	ContentTypes []string         // of bodies other than JSON
+	Content      openapi3.Content // of bodies whose schema is not generated from a type
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+var objectIdType = reflect.TypeOf(primitive.ObjectID{})
+
+// Path converts a gin path to an OpenAPI path, e.g. /article/:articleId to /article/{articleId}
+func Path(ginPath string) string {
+	return pathParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// Document generates an OpenAPI 3 document of the operations. The schemas of the bodies are generated from
+// their Go types, including the rules of their validate tags, and are shared as components.
+func Document(title string, version string, operations []Operation) (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: title, Version: version},
+		Paths:   openapi3.Paths{},
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{},
+			SecuritySchemes: openapi3.SecuritySchemes{
+				SecurityBearer: &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme()},
+				SecurityApiKey: &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().WithType("apiKey").WithIn("header").WithName(auth.HeaderApiKey)},
+			},
+		},
+	}
+
+	for _, operation := range operations {
+		op, err := documentOperation(doc, operation)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", operation.Method, operation.Path, err)
+		}
+		doc.AddOperation(Path(operation.Path), operation.Method, op)
+	}
+	return doc, nil
+}
+
+func documentOperation(doc *openapi3.T, operation Operation) (*openapi3.Operation, error) {
+	op := openapi3.NewOperation()
+	op.Summary = operation.Summary
+	op.OperationID = operation.Id
+	op.Deprecated = operation.Deprecated
+	if operation.Tag != "" {
+		op.Tags = []string{operation.Tag}
+	}
+	if operation.Secured {
+		op.Security = &openapi3.SecurityRequirements{
+			openapi3.NewSecurityRequirement().Authenticate(SecurityBearer),
+			openapi3.NewSecurityRequirement().Authenticate(SecurityApiKey),
+		}
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(operation.Path, -1) {
+		parameter := Parameter{Name: match[1]}
+		for _, p := range operation.Params {
+			if p.Name == parameter.Name {
+				parameter = p
+			}
+		}
+		op.AddParameter(parameterOf(openapi3.NewPathParameter(parameter.Name), parameter).WithRequired(true))
+	}
+	for _, parameter := range operation.Query {
+		op.AddParameter(parameterOf(openapi3.NewQueryParameter(parameter.Name), parameter))
+	}
+	for _, parameter := range operation.Headers {
+		op.AddParameter(parameterOf(openapi3.NewHeaderParameter(parameter.Name), parameter))
+	}
+
+	if operation.Body != nil || operation.Content != nil {
+		content := openapi3.Content{}
+		for contentType, mediaType := range operation.Content {
+			content[contentType] = mediaType
+		}
+		if operation.Body != nil {
+			schema, err := componentSchema(doc, operation.Body)
+			if err != nil {
+				return nil, err
+			}
+			content["application/json"] = openapi3.NewMediaType().WithSchemaRef(schema)
+		}
+		op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithContent(content)}
+	}
+
+	op.Responses = openapi3.Responses{}
+	for _, response := range operation.Responses {
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(response.Status)
+		}
+		r := openapi3.NewResponse().WithDescription(description)
+
+		switch {
+		case response.Body != nil:
+			schema, err := componentSchema(doc, response.Body)
+			if err != nil {
+				return nil, err
+			}
+			r.WithJSONSchemaRef(schema)
+		case response.Content != nil:
+			r.WithContent(response.Content)
+		case len(response.ContentTypes) > 0:
+			r.WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema().WithFormat("binary"), response.ContentTypes))
+		}
+		op.AddResponse(response.Status, r)
+	}
+	return op, nil
+}
+
+func parameterOf(p *openapi3.Parameter, parameter Parameter) *openapi3.Parameter {
+	schema := &openapi3.Schema{Type: parameter.Type, Format: parameter.Format, Pattern: parameter.Pattern}
+	if schema.Type == "" {
+		schema.Type = "string"
+	}
+	for _, value := range parameter.Enum {
+		schema.Enum = append(schema.Enum, value)
+	}
+	return p.WithDescription(parameter.Description).WithRequired(parameter.Required).WithSchema(schema)
+}
+
+// Generates the schema of the value; named structs are added to the components and referenced
+func componentSchema(doc *openapi3.T, value interface{}) (*openapi3.SchemaRef, error) {
+	schema, err := openapi3gen.NewSchemaRefForValue(value, doc.Components.Schemas, openapi3gen.SchemaCustomizer(customizeSchema))
+	if err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(value)
+	switch {
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		doc.Components.Schemas[t.Name()] = schema
+		return openapi3.NewSchemaRef("#/components/schemas/"+t.Name(), schema.Value), nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct && t.Elem().Name() != "":
+		doc.Components.Schemas[t.Elem().Name()] = schema.Value.Items
+		schema.Value.Items = openapi3.NewSchemaRef("#/components/schemas/"+t.Elem().Name(), schema.Value.Items.Value)
+	}
+	return schema, nil
+}
+
+// Describes object ids as strings and applies the rules of validate tags, so the document states the same
+// rules the handlers check
+func customizeSchema(name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	if t == objectIdType {
+		*schema = *openapi3.NewStringSchema().WithPattern("^[0-9a-f]{24}$")
+		return nil
+	}
+
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+			if jsonName == "" || jsonName == "-" {
+				continue
+			}
+			for _, rule := range beforeDive(field.Tag.Get("validate")) {
+				if rule == "required" {
+					schema.Required = append(schema.Required, jsonName)
+				}
+			}
+		}
+		return nil
+	}
+
+	rules := strings.Split(tag.Get("validate"), ",")
+	if dive := indexOf(rules, "dive"); dive >= 0 {
+		if t.Kind() == reflect.Slice {
+			rules = rules[:dive]
+		} else {
+			rules = rules[dive+1:]
+		}
+	}
+	for _, rule := range rules {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "min", "max":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return err
+			}
+			applyBound(schema, key, n)
+		case "oneof":
+			for _, option := range strings.Fields(value) {
+				schema.Enum = append(schema.Enum, option)
+			}
+			if indexOf(rules, "omitempty") >= 0 {
+				schema.Enum = append(schema.Enum, "")
+			}
+		case "url":
+			schema.Format = "uri"
+		}
+	}
+	return nil
+}
+
+func applyBound(schema *openapi3.Schema, key string, n int64) {
+	switch schema.Type {
+	case "string":
+		if key == "min" {
+			schema.MinLength = uint64(n)
+		} else {
+			schema.MaxLength = openapi3.Uint64Ptr(uint64(n))
+		}
+	case "array":
+		if key == "min" {
+			schema.MinItems = uint64(n)
+		} else {
+			schema.MaxItems = openapi3.Uint64Ptr(uint64(n))
+		}
+	case "integer", "number":
+		f := float64(n)
+		if key == "min" {
+			schema.Min = &f
+		} else {
+			schema.Max = &f
+		}
+	}
+}
+
+func beforeDive(validate string) []string {
+	rules := strings.Split(validate, ",")
+	if dive := indexOf(rules, "dive"); dive >= 0 {
+		return rules[:dive]
+	}
+	return rules
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
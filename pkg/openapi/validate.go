@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"article-management-service/pkg/logging"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Validator checks requests, and optionally responses, against the operations of an OpenAPI document
+type Validator struct {
+	Doc    *openapi3.T
+	Logger *logrus.Logger
+	// checks the responses as well, to catch handlers that drifted from the document; meant for tests,
+	// as the JSON responses are copied
+	ValidateResponses bool
+	// called for responses that do not match the document; logs them by default
+	OnResponseError func(c *gin.Context, err error)
+}
+
+// Middleware validates the path, query and header parameters and JSON bodies of requests to the operation
+// of the gin route before the handler runs; invalid requests are rejected with 400. Bodies other than JSON,
+// e.g. uploads and imports, and JSON bodies of any schema, e.g. JSON-RPC, are passed to the handler
+// unchecked. Credentials are checked by the auth package.
+func (v *Validator) Middleware(method string, path string) (gin.HandlerFunc, error) {
+	pathItem := v.Doc.Paths.Find(Path(path))
+	if pathItem == nil || pathItem.GetOperation(method) == nil {
+		return nil, fmt.Errorf("%s %s is not documented", method, path)
+	}
+	route := &routers.Route{
+		Spec:      v.Doc,
+		Path:      Path(path),
+		PathItem:  pathItem,
+		Method:    method,
+		Operation: pathItem.GetOperation(method),
+	}
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		ExcludeRequestBody:    !hasJSONBody(route.Operation),
+		IncludeResponseStatus: true,
+		MultiError:            true,
+	}
+
+	return func(c *gin.Context) {
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: params,
+			Route:      route,
+			Options:    options,
+		}
+
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			logging.FromContext(c.Request.Context(), v.Logger).WithError(err).WithField("status", http.StatusBadRequest).Info("request rejected")
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if !v.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		response := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 writer.Status(),
+			Header:                 writer.Header(),
+			Options:                &openapi3filter.Options{IncludeResponseStatus: true, MultiError: true, ExcludeResponseBody: !writer.json},
+		}
+		response.SetBodyBytes(writer.body.Bytes())
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), response); err != nil {
+			if v.OnResponseError != nil {
+				v.OnResponseError(c, err)
+				return
+			}
+			logging.FromContext(c.Request.Context(), v.Logger).WithError(err).WithField("status", writer.Status()).Error("response does not match the OpenAPI document")
+		}
+	}, nil
+}
+
+// Whether the operation takes a JSON body with a schema to check
+func hasJSONBody(operation *openapi3.Operation) bool {
+	if operation.RequestBody == nil {
+		return false
+	}
+	mediaType := operation.RequestBody.Value.Content.Get("application/json")
+	return mediaType != nil && mediaType.Schema != nil && !mediaType.Schema.Value.IsEmpty()
+}
+
+// recordingWriter copies JSON responses, so they can be validated once written; other responses,
+// e.g. streams, are passed through only
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+	json bool
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	if !w.Written() {
+		w.json = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	}
+	if w.json {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+type testBody struct {
+	Title  string   `json:"title" validate:"required,max=10"`
+	Policy string   `json:"policy,omitempty" validate:"omitempty,oneof=delete archive"`
+	Tags   []string `json:"tags,omitempty" validate:"max=2,dive,min=1"`
+}
+
+type testResponse struct {
+	Id string `json:"id"`
+}
+
+func TestValidator_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doc, err := Document("test", "1.0.0", []Operation{
+		{
+			Id:        "create",
+			Method:    http.MethodPost,
+			Path:      "/item/:itemId",
+			Params:    []Parameter{{Name: "itemId", Pattern: "^[0-9a-f]{24}$"}},
+			Query:     []Parameter{{Name: "dryRun", Type: "boolean"}},
+			Headers:   []Parameter{{Name: "X-Count", Type: "integer"}},
+			Body:      testBody{},
+			Responses: []Response{{Status: http.StatusCreated, Body: testResponse{}}, {Status: http.StatusBadRequest}},
+		},
+		{
+			Id:        "import",
+			Method:    http.MethodPost,
+			Path:      "/import",
+			Content:   openapi3.NewContentWithSchema(openapi3.NewStringSchema(), []string{"text/csv"}),
+			Responses: []Response{{Status: http.StatusOK}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Document() error = %v", err)
+	}
+
+	const itemId = "/item/0123456789abcdef01234567"
+	tests := []struct {
+		name           string
+		path           string
+		contentType    string
+		header         string
+		body           string
+		response       interface{} // of the handler
+		expectedStatus int
+		expectedBody   string // as read by the handler
+		responseError  bool
+	}{
+		{name: "Successfully pass a valid request", path: itemId + "?dryRun=true", body: `{"title": "a", "tags": ["b"]}`, response: testResponse{Id: "1"}, expectedStatus: http.StatusCreated, expectedBody: `{"title": "a", "tags": ["b"]}`},
+		{name: "Successfully pass an empty optional enum", path: itemId, body: `{"title": "a", "policy": ""}`, response: testResponse{Id: "1"}, expectedStatus: http.StatusCreated},
+		{name: "Prevent invalid path parameter", path: "/item/1", body: `{"title": "a"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent invalid query parameter", path: itemId + "?dryRun=maybe", body: `{"title": "a"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent invalid header", path: itemId, header: "many", body: `{"title": "a"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent missing required field", path: itemId, body: `{"policy": "delete"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent too long field", path: itemId, body: `{"title": "abcdefghijk"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent unknown enum value", path: itemId, body: `{"title": "a", "policy": "keep"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent too many items", path: itemId, body: `{"title": "a", "tags": ["b", "c", "d"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent invalid items", path: itemId, body: `{"title": "a", "tags": [""]}`, expectedStatus: http.StatusBadRequest},
+		{name: "Prevent other content types", path: itemId, contentType: "text/plain", body: `{"title": "a"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Successfully pass bodies other than JSON unchecked", path: "/import", contentType: "text/csv", body: "title\na", expectedStatus: http.StatusOK, expectedBody: "title\na"},
+		{name: "Detect undocumented response", path: itemId, body: `{"title": "a"}`, response: gin.H{"id": 1}, expectedStatus: http.StatusCreated, responseError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var responseErr error
+			validator := &Validator{Doc: doc, ValidateResponses: true, OnResponseError: func(c *gin.Context, err error) { responseErr = err }}
+
+			var body string
+			engine := gin.New()
+			for _, route := range []struct{ path, status string }{{"/item/:itemId", "201"}, {"/import", "200"}} {
+				validate, err := validator.Middleware(http.MethodPost, route.path)
+				if err != nil {
+					t.Fatalf("Middleware() error = %v", err)
+				}
+				engine.POST(route.path, validate, func(c *gin.Context) {
+					data, _ := io.ReadAll(c.Request.Body)
+					body = string(data)
+					if tt.response != nil {
+						c.JSON(http.StatusCreated, tt.response)
+						return
+					}
+					c.Status(http.StatusOK)
+				})
+			}
+
+			request := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			request.Header.Set("Content-Type", contentType)
+			if tt.header != "" {
+				request.Header.Set("X-Count", tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("Middleware() status = %v, want %v", recorder.Code, tt.expectedStatus)
+			}
+			if tt.expectedBody != "" && body != tt.expectedBody {
+				t.Errorf("Middleware() body = %q, want %q", body, tt.expectedBody)
+			}
+			if (responseErr != nil) != tt.responseError {
+				t.Errorf("Middleware() response error = %v, want error %v", responseErr, tt.responseError)
+			}
+		})
+	}
+}
+
+func TestValidator_Middleware_Undocumented(t *testing.T) {
+	doc, err := Document("test", "1.0.0", nil)
+	if err != nil {
+		t.Fatalf("Document() error = %v", err)
+	}
+
+	validator := &Validator{Doc: doc}
+	if _, err := validator.Middleware(http.MethodGet, "/article"); err == nil {
+		t.Error("Middleware() error = nil, want an error for undocumented routes")
+	}
+}
@@ -0,0 +1,199 @@
+package ratelimit
+
+import (
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/logging"
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	KeyByIp = "ip"
+	// the subject of the authenticated identity; anonymous requests and requests with invalid credentials
+	// fall back to the client ip
+	KeyByIdentity = "identity"
+	KeyByApiKey   = "api-key" // the former name of KeyByIdentity
+)
+
+// Budget is a token bucket: it holds at most Burst tokens and refills PerMinute tokens every minute.
+type Budget struct {
+	Name      string
+	PerMinute int
+	Burst     int
+}
+
+func (b Budget) ratePerSecond() float64 {
+	return float64(b.PerMinute) / 60
+}
+
+// Store keeps the buckets. Take refills the bucket of the key up to now and takes one token when available;
+// it returns the tokens left and whether a token was taken.
+type Store interface {
+	Take(ctx context.Context, key string, burst float64, ratePerSecond float64, now time.Time) (float64, bool, error)
+}
+
+type Limiter struct {
+	Store  Store
+	KeyBy  string
+	Logger *logrus.Logger
+	Now    func() time.Time
+}
+
+// Middleware limits the requests of every client to the budget; rejected requests get a 429 with Retry-After.
+// Every response carries the RateLimit-* headers, so well-behaved clients can slow down in time.
+// It should be registered after the authentication middleware.
+func (l *Limiter) Middleware(budget Budget) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.take(c, budget, l.ClientKey(c.Request.Context(), c.ClientIP())) {
+			c.Next()
+		}
+	}
+}
+
+// ChargeIp takes a token of the budget of the client ip and aborts with 429 when there is none, without
+// running the next handlers; for requests that are rejected anyway, e.g. with invalid credentials, so
+// guessing credentials is limited like any other request
+func (l *Limiter) ChargeIp(budget Budget) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		l.take(c, budget, IpKey(c.ClientIP()))
+	}
+}
+
+// Takes a token of the bucket of the key and sets the RateLimit-* headers; aborts with 429 and false when
+// the budget is spent
+func (l *Limiter) take(c *gin.Context, budget Budget, clientKey string) bool {
+	tokens, allowed, err := l.takeToken(c.Request.Context(), budget, clientKey)
+	if err != nil {
+		// fail open; an unavailable store should not take the whole api down
+		return true
+	}
+
+	rate, burst := budget.ratePerSecond(), float64(budget.Burst)
+	c.Header("RateLimit-Limit", strconv.Itoa(budget.Burst))
+	c.Header("RateLimit-Remaining", strconv.Itoa(int(math.Floor(tokens))))
+	c.Header("RateLimit-Reset", strconv.Itoa(secondsUntil(burst-tokens, rate)))
+	c.Header("RateLimit-Policy", strconv.Itoa(budget.Burst)+";w="+strconv.Itoa(secondsUntil(burst, rate)))
+
+	if !allowed {
+		c.Header("Retry-After", strconv.Itoa(secondsUntil(1-tokens, rate)))
+		c.AbortWithStatus(http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+// Allow takes a token of the budget of the client for the APIs other than HTTP, e.g. gRPC; returns whether the
+// call may proceed and otherwise the seconds until it may be retried. Fails open like the middleware.
+func (l *Limiter) Allow(ctx context.Context, budget Budget, clientKey string) (bool, int) {
+	tokens, allowed, err := l.takeToken(ctx, budget, clientKey)
+	if err != nil || allowed {
+		return true, 0
+	}
+	return false, secondsUntil(1-tokens, budget.ratePerSecond())
+}
+
+func (l *Limiter) takeToken(ctx context.Context, budget Budget, clientKey string) (float64, bool, error) {
+	now := time.Now()
+	if l.Now != nil {
+		now = l.Now()
+	}
+
+	tokens, allowed, err := l.Store.Take(ctx, budget.Name+":"+clientKey, float64(budget.Burst), budget.ratePerSecond(), now)
+	if err != nil {
+		logging.FromContext(ctx, l.Logger).WithError(err).Error("rate limit store failed")
+	}
+	return tokens, allowed, err
+}
+
+// ClientKey identifies the client of a request by the subject of its identity or by its ip. Only authenticated
+// identities count, as anyone can send an api key; the subject is shared by all credentials of a client.
+func (l *Limiter) ClientKey(ctx context.Context, ip string) string {
+	if l.KeyBy == KeyByIdentity || l.KeyBy == KeyByApiKey {
+		if identity := auth.FromContext(ctx); identity != nil {
+			return "sub:" + identity.Subject
+		}
+	}
+	return IpKey(ip)
+}
+
+// IpKey identifies the client by its ip, whatever the KeyBy of the limiter
+func IpKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Seconds (rounded up) until the given amount of tokens is refilled
+func secondsUntil(tokens float64, ratePerSecond float64) int {
+	if tokens <= 0 {
+		return 0
+	}
+	if ratePerSecond <= 0 {
+		return math.MaxInt32
+	}
+	return int(math.Ceil(tokens / ratePerSecond))
+}
+
+type bucket struct {
+	tokens        float64
+	burst         float64
+	ratePerSecond float64
+	updatedAt     time.Time
+}
+
+// MemoryStore keeps the buckets in memory; the limits are per instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+const sweepInterval = time.Minute
+
+func (s *MemoryStore) Take(_ context.Context, key string, burst float64, ratePerSecond float64, now time.Time) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.buckets == nil {
+		s.buckets = make(map[string]*bucket)
+	}
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updatedAt: now}
+		s.buckets[key] = b
+	}
+	b.burst, b.ratePerSecond = burst, ratePerSecond
+
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*ratePerSecond)
+	}
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return b.tokens, false, nil
+	}
+	b.tokens--
+	return b.tokens, true, nil
+}
+
+// Removes the buckets that are full again, so idle clients do not keep using memory
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.updatedAt).Seconds()*b.ratePerSecond >= b.burst {
+			delete(s.buckets, key)
+		}
+	}
+}
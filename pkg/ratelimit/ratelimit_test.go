@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"article-management-service/pkg/auth"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	t.Run("Refill the bucket over time", func(t *testing.T) {
+		s := &MemoryStore{}
+		now := time.Now()
+
+		for i := 0; i < 2; i++ {
+			if _, allowed, _ := s.Take(context.Background(), "key", 2, 1, now); !allowed {
+				t.Fatalf("MemoryStore.Take() = %v, want %v on take %d", allowed, true, i)
+			}
+		}
+
+		tokens, allowed, _ := s.Take(context.Background(), "key", 2, 1, now)
+		if allowed || tokens != 0 {
+			t.Errorf("MemoryStore.Take() = %v, %v, want %v, %v", tokens, allowed, 0, false)
+		}
+
+		if _, allowed, _ := s.Take(context.Background(), "key", 2, 1, now.Add(time.Second)); !allowed {
+			t.Errorf("MemoryStore.Take() = %v, want %v after refill", allowed, true)
+		}
+	})
+
+	t.Run("Never refill above the burst", func(t *testing.T) {
+		s := &MemoryStore{}
+		now := time.Now()
+
+		s.Take(context.Background(), "key", 2, 1, now)
+		tokens, _, _ := s.Take(context.Background(), "key", 2, 1, now.Add(time.Hour))
+		if tokens != 1 {
+			t.Errorf("MemoryStore.Take() = %v, want %v", tokens, 1)
+		}
+	})
+}
+
+func TestLimiter_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the subject stands in for the identity of valid credentials
+	serve := func(engine *gin.Engine, subject string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/article", nil)
+		if subject != "" {
+			req = req.WithContext(auth.WithIdentity(req.Context(), &auth.Identity{Subject: subject}))
+		}
+		response := httptest.NewRecorder()
+		engine.ServeHTTP(response, req)
+		return response
+	}
+
+	newEngine := func(keyBy string) *gin.Engine {
+		now := time.Now()
+		limiter := &Limiter{Store: &MemoryStore{}, KeyBy: keyBy, Now: func() time.Time { return now }}
+
+		engine := gin.New()
+		engine.GET("/article", limiter.Middleware(Budget{Name: "read", PerMinute: 6, Burst: 2}), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return engine
+	}
+
+	t.Run("Reject with 429 and Retry-After when the budget is spent", func(t *testing.T) {
+		engine := newEngine(KeyByIp)
+
+		first := serve(engine, "")
+		if first.Code != http.StatusOK || first.Header().Get("RateLimit-Limit") != "2" || first.Header().Get("RateLimit-Remaining") != "1" {
+			t.Errorf("first request = %d %v", first.Code, first.Header())
+		}
+
+		serve(engine, "")
+		rejected := serve(engine, "")
+		if rejected.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status %d; got %d", http.StatusTooManyRequests, rejected.Code)
+		}
+
+		// 6 per minute; one token takes 10 seconds
+		if retryAfter := rejected.Header().Get("Retry-After"); retryAfter != "10" {
+			t.Errorf("Retry-After = %v, want %v", retryAfter, "10")
+		}
+	})
+
+	t.Run("Separate budgets per identity", func(t *testing.T) {
+		engine := newEngine(KeyByIdentity)
+
+		serve(engine, "importer")
+		serve(engine, "importer")
+		if response := serve(engine, "importer"); response.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status %d; got %d", http.StatusTooManyRequests, response.Code)
+		}
+
+		if response := serve(engine, "editor-1"); response.Code != http.StatusOK {
+			t.Errorf("Expected status %d; got %d", http.StatusOK, response.Code)
+		}
+		if response := serve(engine, ""); response.Code != http.StatusOK {
+			t.Errorf("Expected status %d for anonymous requests; got %d", http.StatusOK, response.Code)
+		}
+	})
+
+	t.Run("Share the ip budget among anonymous requests", func(t *testing.T) {
+		engine := newEngine(KeyByIdentity)
+
+		serve(engine, "")
+		serve(engine, "")
+		if response := serve(engine, ""); response.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status %d; got %d", http.StatusTooManyRequests, response.Code)
+		}
+	})
+}
+
+func TestLimiter_ChargeIp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	limiter := &Limiter{Store: &MemoryStore{}, KeyBy: KeyByIdentity, Now: func() time.Time { return now }}
+	budget := Budget{Name: "read", PerMinute: 6, Burst: 2}
+
+	// invalid credentials are rejected by the authentication, which charges the ip
+	authenticator := &auth.Authenticator{JWT: &auth.JWTConfig{HMACSecret: []byte("secret")}, OnInvalidCredentials: limiter.ChargeIp(budget)}
+	engine := gin.New()
+	engine.Use(authenticator.Authenticate())
+	engine.GET("/article", limiter.Middleware(budget), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	expected := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests}
+	for i, status := range expected {
+		req, _ := http.NewRequest("GET", "/article", nil)
+		req.Header.Set(auth.HeaderAuthorization, "Bearer invalid")
+		response := httptest.NewRecorder()
+		engine.ServeHTTP(response, req)
+		if response.Code != status {
+			t.Errorf("request %d status = %d, want %d", i, response.Code, status)
+		}
+	}
+
+	// the guessing spent the budget of the ip also for anonymous requests
+	req, _ := http.NewRequest("GET", "/article", nil)
+	response := httptest.NewRecorder()
+	engine.ServeHTTP(response, req)
+	if response.Code != http.StatusTooManyRequests {
+		t.Errorf("anonymous request status = %d, want %d", response.Code, http.StatusTooManyRequests)
+	}
+}
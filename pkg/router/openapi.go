@@ -0,0 +1,387 @@
+package router
+
+import (
+	"article-management-service/pkg/controller"
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/middleware"
+	"article-management-service/pkg/openapi"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	docsTitle   = "Article Management Service"
+	docsVersion = "1.0.0"
+)
+
+var (
+	revisionParam = openapi.Parameter{Name: "n", Type: "integer", Description: "The number of the revision, from 1"}
+	tenantHeader  = openapi.Parameter{Name: middleware.HeaderTenantId, Description: "The tenant of callers whose credentials are not bound to one"}
+
+	badRequest   = openapi.Response{Status: http.StatusBadRequest}
+	unauthorized = openapi.Response{Status: http.StatusUnauthorized, Description: "Missing or invalid credentials"}
+	forbidden    = openapi.Response{Status: http.StatusForbidden, Description: "Not allowed for the roles of the caller, or the quota is exhausted"}
+	notFound     = openapi.Response{Status: http.StatusNotFound}
+	failed       = openapi.Response{Status: http.StatusInternalServerError}
+)
+
+// routeKey is the method and path of a route
+type routeKey struct {
+	method string
+	path   string
+}
+
+// operations documents every route of the first version by method and path; Init fails for routes without documentation
+var operations = map[routeKey]openapi.Operation{
+	{http.MethodPost, routeArticle}: {
+		Id:      "createArticle",
+		Summary: "Creates an article",
+		Tag:     "articles",
+		Body:    controller.NewArticleBody{},
+		Responses: []openapi.Response{
+			{Status: http.StatusCreated, Body: controller.CreatedResponse{}},
+			badRequest, unauthorized, forbidden, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodPost, routeImport}: {
+		Id:      "importArticles",
+		Summary: "Creates the articles of an NDJSON or CSV body; invalid rows are reported and skipped",
+		Tag:     "articles",
+		Query:   []openapi.Parameter{{Name: "dryRun", Type: "boolean", Description: "Only validates the rows"}},
+		Content: openapi3.NewContentWithSchema(openapi3.NewStringSchema(), []string{"application/x-ndjson", "application/ndjson", "application/jsonl", "text/csv"}),
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: controller.ImportReport{}},
+			{Status: http.StatusBadRequest, Description: "The body can not be read; the rows before are imported", Body: controller.ImportReport{}},
+			unauthorized, forbidden,
+			{Status: http.StatusUnsupportedMediaType},
+			{Status: http.StatusTooManyRequests, Description: "The create budget is spent; the rows before are imported", Body: controller.ImportReport{}},
+			{Status: http.StatusInternalServerError, Description: "An article can not be stored; the rows before are imported", Body: controller.ImportReport{}},
+		},
+		Secured: true,
+	},
+	{http.MethodGet, routeExport}: {
+		Id:      "exportArticles",
+		Summary: "Streams the articles as NDJSON, CSV or ZIP with their images",
+		Tag:     "articles",
+		Query: []openapi.Parameter{
+			{Name: "format", Enum: []string{controller.ExportFormatNDJSON, controller.ExportFormatCSV, controller.ExportFormatZIP}},
+			{Name: "withImages", Type: "boolean", Description: "Only articles with or without images"},
+			{Name: "includeExpired", Type: "boolean"},
+			{Name: "expiresFrom", Format: "date-time"},
+			{Name: "expiresTo", Format: "date-time"},
+		},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, ContentTypes: []string{"application/x-ndjson", "text/csv", "application/zip"}},
+			badRequest, forbidden, failed,
+		},
+	},
+	{http.MethodPost, routeImage}: {
+		Id:      "attachImage",
+		Summary: "Attaches an image to the article",
+		Tag:     "articles",
+		Params:  []openapi.Parameter{objectIdParam("articleId")},
+		Content: openapi3.NewContentWithFormDataSchema(imageUpload()),
+		Responses: []openapi.Response{
+			{Status: http.StatusOK},
+			badRequest, unauthorized, forbidden, notFound, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodGet, routeFindArticles}: {
+		Id:      "findArticleTitles",
+		Summary: "Lists the titles of the articles",
+		Tag:     "articles",
+		Query:   []openapi.Parameter{{Name: "withImages", Type: "boolean", Description: "Only articles with or without images"}},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: []string{}},
+			forbidden, failed,
+		},
+	},
+	{http.MethodPut, routeUpdate}: {
+		Id:      "updateArticle",
+		Summary: "Replaces the article; the previous version stays available as a revision",
+		Tag:     "articles",
+		Params:  []openapi.Parameter{objectIdParam("articleId")},
+		Body:    controller.NewArticleBody{},
+		Responses: []openapi.Response{
+			{Status: http.StatusNoContent},
+			badRequest, unauthorized, forbidden, notFound, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodDelete, routeDelete}: {
+		Id:      "deleteArticle",
+		Summary: "Moves the article to the trash",
+		Tag:     "trash",
+		Params:  []openapi.Parameter{objectIdParam("articleId")},
+		Responses: []openapi.Response{
+			{Status: http.StatusNoContent},
+			badRequest, unauthorized, forbidden, notFound, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodPost, routeRestore}: {
+		Id:      "restoreArticle",
+		Summary: "Restores the article from the trash",
+		Tag:     "trash",
+		Params:  []openapi.Parameter{objectIdParam("articleId")},
+		Responses: []openapi.Response{
+			{Status: http.StatusNoContent},
+			badRequest, unauthorized, forbidden, notFound, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodGet, routeTrash}: {
+		Id:      "findTrash",
+		Summary: "Lists the articles in the trash, most recently deleted first",
+		Tag:     "trash",
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: []db.ArticleDb{}},
+			forbidden, failed,
+		},
+	},
+	{http.MethodGet, routeRevisions}: {
+		Id:      "findRevisions",
+		Summary: "Lists the revisions of the article, oldest first",
+		Tag:     "revisions",
+		Params:  []openapi.Parameter{objectIdParam("articleId")},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: []db.RevisionDb{}},
+			badRequest, forbidden, notFound, failed,
+		},
+	},
+	{http.MethodGet, routeRevision}: {
+		Id:      "findRevision",
+		Summary: "Returns one revision of the article",
+		Tag:     "revisions",
+		Params:  []openapi.Parameter{objectIdParam("articleId"), revisionParam},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: db.RevisionDb{}},
+			badRequest, forbidden, notFound, failed,
+		},
+	},
+	{http.MethodGet, routeRevisionDiff}: {
+		Id:      "diffRevisions",
+		Summary: "Lists the fields that differ between two revisions",
+		Tag:     "revisions",
+		Params:  []openapi.Parameter{objectIdParam("articleId"), revisionParam},
+		Query:   []openapi.Parameter{{Name: "to", Type: "integer", Description: "The revision to compare with; defaults to the next one"}},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: []controller.FieldChange{}},
+			badRequest, forbidden, notFound, failed,
+		},
+	},
+	{http.MethodPost, routeRevisionRestore}: {
+		Id:      "restoreRevision",
+		Summary: "Restores the article to the revision",
+		Tag:     "revisions",
+		Params:  []openapi.Parameter{objectIdParam("articleId"), revisionParam},
+		Responses: []openapi.Response{
+			{Status: http.StatusNoContent},
+			badRequest, unauthorized, forbidden, notFound, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodPost, routeRenew}: {
+		Id:      "renewArticle",
+		Summary: "Pushes back the expiration date of the article",
+		Tag:     "articles",
+		Params:  []openapi.Parameter{objectIdParam("articleId")},
+		Body:    controller.RenewArticleBody{},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: controller.RenewResponse{}},
+			badRequest, unauthorized, forbidden, notFound,
+			{Status: http.StatusConflict, Description: "Deleted or renewed concurrently"},
+			failed,
+		},
+		Secured: true,
+	},
+	{http.MethodGet, routeEvents}: {
+		Id:      "streamEvents",
+		Summary: "Streams the changes of the articles as server-sent events",
+		Tag:     "articles",
+		Headers: []openapi.Parameter{{Name: controller.HeaderLastEventId, Description: "Resumes after the event"}},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, ContentTypes: []string{"text/event-stream"}},
+			{Status: http.StatusBadRequest, Description: "The event to resume after is no longer available"},
+			{Status: http.StatusServiceUnavailable, Description: "Too many clients resume from older events; retry after the Retry-After seconds"},
+			forbidden, failed,
+		},
+	},
+	{http.MethodGet, routeAudit}: {
+		Id:      "findAuditEntries",
+		Summary: "Lists the audit log, newest first",
+		Tag:     "audit",
+		Query: []openapi.Parameter{
+			objectIdParam("articleId"),
+			{Name: "actor"},
+			{Name: "from", Format: "date-time"},
+			{Name: "to", Format: "date-time"},
+			{Name: "limit", Type: "integer"},
+		},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: []db.AuditEntryDb{}},
+			badRequest, unauthorized, forbidden, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodPost, routeWebhooks}: {
+		Id:      "createWebhook",
+		Summary: "Subscribes a url to article events",
+		Tag:     "webhooks",
+		Body:    controller.NewWebhookBody{},
+		Responses: []openapi.Response{
+			{Status: http.StatusCreated, Body: controller.CreatedResponse{}},
+			badRequest, unauthorized, forbidden, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodGet, routeWebhooks}: {
+		Id:      "findWebhooks",
+		Summary: "Lists the webhooks",
+		Tag:     "webhooks",
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: []db.WebhookDb{}},
+			unauthorized, forbidden, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodDelete, routeWebhook}: {
+		Id:      "deleteWebhook",
+		Summary: "Deletes the webhook",
+		Tag:     "webhooks",
+		Params:  []openapi.Parameter{objectIdParam("webhookId")},
+		Responses: []openapi.Response{
+			{Status: http.StatusNoContent},
+			badRequest, unauthorized, forbidden, notFound, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodGet, routeDeliveries}: {
+		Id:      "findDeliveries",
+		Summary: "Lists the deliveries of the webhook, newest first",
+		Tag:     "webhooks",
+		Params:  []openapi.Parameter{objectIdParam("webhookId")},
+		Query:   []openapi.Parameter{{Name: "status", Enum: []string{db.DeliveryStatusPending, db.DeliveryStatusDelivered, db.DeliveryStatusDead}}},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Body: []db.WebhookDeliveryDb{}},
+			badRequest, unauthorized, forbidden, notFound, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodPost, routeDeliveryRetry}: {
+		Id:      "retryDelivery",
+		Summary: "Moves a dead delivery back to pending",
+		Tag:     "webhooks",
+		Params:  []openapi.Parameter{objectIdParam("webhookId"), objectIdParam("deliveryId")},
+		Responses: []openapi.Response{
+			{Status: http.StatusAccepted},
+			badRequest, unauthorized, forbidden, notFound, failed,
+		},
+		Secured: true,
+	},
+	{http.MethodPost, routeRpc}: {
+		Id:      "rpc",
+		Summary: "Calls article.create, article.get, article.find or article.attachImage with JSON-RPC 2.0; batches and notifications included",
+		Tag:     "rpc",
+		Content: openapi3.NewContentWithJSONSchema(anySchema("A JSON-RPC 2.0 request or a batch of them")),
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Description: "The responses; failed calls are answered with error objects", Content: openapi3.NewContentWithJSONSchema(anySchema("A JSON-RPC 2.0 response or a batch of them"))},
+			{Status: http.StatusNoContent, Description: "Only notifications"},
+			badRequest,
+			{Status: http.StatusRequestEntityTooLarge},
+		},
+	},
+	{http.MethodPost, routeGraphql}: {
+		Id:      "graphql",
+		Summary: "Runs a GraphQL query of article or articles, or a createArticle or updateArticle mutation",
+		Tag:     "graphql",
+		Content: openapi3.NewContentWithJSONSchema(anySchema("The query, operationName and variables")),
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, Description: "The data and the errors of the operation", Content: openapi3.NewContentWithJSONSchema(anySchema("The data and errors"))},
+			badRequest,
+			{Status: http.StatusUnauthorized, Description: "A mutation without credentials"},
+			{Status: http.StatusRequestEntityTooLarge},
+			failed,
+		},
+	},
+}
+
+// Document generates the OpenAPI document of the served routes
+func (r *Router) Document() (*openapi3.T, error) {
+	return document(r.served(), r.Secured)
+}
+
+func document(routes []servedRoute, secured bool) (*openapi3.T, error) {
+	documented := make([]openapi.Operation, 0, len(routes))
+	for _, route := range routes {
+		if !route.documented {
+			return nil, fmt.Errorf("%s %s is not documented", route.method, route.path)
+		}
+		operation := route.operation
+		operation.Method = route.method
+		operation.Path = route.path
+		operation.Deprecated = route.deprecated
+		operation.Headers = append(append([]openapi.Parameter{}, operation.Headers...), tenantHeader)
+		operation.Secured = operation.Secured && secured
+		if !operation.Secured {
+			operation.Responses = withoutStatus(operation.Responses, http.StatusUnauthorized)
+		}
+		documented = append(documented, operation)
+	}
+	return openapi.Document(docsTitle, docsVersion, documented)
+}
+
+// Serves the OpenAPI document of the routes and the interactive docs page with its assets
+func (r *Router) addDocs(doc *openapi3.T) error {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	r.Engine.GET(routeOpenApi, chain(r.Middleware.Read, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", spec)
+	})...)
+	r.Engine.GET(routeDocs, chain(r.Middleware.Read, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage)
+	})...)
+	assets := http.FS(openapi.DocsAssets)
+	r.Engine.GET(routeDocsAssets, chain(r.Middleware.Read, func(c *gin.Context) {
+		c.FileFromFS(path.Join("swagger-ui", c.Param("asset")), assets)
+	})...)
+	return nil
+}
+
+// The multipart form of image uploads
+func imageUpload() *openapi3.Schema {
+	schema := openapi3.NewObjectSchema().WithProperty("file", openapi3.NewStringSchema().WithFormat("binary"))
+	schema.Required = []string{"file"}
+	return schema
+}
+
+// A schema of any JSON value, which is not validated
+func anySchema(description string) *openapi3.Schema {
+	schema := openapi3.NewSchema()
+	schema.Description = description
+	return schema
+}
+
+func objectIdParam(name string) openapi.Parameter {
+	return openapi.Parameter{Name: name, Pattern: "^[0-9a-f]{24}$"}
+}
+
+func withoutStatus(responses []openapi.Response, status int) []openapi.Response {
+	filtered := make([]openapi.Response, 0, len(responses))
+	for _, response := range responses {
+		if response.Status != status {
+			filtered = append(filtered, response)
+		}
+	}
+	return filtered
+}
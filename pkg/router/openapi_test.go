@@ -0,0 +1,185 @@
+package router
+
+import (
+	"article-management-service/pkg/controller"
+	"article-management-service/pkg/openapi"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRouter_OpenApi(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		router  *Router
+		paths   []string
+		missing []string
+	}{
+		{
+			name:    "Document the article routes",
+			router:  &Router{ArticleCtrl: &controller.ArticleController{}},
+			paths:   []string{"/article", "/article/{articleId}", "/article/{articleId}/revisions/{n}/diff", "/image/{articleId}"},
+			missing: []string{"/article/events", "/audit", "/webhooks"},
+		},
+		{
+			name: "Document the optional routes",
+			router: &Router{
+				ArticleCtrl: &controller.ArticleController{},
+				AuditCtrl:   &controller.AuditController{},
+				WebhookCtrl: &controller.WebhookController{},
+				GraphqlCtrl: &controller.GraphqlController{},
+				Events:      true,
+				Secured:     true,
+			},
+			paths: []string{"/article/events", "/audit", "/webhooks", "/webhooks/{webhookId}/deliveries/{deliveryId}/retry", "/graphql"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.router.Engine = gin.New()
+			if err := test.router.Init(); err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+
+			recorder := httptest.NewRecorder()
+			test.router.Engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, routeOpenApi, nil))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Status = %d, expected %d", recorder.Code, http.StatusOK)
+			}
+
+			doc, err := openapi3.NewLoader().LoadFromData(recorder.Body.Bytes())
+			if err != nil {
+				t.Fatalf("LoadFromData() error = %v", err)
+			}
+			if err := doc.Validate(context.Background()); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+
+			for _, path := range test.paths {
+				if doc.Paths.Find(path) == nil {
+					t.Errorf("%s is not documented", path)
+				}
+			}
+			for _, path := range test.missing {
+				if doc.Paths.Find(path) != nil {
+					t.Errorf("%s is documented, but not served", path)
+				}
+			}
+
+			create := doc.Paths.Find("/article").Post
+			if _, ok := create.Responses["401"]; ok != test.router.Secured {
+				t.Errorf("401 documented = %v, expected %v", ok, test.router.Secured)
+			}
+		})
+	}
+}
+
+func TestRouter_Docs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := &Router{ArticleCtrl: &controller.ArticleController{}, Engine: gin.New(), Validator: &openapi.Validator{}}
+	if err := router.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		status      int
+		contentType string
+	}{
+		{name: "Successfully serve the docs page", path: routeDocs, status: http.StatusOK, contentType: "text/html"},
+		{name: "Successfully serve the script", path: "/docs/swagger-ui/swagger-ui-bundle.js", status: http.StatusOK, contentType: "javascript"},
+		{name: "Successfully serve the styles", path: "/docs/swagger-ui/swagger-ui.css", status: http.StatusOK, contentType: "text/css"},
+		{name: "Prevent unknown assets", path: "/docs/swagger-ui/missing.js", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.Engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if recorder.Code != tt.status || !strings.Contains(recorder.Header().Get("Content-Type"), tt.contentType) {
+				t.Fatalf("%s = %d %s, expected %d %s", tt.path, recorder.Code, recorder.Header().Get("Content-Type"), tt.status, tt.contentType)
+			}
+			if tt.path == routeDocs && strings.Contains(recorder.Body.String(), "https://") {
+				t.Error("the docs page loads assets from other origins")
+			}
+		})
+	}
+}
+
+func TestRouter_OpenApiSchemas(t *testing.T) {
+	router := &Router{ArticleCtrl: &controller.ArticleController{}, WebhookCtrl: &controller.WebhookController{}, Engine: gin.New()}
+	if err := router.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	doc, err := router.Document()
+	if err != nil {
+		t.Fatalf("Document() error = %v", err)
+	}
+
+	article := doc.Components.Schemas["NewArticleBody"].Value
+	if len(article.Required) != 3 {
+		t.Errorf("Required = %v, expected title, expirationDate and description", article.Required)
+	}
+	if max := article.Properties["description"].Value.MaxLength; max == nil || *max != 4000 {
+		t.Errorf("description maxLength = %v, expected 4000", max)
+	}
+	if enum := article.Properties["expiryPolicy"].Value.Enum; len(enum) != 4 {
+		t.Errorf("expiryPolicy enum = %v, expected 3 policies and the empty one of omitempty", enum)
+	}
+	if _, ok := article.Properties["title"]; !ok {
+		t.Error("title is not documented")
+	}
+
+	webhook := doc.Components.Schemas["NewWebhookBody"].Value
+	events := webhook.Properties["events"].Value
+	if events.MinItems != 1 || len(events.Items.Value.Enum) == 0 {
+		t.Errorf("events = minItems %d, enum %v; expected the rules before dive on the array and after it on the items", events.MinItems, events.Items.Value.Enum)
+	}
+	if _, ok := doc.Components.Schemas["WebhookDb"].Value.Properties["secret"]; ok {
+		t.Error("the secret of webhooks is documented")
+	}
+}
+
+func TestRouter_Validator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := &Router{ArticleCtrl: &controller.ArticleController{}, Engine: gin.New(), Validator: &openapi.Validator{}}
+	if err := router.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "Prevent invalid withImages", method: http.MethodGet, path: "/article?withImages=maybe"},
+		{name: "Prevent invalid article id", method: http.MethodGet, path: "/article/1/revisions"},
+		{name: "Prevent invalid revision number", method: http.MethodGet, path: "/article/0123456789abcdef01234567/revisions/first"},
+		{name: "Prevent invalid article", method: http.MethodPost, path: "/article", body: `{"title": "a"}`},
+		{name: "Prevent unknown export format", method: http.MethodGet, path: "/article/export?format=xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the controller has no db handler, so requests reaching it fail differently
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.Engine.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("Status = %d, expected %d", recorder.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -1,32 +1,152 @@
 package router
 
 import (
+	"article-management-service/pkg/openapi"
 	"errors"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ArticleController interface {
 	Create(c *gin.Context)
+	Import(c *gin.Context)
+	Export(c *gin.Context)
 	AttachImage(c *gin.Context)
 	Find(c *gin.Context)
+	Delete(c *gin.Context)
+	Restore(c *gin.Context)
+	FindTrash(c *gin.Context)
+	Update(c *gin.Context)
+	FindRevisions(c *gin.Context)
+	FindRevision(c *gin.Context)
+	DiffRevisions(c *gin.Context)
+	RestoreRevision(c *gin.Context)
+	Renew(c *gin.Context)
+	Events(c *gin.Context)
+}
+
+type AuditController interface {
+	Find(c *gin.Context)
+}
+
+type RpcController interface {
+	Handle(c *gin.Context)
+}
+
+type GraphqlController interface {
+	Handle(c *gin.Context)
+}
+
+type WebhookController interface {
+	Create(c *gin.Context)
+	Find(c *gin.Context)
+	Delete(c *gin.Context)
+	FindDeliveries(c *gin.Context)
+	RetryDelivery(c *gin.Context)
 }
 
 const (
-	routeArticle      = "/article"
-	routeImage        = "/image/:articleId"
-	routeFindArticles = "/article"
+	routeArticle         = "/article"
+	routeImport          = "/article/import"
+	routeExport          = "/article/export"
+	routeImage           = "/image/:articleId"
+	routeFindArticles    = "/article"
+	routeAudit           = "/audit"
+	routeDelete          = "/article/:articleId"
+	routeUpdate          = "/article/:articleId"
+	routeRevisions       = "/article/:articleId/revisions"
+	routeRevision        = "/article/:articleId/revisions/:n"
+	routeRevisionDiff    = "/article/:articleId/revisions/:n/diff"
+	routeRevisionRestore = "/article/:articleId/revisions/:n/restore"
+	routeRenew           = "/article/:articleId/renew"
+	routeEvents          = "/article/events"
+	routeRestore         = "/article/:articleId/restore"
+	routeTrash           = "/trash"
+	routeWebhooks        = "/webhooks"
+	routeWebhook         = "/webhooks/:webhookId"
+	routeDeliveries      = "/webhooks/:webhookId/deliveries"
+	routeDeliveryRetry   = "/webhooks/:webhookId/deliveries/:deliveryId/retry"
+	routeRpc             = "/rpc"
+	routeGraphql         = "/graphql"
+	routeOpenApi         = "/openapi.json"
+	routeDocs            = "/docs"
+	routeDocsAssets      = "/docs/swagger-ui/*asset"
 )
 
+// RouteMiddleware holds the optional middleware per kind of route, e.g. separate rate limits
+type RouteMiddleware struct {
+	Read   []gin.HandlerFunc
+	Create []gin.HandlerFunc
+	Upload []gin.HandlerFunc
+	Modify []gin.HandlerFunc // changes to existing articles
+	Admin  []gin.HandlerFunc // audit and webhooks
+	Batch  []gin.HandlerFunc // /rpc, whose handler charges and authorizes each call by itself
+}
+
+// RouteKind selects the middleware of a route
+type RouteKind int
+
+const (
+	KindRead RouteKind = iota
+	KindCreate
+	KindUpload
+	KindModify
+	KindAdmin
+	KindBatch
+)
+
+func (m RouteMiddleware) of(kind RouteKind) []gin.HandlerFunc {
+	switch kind {
+	case KindCreate:
+		return m.Create
+	case KindUpload:
+		return m.Upload
+	case KindModify:
+		return m.Modify
+	case KindAdmin:
+		return m.Admin
+	case KindBatch:
+		return m.Batch
+	default:
+		return m.Read
+	}
+}
+
 type Router struct {
 	ArticleCtrl ArticleController
+	AuditCtrl   AuditController   // optional
+	WebhookCtrl WebhookController // optional
+	RpcCtrl     RpcController     // optional
+	GraphqlCtrl GraphqlController // optional
 	Engine      *gin.Engine
+	Middleware  RouteMiddleware
+	Events      bool               // serves the article events, which need MongoDB change streams
+	Secured     bool               // documents that the create, upload, modify and admin routes require credentials
+	Validator   *openapi.Validator // optional; validates the requests against the OpenAPI document, which Init sets
+	// the versions of the api, oldest first; the first serves the routes below, defaults to v1
+	Versions []Version
+	// the routes at the root serve the first version for clients from before the versions; nil serves them
+	// without deprecation headers
+	Unversioned *Deprecation
+	routes      []route
+}
+
+// route is a route of the first version; the routes are registered once the OpenAPI document is generated from them
+type route struct {
+	method     string
+	path       string
+	kind       RouteKind
+	handler    gin.HandlerFunc
+	operation  openapi.Operation
+	documented bool
 }
 
 func NewRouter(articleCtrl ArticleController, engine *gin.Engine) *Router {
 	return &Router{
 		ArticleCtrl: articleCtrl,
 		Engine:      engine,
+		Versions:    []Version{{Name: "v1"}},
 	}
 }
 
@@ -42,11 +162,79 @@ func (r *Router) addRoutes() error {
 		return errors.New("engine is not initialized")
 	}
 
-	r.Engine.POST(routeArticle, r.ArticleCtrl.Create)
-	r.Engine.POST(routeImage, r.ArticleCtrl.AttachImage)
-	r.Engine.GET(routeFindArticles, r.ArticleCtrl.Find)
+	r.handle(http.MethodPost, routeArticle, KindCreate, r.ArticleCtrl.Create)
+	r.handle(http.MethodPost, routeImport, KindCreate, r.ArticleCtrl.Import)
+	r.handle(http.MethodPost, routeImage, KindUpload, r.ArticleCtrl.AttachImage)
+	r.handle(http.MethodGet, routeFindArticles, KindRead, r.ArticleCtrl.Find)
+	r.handle(http.MethodGet, routeExport, KindRead, r.ArticleCtrl.Export)
+	r.handle(http.MethodDelete, routeDelete, KindModify, r.ArticleCtrl.Delete)
+	r.handle(http.MethodPost, routeRestore, KindModify, r.ArticleCtrl.Restore)
+	r.handle(http.MethodGet, routeTrash, KindRead, r.ArticleCtrl.FindTrash)
+	r.handle(http.MethodPut, routeUpdate, KindModify, r.ArticleCtrl.Update)
+	r.handle(http.MethodGet, routeRevisions, KindRead, r.ArticleCtrl.FindRevisions)
+	r.handle(http.MethodGet, routeRevision, KindRead, r.ArticleCtrl.FindRevision)
+	r.handle(http.MethodGet, routeRevisionDiff, KindRead, r.ArticleCtrl.DiffRevisions)
+	r.handle(http.MethodPost, routeRevisionRestore, KindModify, r.ArticleCtrl.RestoreRevision)
+	r.handle(http.MethodPost, routeRenew, KindModify, r.ArticleCtrl.Renew)
+	if r.Events {
+		r.handle(http.MethodGet, routeEvents, KindRead, r.ArticleCtrl.Events)
+	}
+	if r.AuditCtrl != nil {
+		r.handle(http.MethodGet, routeAudit, KindAdmin, r.AuditCtrl.Find)
+	}
+	if r.WebhookCtrl != nil {
+		r.handle(http.MethodPost, routeWebhooks, KindAdmin, r.WebhookCtrl.Create)
+		r.handle(http.MethodGet, routeWebhooks, KindAdmin, r.WebhookCtrl.Find)
+		r.handle(http.MethodDelete, routeWebhook, KindAdmin, r.WebhookCtrl.Delete)
+		r.handle(http.MethodGet, routeDeliveries, KindAdmin, r.WebhookCtrl.FindDeliveries)
+		r.handle(http.MethodPost, routeDeliveryRetry, KindAdmin, r.WebhookCtrl.RetryDelivery)
+	}
+	if r.RpcCtrl != nil {
+		// the calls are charged and authorized one by one, so reads need no credentials
+		r.handle(http.MethodPost, routeRpc, KindBatch, r.RpcCtrl.Handle)
+	}
+	if r.GraphqlCtrl != nil {
+		// queries are reads; the controller rejects anonymous mutations when credentials are required
+		r.handle(http.MethodPost, routeGraphql, KindRead, r.GraphqlCtrl.Handle)
+	}
 
-	return nil
+	served := r.served()
+	doc, err := document(served, r.Secured)
+	if err != nil {
+		return err
+	}
+	if r.Validator != nil {
+		r.Validator.Doc = doc
+	}
+
+	for _, route := range served {
+		handlers := append([]gin.HandlerFunc{}, route.middleware...)
+		handlers = append(handlers, r.Middleware.of(route.kind)...)
+		if r.Validator != nil {
+			// after the middleware, so unauthenticated and rate limited requests are rejected first
+			validate, err := r.Validator.Middleware(route.method, route.path)
+			if err != nil {
+				return err
+			}
+			handlers = append(handlers, validate)
+		}
+		r.Engine.Handle(route.method, route.path, append(handlers, route.handler)...)
+	}
+
+	return r.addDocs(doc)
+}
+
+// Records a route of the first version with its documentation; it is registered by addRoutes
+func (r *Router) handle(method string, path string, kind RouteKind, handler gin.HandlerFunc) {
+	operation, ok := operations[routeKey{method, path}]
+	r.routes = append(r.routes, route{method: method, path: path, kind: kind, handler: handler, operation: operation, documented: ok})
+}
+
+// Appends the handler to the middleware of the route
+func chain(middleware []gin.HandlerFunc, handler gin.HandlerFunc) []gin.HandlerFunc {
+	handlers := make([]gin.HandlerFunc, 0, len(middleware)+1)
+	handlers = append(handlers, middleware...)
+	return append(handlers, handler)
 }
 
 func (r *Router) Run(addr ...string) {
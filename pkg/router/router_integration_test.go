@@ -1,6 +1,7 @@
 package router
 
 import (
+	"article-management-service/pkg/auth"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -20,6 +21,7 @@ import (
 	"article-management-service/pkg/controller"
 	"article-management-service/pkg/db"
 	"article-management-service/pkg/env"
+	"article-management-service/pkg/openapi"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -96,6 +98,7 @@ func initializeServer(t *testing.T) (*gin.Engine, func()) {
 	engine := gin.Default()
 
 	engine.SetTrustedProxies(nil)
+	engine.Use(auth.Disabled())
 
 	dbHandler := &db.ArticleDbHandler{}
 	err = dbHandler.New(conn.Database)
@@ -112,7 +115,15 @@ func initializeServer(t *testing.T) (*gin.Engine, func()) {
 	}
 
 	router := NewRouter(articleController, engine)
-	router.Init()
+	router.Validator = &openapi.Validator{
+		ValidateResponses: true,
+		OnResponseError: func(c *gin.Context, err error) {
+			t.Errorf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		},
+	}
+	if err := router.Init(); err != nil {
+		panic(err)
+	}
 
 	return engine, func() {
 		conn.Close()
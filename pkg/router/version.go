@@ -0,0 +1,139 @@
+package router
+
+import (
+	"article-management-service/pkg/openapi"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is a version of the api served under /<Name>. Every version serves the routes of the version before,
+// so a version only holds the routes it changes, e.g. a breaking change of a response.
+type Version struct {
+	Name        string         // the path prefix, e.g. v2
+	Deprecation *Deprecation   // nil while the version is supported
+	Routes      []VersionRoute // replace the routes of the version before with the same method and path, or add routes
+}
+
+// VersionRoute is a route of a version; the path is without the version, e.g. /article
+type VersionRoute struct {
+	Method    string
+	Path      string
+	Kind      RouteKind
+	Handler   gin.HandlerFunc
+	Operation openapi.Operation // the documentation; the method and path are the ones of the route
+}
+
+// Deprecation announces that a version is going away with the Deprecation (RFC 9745) and Sunset (RFC 8594)
+// headers, and links the same route in the next version
+type Deprecation struct {
+	Date   time.Time // since when the version is deprecated
+	Sunset time.Time // when the version is removed; zero when not planned yet
+}
+
+// servedRoute is a route as served under the prefix of its version
+type servedRoute struct {
+	route
+	prefix     string
+	middleware []gin.HandlerFunc // before the middleware of the kind of route
+	deprecated bool
+}
+
+// The routes of every version and the unversioned routes at the root
+func (r *Router) served() []servedRoute {
+	versions := r.Versions
+	if len(versions) == 0 {
+		versions = []Version{{Name: "v1"}}
+	}
+
+	var served []servedRoute
+	routes := r.routes
+	for i, version := range versions {
+		routes = withVersionRoutes(routes, version.Routes)
+		successor := ""
+		if i+1 < len(versions) {
+			successor = "/" + versions[i+1].Name
+		}
+
+		prefix := "/" + version.Name
+		for _, route := range routes {
+			served = append(served, serve(route, prefix, version.Deprecation, successor))
+		}
+
+		if i == 0 {
+			for _, route := range routes {
+				served = append(served, serve(route, "", r.Unversioned, prefix))
+			}
+		}
+	}
+	return served
+}
+
+func serve(route route, prefix string, deprecation *Deprecation, successor string) servedRoute {
+	served := servedRoute{route: route, prefix: prefix, deprecated: deprecation != nil}
+	served.path = prefix + route.path
+	if prefix != "" {
+		served.operation.Id = strings.TrimPrefix(prefix, "/") + upperFirst(route.operation.Id)
+	}
+	if deprecation != nil {
+		served.middleware = []gin.HandlerFunc{deprecated(*deprecation, prefix, successor)}
+	}
+	return served
+}
+
+// Replaces the routes with the same method and path and appends the new ones
+func withVersionRoutes(routes []route, versionRoutes []VersionRoute) []route {
+	replaced := append([]route{}, routes...)
+	for _, versionRoute := range versionRoutes {
+		route := route{
+			method:     versionRoute.Method,
+			path:       versionRoute.Path,
+			kind:       versionRoute.Kind,
+			handler:    versionRoute.Handler,
+			operation:  versionRoute.Operation,
+			documented: true,
+		}
+
+		found := false
+		for i := range replaced {
+			if replaced[i].method == route.method && replaced[i].path == route.path {
+				replaced[i] = route
+				found = true
+			}
+		}
+		if !found {
+			replaced = append(replaced, route)
+		}
+	}
+	return replaced
+}
+
+// Sets the deprecation headers of a route of the version with the prefix; the successor is the prefix
+// of the next version
+func deprecated(deprecation Deprecation, prefix string, successor string) gin.HandlerFunc {
+	date := "@" + strconv.FormatInt(deprecation.Date.Unix(), 10)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", date)
+		if !deprecation.Sunset.IsZero() {
+			c.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if successor != "" {
+			c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, strings.TrimPrefix(c.Request.URL.Path, prefix)))
+		}
+		c.Next()
+	}
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
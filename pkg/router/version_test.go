@@ -0,0 +1,130 @@
+package router
+
+import (
+	"article-management-service/pkg/controller"
+	"article-management-service/pkg/openapi"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// articleController answers Find with 418, so tests can tell which handler served a request
+type articleController struct {
+	controller.ArticleController
+}
+
+func (c *articleController) Find(context *gin.Context) {
+	context.JSON(http.StatusTeapot, []string{"v1"})
+}
+
+func TestRouter_Versions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deprecation := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	v2Find := VersionRoute{
+		Method: http.MethodGet,
+		Path:   routeFindArticles,
+		Kind:   KindRead,
+		Handler: func(c *gin.Context) {
+			c.JSON(http.StatusTeapot, []gin.H{{"title": "v2"}})
+		},
+		Operation: openapi.Operation{
+			Id:        "findArticles",
+			Summary:   "Lists the articles",
+			Responses: []openapi.Response{{Status: http.StatusTeapot, Body: []controller.ExportedArticle{}}},
+		},
+	}
+	router := &Router{
+		ArticleCtrl: &articleController{},
+		Engine:      gin.New(),
+		Versions: []Version{
+			{Name: "v1", Deprecation: &Deprecation{Date: deprecation}},
+			{Name: "v2", Routes: []VersionRoute{v2Find}},
+		},
+		Unversioned: &Deprecation{Date: deprecation, Sunset: sunset},
+	}
+	if err := router.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name                string
+		path                string
+		expectedBody        string
+		expectedDeprecation string
+		expectedSunset      string
+		expectedLink        string
+	}{
+		{
+			name:                "Serve the first version at the root with deprecation headers",
+			path:                "/article?withImages=true",
+			expectedBody:        `["v1"]`,
+			expectedDeprecation: "@1704067200",
+			expectedSunset:      "Wed, 01 Jan 2025 00:00:00 GMT",
+			expectedLink:        `</v1/article>; rel="successor-version"`,
+		},
+		{
+			name:                "Serve a deprecated version",
+			path:                "/v1/article",
+			expectedBody:        `["v1"]`,
+			expectedDeprecation: "@1704067200",
+			expectedLink:        `</v2/article>; rel="successor-version"`,
+		},
+		{
+			name:         "Serve the replaced route of the next version",
+			path:         "/v2/article",
+			expectedBody: `[{"title":"v2"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.Engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != http.StatusTeapot || recorder.Body.String() != tt.expectedBody {
+				t.Errorf("Response = %d %s, expected %d %s", recorder.Code, recorder.Body.String(), http.StatusTeapot, tt.expectedBody)
+			}
+			if deprecation := recorder.Header().Get("Deprecation"); deprecation != tt.expectedDeprecation {
+				t.Errorf("Deprecation = %q, expected %q", deprecation, tt.expectedDeprecation)
+			}
+			if sunset := recorder.Header().Get("Sunset"); sunset != tt.expectedSunset {
+				t.Errorf("Sunset = %q, expected %q", sunset, tt.expectedSunset)
+			}
+			if link := recorder.Header().Get("Link"); link != tt.expectedLink {
+				t.Errorf("Link = %q, expected %q", link, tt.expectedLink)
+			}
+		})
+	}
+
+	t.Run("Document every version", func(t *testing.T) {
+		doc, err := router.Document()
+		if err != nil {
+			t.Fatalf("Document() error = %v", err)
+		}
+		if err := doc.Validate(context.Background()); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+
+		for path, deprecated := range map[string]bool{"/article": true, "/v1/article": true, "/v2/article": false, "/v2/article/{articleId}": false} {
+			pathItem := doc.Paths.Find(path)
+			if pathItem == nil {
+				t.Errorf("%s is not documented", path)
+				continue
+			}
+			for _, operation := range pathItem.Operations() {
+				if operation.Deprecated != deprecated {
+					t.Errorf("%s deprecated = %v, expected %v", path, operation.Deprecated, deprecated)
+				}
+			}
+		}
+		if id := doc.Paths.Find("/v2/article").Get.OperationID; id != "v2FindArticles" {
+			t.Errorf("OperationID = %q, expected v2FindArticles", id)
+		}
+	})
+}
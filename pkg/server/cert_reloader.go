@@ -0,0 +1,180 @@
+package server
+
+import (
+	"article-management-service/pkg/logging"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+// CertReloader serves the certificate (and client CA pool) from disk and swaps it when the files change or
+// on SIGHUP. Every new handshake picks up the current certificate, so open connections are not dropped.
+type CertReloader struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string // optional; only needed for mTLS
+	Logger       *logrus.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+func NewCertReloader(certFile string, keyFile string, clientCAFile string, logger *logrus.Logger) (*CertReloader, error) {
+	r := &CertReloader{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: clientCAFile,
+		Logger:       logger,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate, key and client CAs from disk; on failure the previous ones stay in use
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("loading client CA: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("client CA file contains no certificates")
+		}
+	}
+
+	modTimes, err := r.readModTimes()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *CertReloader) files() []string {
+	files := []string{r.CertFile, r.KeyFile}
+	if r.ClientCAFile != "" {
+		files = append(files, r.ClientCAFile)
+	}
+	return files
+}
+
+func (r *CertReloader) readModTimes() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// Changed reports whether any of the files has been modified since the last successful reload
+func (r *CertReloader) Changed() bool {
+	modTimes, err := r.readModTimes()
+	if err != nil {
+		// files are likely being replaced; try again on the next tick
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch reloads the certificates on SIGHUP and when the files change on disk, until the context is done
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	logger := logging.OrDefault(r.Logger)
+
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		case <-ticker.C:
+			if !r.Changed() {
+				continue
+			}
+		}
+
+		if err := r.Reload(); err != nil {
+			logger.WithError(err).Error("failed to reload certificates; keeping the previous ones")
+			continue
+		}
+		logger.Info("reloaded certificates")
+	}
+}
+
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// TLSConfig returns a config that always serves the current certificate and, for mTLS, verifies
+// client certificates against the current client CA pool.
+func (r *CertReloader) TLSConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: r.GetCertificate,
+		ClientAuth:     clientAuth,
+	}
+
+	if clientAuth != tls.NoClientCert {
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			perConn := config.Clone()
+			perConn.GetConfigForClient = nil
+			perConn.ClientCAs = r.clientCAs
+			return perConn, nil
+		}
+	}
+	return config
+}
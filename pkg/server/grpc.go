@@ -0,0 +1,82 @@
+package server
+
+import (
+	"article-management-service/pkg/logging"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// GrpcServer serves gRPC services on their own port, over TLS with the certificate of the HTTP server when configured
+type GrpcServer struct {
+	Addr     string
+	Register func(server *grpc.Server) // registers the services
+	Options  []grpc.ServerOption       // e.g. the interceptors
+	TLS      *TLSConfig
+	Logger   *logrus.Logger
+}
+
+// Run serves until the context is done, after which open calls get time to finish.
+func (s *GrpcServer) Run(ctx context.Context) error {
+	logger := logging.OrDefault(s.Logger)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	options := append([]grpc.ServerOption{}, s.Options...)
+	if s.TLS != nil {
+		clientAuth, err := ParseClientAuth(s.TLS.ClientAuth)
+		if err != nil {
+			return err
+		}
+		if clientAuth != tls.NoClientCert && s.TLS.ClientCAFile == "" {
+			return errors.New("client certificates require a client CA file")
+		}
+
+		reloader, err := NewCertReloader(s.TLS.CertFile, s.TLS.KeyFile, s.TLS.ClientCAFile, s.Logger)
+		if err != nil {
+			return err
+		}
+		go reloader.Watch(ctx, s.TLS.ReloadInterval)
+
+		config := reloader.TLSConfig(clientAuth)
+		config.NextProtos = []string{"h2"}
+		options = append(options, grpc.Creds(credentials.NewTLS(config)))
+	}
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer(options...)
+	s.Register(server)
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.Serve(listener) }()
+	logger.WithFields(logrus.Fields{"addr": listener.Addr().String(), "tls": s.TLS != nil}).Info("serving gRPC")
+
+	select {
+	case <-ctx.Done():
+	case err := <-errs:
+		return err
+	}
+
+	// streams that outlive the shutdown timeout are cut off
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		server.Stop()
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"article-management-service/pkg/logging"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request" // verify a client certificate when one is given
+	ClientAuthRequire = "require" // every client needs a valid certificate
+
+	PlainHTTPRedirect = "redirect"
+	PlainHTTPReject   = "reject"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+type TLSConfig struct {
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string
+	ClientAuth     string
+	ReloadInterval time.Duration
+}
+
+// Server serves the handler over HTTP, or over HTTPS (with HTTP/2) when TLS is configured.
+// With TLS an optional plain HTTP listener redirects or rejects clients that did not use HTTPS.
+type Server struct {
+	Handler       http.Handler
+	Addr          string
+	TLS           *TLSConfig
+	PlainHTTPAddr string
+	PlainHTTPMode string
+	Logger        *logrus.Logger
+}
+
+// ParseClientAuth maps the configured client auth mode to the tls package's type
+func ParseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case ClientAuthNone, "":
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("unknown client auth mode %q", mode)
+}
+
+// Run serves until the context is done, after which open requests get time to finish.
+func (s *Server) Run(ctx context.Context) error {
+	logger := logging.OrDefault(s.Logger)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	srv := &http.Server{Addr: s.Addr, Handler: s.Handler, ReadHeaderTimeout: 10 * time.Second}
+	servers := []*http.Server{srv}
+	errs := make(chan error, 2)
+
+	if s.TLS == nil {
+		go func() { errs <- srv.ListenAndServe() }()
+		logger.WithField("addr", s.Addr).Info("serving HTTP")
+	} else {
+		clientAuth, err := ParseClientAuth(s.TLS.ClientAuth)
+		if err != nil {
+			return err
+		}
+		if clientAuth != tls.NoClientCert && s.TLS.ClientCAFile == "" {
+			return errors.New("client certificates require a client CA file")
+		}
+
+		reloader, err := NewCertReloader(s.TLS.CertFile, s.TLS.KeyFile, s.TLS.ClientCAFile, s.Logger)
+		if err != nil {
+			return err
+		}
+		go reloader.Watch(ctx, s.TLS.ReloadInterval)
+
+		var plain *http.Server
+		if s.PlainHTTPAddr != "" {
+			plainHandler, err := s.plainHTTPHandler()
+			if err != nil {
+				return err
+			}
+			plain = &http.Server{Addr: s.PlainHTTPAddr, Handler: plainHandler, ReadHeaderTimeout: 10 * time.Second}
+		}
+
+		// both addresses are bound before anything is served, so a busy port leaves no server running
+		listener, err := net.Listen("tcp", s.Addr)
+		if err != nil {
+			return err
+		}
+		var plainListener net.Listener
+		if plain != nil {
+			if plainListener, err = net.Listen("tcp", s.PlainHTTPAddr); err != nil {
+				listener.Close()
+				return err
+			}
+			servers = append(servers, plain)
+		}
+
+		srv.TLSConfig = reloader.TLSConfig(clientAuth)
+		go func() { errs <- srv.ServeTLS(listener, "", "") }()
+		logger.WithField("addr", s.Addr).Info("serving HTTPS")
+
+		if plain != nil {
+			go func() { errs <- plain.Serve(plainListener) }()
+			logger.WithFields(logrus.Fields{"addr": s.PlainHTTPAddr, "mode": s.PlainHTTPMode}).Info("serving plain HTTP")
+		}
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errs:
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	for _, srv := range servers {
+		if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) plainHTTPHandler() (http.Handler, error) {
+	switch s.PlainHTTPMode {
+	case PlainHTTPRedirect, "":
+		_, port, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return nil, err
+		}
+		return RedirectToHTTPS(port), nil
+	case PlainHTTPReject:
+		return RejectPlainHTTP(), nil
+	}
+	return nil, fmt.Errorf("unknown plain HTTP mode %q", s.PlainHTTPMode)
+}
+
+// RedirectToHTTPS permanently redirects every request to the same url on the HTTPS port
+func RedirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		// 308 keeps the method and body of e.g. a POST
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// RejectPlainHTTP answers every request with 426, telling the client to switch to TLS
+func RejectPlainHTTP() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Upgrade", "TLS/1.2, HTTP/1.1")
+		w.Header().Set("Connection", "Upgrade")
+		http.Error(w, "HTTPS is required", http.StatusUpgradeRequired)
+	})
+}
@@ -0,0 +1,310 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type testCert struct {
+	certPEM []byte
+	keyPEM  []byte
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+}
+
+// creates a certificate for localhost; signed by the parent, or self-signed when there is no parent
+func createCert(t *testing.T, serial int64, isCA bool, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	return &testCert{
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		cert:    cert,
+		key:     key,
+	}
+}
+
+func writeCert(t *testing.T, dir string, c *testCert) (certFile string, keyFile string) {
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, c.certPEM, 0600); err != nil {
+		t.Fatalf("Failed to write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, c.keyPEM, 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return
+}
+
+func freeAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// starts the server and waits until it accepts connections
+func startServer(t *testing.T, s *Server) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Server.Run() error = %v, wantErr %v", err, false)
+		}
+	})
+
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", s.Addr); err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Server did not start listening on %s", s.Addr)
+}
+
+func servedSerial(addr string, config *tls.Config) (int64, error) {
+	conn, err := tls.Dial("tcp", addr, config)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if err := conn.Handshake(); err != nil {
+		return 0, err
+	}
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+func TestServer_TLS(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("Serve HTTP/2 and hot-reload the certificate", func(t *testing.T) {
+		dir := t.TempDir()
+		first := createCert(t, 1, false, nil)
+		certFile, keyFile := writeCert(t, dir, first)
+
+		s := &Server{
+			Handler: handler,
+			Addr:    freeAddr(t),
+			TLS:     &TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: 20 * time.Millisecond},
+		}
+		startServer(t, s)
+
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		}}
+		resp, err := client.Get("https://" + s.Addr + "/")
+		if err != nil {
+			t.Fatalf("GET error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.ProtoMajor != 2 {
+			t.Errorf("Expected HTTP/2, got %s", resp.Proto)
+		}
+
+		// ensure a different modification time, even on file systems with a coarse resolution
+		time.Sleep(10 * time.Millisecond)
+		second := createCert(t, 2, false, nil)
+		writeCert(t, dir, second)
+		future := time.Now().Add(time.Second)
+		os.Chtimes(certFile, future, future)
+
+		for i := 0; i < 100; i++ {
+			serial, err := servedSerial(s.Addr, &tls.Config{InsecureSkipVerify: true})
+			if err == nil && serial == 2 {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Errorf("Expected the reloaded certificate to be served")
+	})
+
+	t.Run("Require client certificates", func(t *testing.T) {
+		dir := t.TempDir()
+		ca := createCert(t, 10, true, nil)
+		serverCert := createCert(t, 11, false, ca)
+		clientCert := createCert(t, 12, false, ca)
+		certFile, keyFile := writeCert(t, dir, serverCert)
+		caFile := filepath.Join(dir, "ca.pem")
+		os.WriteFile(caFile, ca.certPEM, 0600)
+
+		s := &Server{
+			Handler: handler,
+			Addr:    freeAddr(t),
+			TLS:     &TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: ClientAuthRequire},
+		}
+		startServer(t, s)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+
+		noCertClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		if resp, err := noCertClient.Get("https://" + s.Addr + "/"); err == nil {
+			resp.Body.Close()
+			t.Errorf("Expected the request without client certificate to fail")
+		}
+
+		keyPair, err := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+		if err != nil {
+			t.Fatalf("Failed to load client key pair: %v", err)
+		}
+		certClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{keyPair}}}}
+		resp, err := certClient.Get("https://" + s.Addr + "/")
+		if err != nil {
+			t.Fatalf("GET with client certificate error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status %d; got %d", http.StatusOK, resp.StatusCode)
+		}
+	})
+}
+
+func TestServer_PlainHTTPFailure(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, createCert(t, 20, false, nil))
+
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to occupy a port: %v", err)
+	}
+	defer occupied.Close()
+
+	tests := []struct {
+		name      string
+		plainAddr string
+		plainMode string
+	}{
+		{name: "Prevent serving HTTPS with an unknown plain HTTP mode", plainAddr: freeAddr(t), plainMode: "bogus"},
+		{name: "Prevent serving HTTPS when the plain listener fails", plainAddr: occupied.Addr().String(), plainMode: PlainHTTPReject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{
+				Handler:       http.NotFoundHandler(),
+				Addr:          freeAddr(t),
+				TLS:           &TLSConfig{CertFile: certFile, KeyFile: keyFile},
+				PlainHTTPAddr: tt.plainAddr,
+				PlainHTTPMode: tt.plainMode,
+				Logger:        logrus.New(),
+			}
+			var logged bytes.Buffer
+			s.Logger.SetOutput(&logged)
+
+			done := make(chan error, 1)
+			go func() { done <- s.Run(context.Background()) }()
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Fatalf("Server.Run() error = %v, wantErr %v", err, true)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Server.Run() kept serving")
+			}
+
+			if strings.Contains(logged.String(), "serving HTTPS") {
+				t.Error("HTTPS was served before the plain listener failed")
+			}
+			// no HTTPS server is left behind
+			for i := 0; i < 20; i++ {
+				if conn, err := net.Dial("tcp", s.Addr); err == nil {
+					conn.Close()
+					t.Fatal("HTTPS is served after Server.Run() returned")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		host     string
+		expected string
+	}{
+		{name: "Keep non default port", port: "5443", host: "example.com:5000", expected: "https://example.com:5443/article?withImages=true"},
+		{name: "Omit default port", port: "443", host: "example.com", expected: "https://example.com/article?withImages=true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "http://"+tt.host+"/article?withImages=true", nil)
+			response := httptest.NewRecorder()
+			RedirectToHTTPS(tt.port).ServeHTTP(response, req)
+
+			if response.Code != http.StatusPermanentRedirect {
+				t.Errorf("Expected status %d; got %d", http.StatusPermanentRedirect, response.Code)
+			}
+			if location := response.Header().Get("Location"); location != tt.expected {
+				t.Errorf("RedirectToHTTPS() = %v, want %v", location, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRejectPlainHTTP(t *testing.T) {
+	response := httptest.NewRecorder()
+	RejectPlainHTTP().ServeHTTP(response, httptest.NewRequest("GET", "/article", nil))
+
+	if response.Code != http.StatusUpgradeRequired {
+		t.Errorf("Expected status %d; got %d", http.StatusUpgradeRequired, response.Code)
+	}
+}
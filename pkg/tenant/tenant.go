@@ -0,0 +1,118 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Default is the tenant of requests that do not name one; articles created before multi-tenancy belong to it
+const Default = "default"
+
+// tenant ids end up in file paths, so they are restricted to a safe set of characters
+var validId = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type tenantKey struct{}
+
+var (
+	// ErrInvalid is a tenant id outside of the safe set of characters
+	ErrInvalid = errors.New("invalid tenant id")
+	// ErrForbidden is a requested tenant that contradicts the identity or is not allowed to be requested
+	ErrForbidden = errors.New("tenant not allowed")
+	// ErrInvalidIdentity is an invalid tenant of the identity, e.g. of a token claim; it wraps ErrInvalid
+	ErrInvalidIdentity = fmt.Errorf("%w of the identity", ErrInvalid)
+)
+
+func IsValid(tenantId string) bool {
+	return validId.MatchString(tenantId)
+}
+
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantId)
+}
+
+// Resolve returns the tenant of a request from the tenant of its identity and the requested one, e.g. of the
+// X-Tenant-ID header; empty when neither names one. The tenant of the identity wins; the requested one is only
+// used when allowed and may not contradict the identity. Both have to be valid.
+func Resolve(identityTenant string, requested string, requestAllowed bool) (string, error) {
+	if identityTenant != "" && !IsValid(identityTenant) {
+		return "", ErrInvalidIdentity
+	}
+	if requested == "" {
+		return identityTenant, nil
+	}
+	if !IsValid(requested) {
+		return "", ErrInvalid
+	}
+
+	switch {
+	case identityTenant != "" && requested != identityTenant:
+		return "", ErrForbidden
+	case identityTenant == "" && !requestAllowed:
+		return "", ErrForbidden
+	}
+	return requested, nil
+}
+
+// FromContext returns the tenant of the request; Default when none was resolved
+func FromContext(ctx context.Context) string {
+	if ctx != nil {
+		if tenantId, ok := ctx.Value(tenantKey{}).(string); ok && tenantId != "" {
+			return tenantId
+		}
+	}
+	return Default
+}
+
+// Quota limits what a tenant can store; 0 means unlimited
+type Quota struct {
+	MaxArticles int64
+	MaxImages   int64
+}
+
+type Quotas struct {
+	Default   Quota
+	PerTenant map[string]Quota
+}
+
+func (q Quotas) For(tenantId string) Quota {
+	if quota, ok := q.PerTenant[tenantId]; ok {
+		return quota
+	}
+	return q.Default
+}
+
+// ParseQuotas parses per tenant overrides in the form "teamA=100/300,teamB=50/0" (max articles/max images)
+func ParseQuotas(defaultQuota Quota, overrides string) (Quotas, error) {
+	quotas := Quotas{Default: defaultQuota, PerTenant: make(map[string]Quota)}
+	if strings.TrimSpace(overrides) == "" {
+		return quotas, nil
+	}
+
+	for _, override := range strings.Split(overrides, ",") {
+		tenantId, limits, found := strings.Cut(strings.TrimSpace(override), "=")
+		if !found || !IsValid(tenantId) {
+			return quotas, fmt.Errorf("invalid tenant quota %q", override)
+		}
+
+		articles, images, found := strings.Cut(limits, "/")
+		if !found {
+			return quotas, fmt.Errorf("invalid tenant quota %q", override)
+		}
+
+		maxArticles, err := strconv.ParseInt(articles, 10, 64)
+		if err != nil {
+			return quotas, fmt.Errorf("invalid tenant quota %q: %w", override, err)
+		}
+		maxImages, err := strconv.ParseInt(images, 10, 64)
+		if err != nil {
+			return quotas, fmt.Errorf("invalid tenant quota %q: %w", override, err)
+		}
+
+		quotas.PerTenant[tenantId] = Quota{MaxArticles: maxArticles, MaxImages: maxImages}
+	}
+	return quotas, nil
+}
@@ -0,0 +1,83 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseQuotas(t *testing.T) {
+	defaultQuota := Quota{MaxArticles: 10, MaxImages: 30}
+
+	tests := []struct {
+		name      string
+		overrides string
+		want      map[string]Quota
+		wantErr   bool
+	}{
+		{name: "No overrides", overrides: "", want: map[string]Quota{}},
+		{name: "Multiple overrides", overrides: "team-a=100/300, team_b=50/0", want: map[string]Quota{
+			"team-a": {MaxArticles: 100, MaxImages: 300},
+			"team_b": {MaxArticles: 50, MaxImages: 0},
+		}},
+		{name: "Reject missing images", overrides: "team-a=100", wantErr: true},
+		{name: "Reject invalid tenant", overrides: "../a=1/1", wantErr: true},
+		{name: "Reject non numeric limit", overrides: "team-a=many/1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotas, err := ParseQuotas(defaultQuota, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseQuotas() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(quotas.PerTenant, tt.want) {
+				t.Errorf("ParseQuotas() = %v, want %v", quotas.PerTenant, tt.want)
+			}
+			if quotas.For("unknown") != defaultQuota {
+				t.Errorf("Quotas.For() = %v, want %v", quotas.For("unknown"), defaultQuota)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != Default {
+		t.Errorf("FromContext() = %v, want %v", got, Default)
+	}
+
+	if got := FromContext(WithTenant(context.Background(), "team-a")); got != "team-a" {
+		t.Errorf("FromContext() = %v, want %v", got, "team-a")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name           string
+		identityTenant string
+		requested      string
+		requestAllowed bool
+		expected       string
+		expectedErr    error
+	}{
+		{name: "Successfully resolve the tenant of the identity", identityTenant: "team-a", expected: "team-a"},
+		{name: "Successfully resolve an allowed requested tenant", requested: "team-b", requestAllowed: true, expected: "team-b"},
+		{name: "Prevent an invalid tenant of the identity", identityTenant: "../../etc", expectedErr: ErrInvalidIdentity},
+		{name: "Prevent an invalid tenant of the identity matching the request", identityTenant: "../../etc", requested: "../../etc", expectedErr: ErrInvalidIdentity},
+		{name: "Prevent an invalid requested tenant", requested: "../team-b", requestAllowed: true, expectedErr: ErrInvalid},
+		{name: "Prevent a requested tenant contradicting the identity", identityTenant: "team-a", requested: "team-b", requestAllowed: true, expectedErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := Resolve(tt.identityTenant, tt.requested, tt.requestAllowed)
+			if !errors.Is(err, tt.expectedErr) || found != tt.expected {
+				t.Errorf("Resolve() = %v, %v, want %v, %v", found, err, tt.expected, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp" // configured through the standard OTEL_EXPORTER_OTLP_* environment variables
+	ExporterFile = "file"
+)
+
+const ServiceName = "article-management-service"
+
+// Setup installs the W3C trace context propagator and a global tracer provider exporting to the given exporter.
+// The returned function flushes and stops the exporter; it should be called before the process exits.
+func Setup(ctx context.Context, exporter string, filePath string) (func(context.Context) error, error) {
+	// traceparent and tracestate are always accepted and propagated, also when no spans are exported
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	closeFile := func() error { return nil }
+
+	switch exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		otlpExporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		spanExporter = otlpExporter
+	case ExporterFile:
+		file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		fileExporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		spanExporter = fileExporter
+		closeFile = file.Close
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeErr := closeFile(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
+
+// Tracer returns a tracer of the global tracer provider for the given instrumentation scope.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
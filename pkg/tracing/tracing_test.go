@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("Export spans to a local file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.json")
+
+		shutdown, err := Setup(context.Background(), ExporterFile, path)
+		if err != nil {
+			t.Fatalf("Setup() error = %v, wantErr %v", err, false)
+		}
+
+		_, span := Tracer("test").Start(context.Background(), "test-span")
+		span.End()
+
+		if err := shutdown(context.Background()); err != nil {
+			t.Fatalf("shutdown() error = %v, wantErr %v", err, false)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read trace file: %v", err)
+		}
+
+		if !strings.Contains(string(content), "test-span") {
+			t.Errorf("Setup() trace file does not contain the span: %s", content)
+		}
+	})
+
+	t.Run("Always install the trace context propagator", func(t *testing.T) {
+		_, err := Setup(context.Background(), ExporterNone, "")
+		if err != nil {
+			t.Fatalf("Setup() error = %v, wantErr %v", err, false)
+		}
+
+		fields := otel.GetTextMapPropagator().Fields()
+		if !strings.Contains(strings.Join(fields, ","), "traceparent") {
+			t.Errorf("Setup() propagator fields = %v, want traceparent", fields)
+		}
+	})
+
+	t.Run("Reject unknown exporter", func(t *testing.T) {
+		if _, err := Setup(context.Background(), "unknown", ""); err == nil {
+			t.Errorf("Setup() error = %v, wantErr %v", err, true)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package webhook
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	defaultConcurrency = 8
+	// how long a claimed delivery is skipped by other instances; longer than the timeout of a request
+	claimLease = time.Minute
+	// how much of the response of the receiver is read, so the connection can be reused
+	maxResponseSize = 64 << 10
+)
+
+// Deliverer sends the pending deliveries to the webhooks. Failed deliveries are retried with exponential
+// backoff; after the maximum attempts they are dead and stay in the history until they are retried manually.
+type Deliverer struct {
+	WebhookDbHandler         db.WebhookDbHandlerInterface
+	WebhookDeliveryDbHandler db.WebhookDeliveryDbHandlerInterface
+	Client                   *http.Client  // defaults to NewClient with a 10 second timeout, which only reaches public addresses
+	MaxAttempts              int           // per round; a retried dead delivery gets a new round
+	Concurrency              int           // how many webhooks are delivered to at once; defaults to 8
+	BackoffBase              time.Duration // the delay before the second attempt, doubled for each further attempt
+	BackoffMax               time.Duration // 0 does not cap the delay
+	Retention                time.Duration // how long final deliveries are kept; 0 keeps them forever
+	Logger                   *logrus.Logger
+	Now                      func() time.Time // defaults to time.Now
+}
+
+// Backoff returns the delay after the given failed attempt: base, 2*base, 4*base, ... capped at max
+func Backoff(attempt int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if (max > 0 && delay >= max) || delay <= 0 {
+			return max
+		}
+	}
+	if max > 0 && delay > max {
+		return max
+	}
+	return delay
+}
+
+// Run delivers the due deliveries every interval until the context is done
+func (d *Deliverer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			logging.OrDefault(d.Logger).WithError(err).Error("failed to deliver the webhooks")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue delivers every due delivery once and returns the amount of attempts. Up to Concurrency webhooks are
+// delivered to at once, the deliveries of one webhook one after another, so a slow receiver only holds up its own.
+func (d *Deliverer) DeliverDue(ctx context.Context) (int, error) {
+	concurrency := d.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	type result struct {
+		webhookId primitive.ObjectID
+		err       error
+	}
+	results := make(chan result, concurrency)
+	busy := make(map[primitive.ObjectID]bool)
+	inFlight, attempts := 0, 0
+	var firstErr error
+
+	// waits for the attempt of one webhook to finish
+	wait := func() {
+		r := <-results
+		inFlight--
+		delete(busy, r.webhookId)
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
+		} else if r.err == nil {
+			attempts++
+		}
+	}
+
+	for ctx.Err() == nil && firstErr == nil {
+		if inFlight == concurrency {
+			wait()
+			continue
+		}
+
+		skip := make([]primitive.ObjectID, 0, len(busy))
+		for webhookId := range busy {
+			skip = append(skip, webhookId)
+		}
+		delivery, err := d.WebhookDeliveryDbHandler.Claim(ctx, d.now(), claimLease, skip)
+		if err != nil {
+			firstErr = err
+			break
+		}
+		if delivery == nil {
+			if inFlight == 0 {
+				break
+			}
+			// the busy webhooks may have further due deliveries
+			wait()
+			continue
+		}
+
+		busy[delivery.WebhookId] = true
+		inFlight++
+		go func(delivery db.WebhookDeliveryDb) {
+			results <- result{webhookId: delivery.WebhookId, err: d.Deliver(ctx, delivery)}
+		}(*delivery)
+	}
+
+	for inFlight > 0 {
+		wait()
+	}
+	if firstErr != nil {
+		return attempts, firstErr
+	}
+	return attempts, ctx.Err()
+}
+
+// Deliver makes one attempt to deliver and records its outcome
+func (d *Deliverer) Deliver(ctx context.Context, delivery db.WebhookDeliveryDb) error {
+	ctx = tenant.WithTenant(ctx, delivery.TenantId)
+
+	webhook, err := d.WebhookDbHandler.FindOneById(ctx, delivery.WebhookId)
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+	attempt := db.WebhookAttemptDb{Time: now}
+	if webhook == nil {
+		attempt.Error = "the webhook was deleted"
+		delivery.Status = db.DeliveryStatusDead
+	} else {
+		attempt.StatusCode, err = d.send(ctx, *webhook, delivery, now)
+		attempt.Duration = d.now().Sub(now)
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		delivery.Status = d.statusAfter(delivery.RoundAttempts+1, err == nil)
+	}
+	delivery.RoundAttempts++
+
+	if delivery.Status == db.DeliveryStatusPending {
+		delivery.NextAttemptAt = now.Add(Backoff(delivery.RoundAttempts, d.BackoffBase, d.BackoffMax))
+	} else if d.Retention > 0 {
+		expireAt := now.Add(d.Retention)
+		delivery.ExpireAt = &expireAt
+	}
+
+	logging.FromContext(ctx, d.Logger).WithFields(logrus.Fields{
+		"webhookId":  delivery.WebhookId.Hex(),
+		"deliveryId": delivery.Id.Hex(),
+		"event":      delivery.EventType,
+		"statusCode": attempt.StatusCode,
+		"status":     delivery.Status,
+	}).Info("webhook delivery attempted")
+
+	return d.WebhookDeliveryDbHandler.Finish(ctx, delivery, attempt)
+}
+
+func (d *Deliverer) statusAfter(attempts int, delivered bool) string {
+	if delivered {
+		return db.DeliveryStatusDelivered
+	}
+	if attempts >= d.MaxAttempts {
+		return db.DeliveryStatusDead
+	}
+	return db.DeliveryStatusPending
+}
+
+// Posts the payload and returns the status code of the response; any status other than 2xx is an error
+func (d *Deliverer) send(ctx context.Context, webhook db.WebhookDb, delivery db.WebhookDeliveryDb, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "article-management-service-webhook")
+	request.Header.Set(HeaderId, delivery.Id.Hex())
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderTimestamp, fmt.Sprint(now.Unix()))
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, now, body))
+
+	client := d.Client
+	if client == nil {
+		client = NewClient(defaultTimeout, false)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseSize))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("the receiver responded with %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+func (d *Deliverer) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
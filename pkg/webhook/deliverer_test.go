@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeliverer_Deliver(t *testing.T) {
+	now := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+	webhookId := primitive.NewObjectID()
+	failed := db.WebhookAttemptDb{Time: now, StatusCode: http.StatusInternalServerError}
+
+	tests := []struct {
+		name               string
+		receiverStatus     int
+		deleted            bool
+		previousAttempts   []db.WebhookAttemptDb
+		roundAttempts      int
+		expectedStatus     string
+		expectedNext       time.Time
+		expectedStatusCode int
+		expectedExpiry     bool
+	}{
+		{name: "Successfully deliver", receiverStatus: http.StatusNoContent, expectedStatus: db.DeliveryStatusDelivered, expectedStatusCode: http.StatusNoContent, expectedExpiry: true},
+		{name: "Successfully retry failed deliveries later", receiverStatus: http.StatusInternalServerError, previousAttempts: []db.WebhookAttemptDb{failed}, roundAttempts: 1, expectedStatus: db.DeliveryStatusPending, expectedNext: now.Add(20 * time.Second), expectedStatusCode: http.StatusInternalServerError},
+		{name: "Successfully give up after the maximum attempts", receiverStatus: http.StatusBadRequest, previousAttempts: []db.WebhookAttemptDb{failed, failed}, roundAttempts: 2, expectedStatus: db.DeliveryStatusDead, expectedStatusCode: http.StatusBadRequest, expectedExpiry: true},
+		{name: "Successfully retry a dead delivery with a new round of attempts", receiverStatus: http.StatusInternalServerError, previousAttempts: []db.WebhookAttemptDb{failed, failed, failed}, roundAttempts: 0, expectedStatus: db.DeliveryStatusPending, expectedNext: now.Add(10 * time.Second), expectedStatusCode: http.StatusInternalServerError},
+		{name: "Successfully give up on deleted webhooks", deleted: true, expectedStatus: db.DeliveryStatusDead, expectedExpiry: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *http.Request
+			var receivedBody []byte
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				receivedBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.receiverStatus)
+			}))
+			defer receiver.Close()
+
+			var finished db.WebhookDeliveryDb
+			var attempt db.WebhookAttemptDb
+			d := &Deliverer{
+				WebhookDbHandler: &mocks.MockWebhookDbHandler{FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.WebhookDb, error) {
+					if tt.deleted {
+						return nil, nil
+					}
+					return &db.WebhookDb{Id: id, Url: receiver.URL, Secret: "secret"}, nil
+				}},
+				WebhookDeliveryDbHandler: &mocks.MockWebhookDeliveryDbHandler{FinishFunc: func(ctx context.Context, delivery db.WebhookDeliveryDb, a db.WebhookAttemptDb) error {
+					finished, attempt = delivery, a
+					return nil
+				}},
+				// the receiver listens on the loopback
+				Client:      NewClient(time.Second, true),
+				MaxAttempts: 3,
+				BackoffBase: 10 * time.Second,
+				Retention:   time.Hour,
+				Now:         func() time.Time { return now },
+			}
+
+			delivery := db.WebhookDeliveryDb{
+				Id:            primitive.NewObjectID(),
+				WebhookId:     webhookId,
+				EventType:     db.ArticleEventCreated,
+				Payload:       `{"type":"created"}`,
+				Status:        db.DeliveryStatusPending,
+				Attempts:      tt.previousAttempts,
+				RoundAttempts: tt.roundAttempts,
+			}
+			if err := d.Deliver(context.Background(), delivery); err != nil {
+				t.Fatalf("Deliverer.Deliver() error = %v", err)
+			}
+
+			if finished.Status != tt.expectedStatus {
+				t.Errorf("Deliverer.Deliver() status = %v, want %v", finished.Status, tt.expectedStatus)
+			}
+			if attempt.StatusCode != tt.expectedStatusCode {
+				t.Errorf("Deliverer.Deliver() status code = %v, want %v", attempt.StatusCode, tt.expectedStatusCode)
+			}
+			if !tt.expectedNext.IsZero() && !finished.NextAttemptAt.Equal(tt.expectedNext) {
+				t.Errorf("Deliverer.Deliver() next attempt = %v, want %v", finished.NextAttemptAt, tt.expectedNext)
+			}
+			if !tt.deleted && finished.RoundAttempts != tt.roundAttempts+1 {
+				t.Errorf("Deliverer.Deliver() round attempts = %v, want %v", finished.RoundAttempts, tt.roundAttempts+1)
+			}
+			if (finished.ExpireAt != nil) != tt.expectedExpiry {
+				t.Errorf("Deliverer.Deliver() expireAt = %v, want expiry %v", finished.ExpireAt, tt.expectedExpiry)
+			}
+			if tt.deleted {
+				return
+			}
+
+			if received == nil {
+				t.Fatal("Deliverer.Deliver() did not post to the receiver")
+			}
+			if received.Header.Get(HeaderEvent) != db.ArticleEventCreated || received.Header.Get(HeaderId) != delivery.Id.Hex() {
+				t.Errorf("Deliverer.Deliver() headers = %v", received.Header)
+			}
+			timestamp, _ := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+			if !Verify("secret", time.Unix(timestamp, 0), receivedBody, received.Header.Get(HeaderSignature)) {
+				t.Errorf("Deliverer.Deliver() signature %v does not verify", received.Header.Get(HeaderSignature))
+			}
+		})
+	}
+}
+
+func TestDeliverer_DeliverDue(t *testing.T) {
+	due := []*db.WebhookDeliveryDb{{Id: primitive.NewObjectID(), WebhookId: primitive.NewObjectID()}, {Id: primitive.NewObjectID(), WebhookId: primitive.NewObjectID()}}
+	var finished int32
+
+	d := &Deliverer{
+		WebhookDeliveryDbHandler: &mocks.MockWebhookDeliveryDbHandler{
+			ClaimFunc: func(ctx context.Context, now time.Time, lease time.Duration, skipWebhookIds []primitive.ObjectID) (*db.WebhookDeliveryDb, error) {
+				if len(due) == 0 {
+					return nil, nil
+				}
+				delivery := due[0]
+				due = due[1:]
+				return delivery, nil
+			},
+			FinishFunc: func(ctx context.Context, delivery db.WebhookDeliveryDb, attempt db.WebhookAttemptDb) error {
+				atomic.AddInt32(&finished, 1)
+				return nil
+			},
+		},
+		WebhookDbHandler: &mocks.MockWebhookDbHandler{},
+	}
+
+	attempts, err := d.DeliverDue(context.Background())
+	if err != nil || attempts != 2 || finished != 2 {
+		t.Errorf("Deliverer.DeliverDue() = %v, %v, finished %v, want %v", attempts, err, finished, 2)
+	}
+}
+
+func TestDeliverer_DeliverDue_Concurrency(t *testing.T) {
+	slowId, fastId := primitive.NewObjectID(), primitive.NewObjectID()
+	release := make(chan struct{})
+	fastDelivered := make(chan struct{})
+	var slowInFlight, slowConcurrent int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			if atomic.AddInt32(&slowInFlight, 1) > 1 {
+				atomic.StoreInt32(&slowConcurrent, 1)
+			}
+			<-release
+			atomic.AddInt32(&slowInFlight, -1)
+		} else {
+			fastDelivered <- struct{}{}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	due := []db.WebhookDeliveryDb{{Id: primitive.NewObjectID(), WebhookId: slowId}, {Id: primitive.NewObjectID(), WebhookId: slowId}, {Id: primitive.NewObjectID(), WebhookId: fastId}}
+	d := &Deliverer{
+		WebhookDbHandler: &mocks.MockWebhookDbHandler{FindOneByIdFunc: func(ctx context.Context, id primitive.ObjectID) (*db.WebhookDb, error) {
+			path := "/fast"
+			if id == slowId {
+				path = "/slow"
+			}
+			return &db.WebhookDb{Id: id, Url: receiver.URL + path, Secret: "secret"}, nil
+		}},
+		WebhookDeliveryDbHandler: &mocks.MockWebhookDeliveryDbHandler{
+			ClaimFunc: func(ctx context.Context, now time.Time, lease time.Duration, skipWebhookIds []primitive.ObjectID) (*db.WebhookDeliveryDb, error) {
+				for i, delivery := range due {
+					skipped := false
+					for _, id := range skipWebhookIds {
+						if id == delivery.WebhookId {
+							skipped = true
+						}
+					}
+					if skipped {
+						continue
+					}
+					due = append(due[:i], due[i+1:]...)
+					return &delivery, nil
+				}
+				return nil, nil
+			},
+		},
+		Client:      NewClient(time.Second*5, true),
+		MaxAttempts: 3,
+	}
+
+	done := make(chan struct{})
+	var attempts int
+	var err error
+	go func() {
+		attempts, err = d.DeliverDue(context.Background())
+		close(done)
+	}()
+
+	// the fast webhook is delivered to while the slow one still has not responded
+	select {
+	case <-fastDelivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Deliverer.DeliverDue() did not deliver to the fast webhook while the slow one was busy")
+	}
+	close(release)
+	<-done
+
+	if err != nil || attempts != 3 {
+		t.Errorf("Deliverer.DeliverDue() = %v, %v, want %v", attempts, err, 3)
+	}
+	if atomic.LoadInt32(&slowConcurrent) != 0 {
+		t.Error("Deliverer.DeliverDue() delivered to the slow webhook twice at once")
+	}
+}
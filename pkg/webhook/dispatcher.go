@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/logging"
+	"article-management-service/pkg/tenant"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// how long the dispatcher waits before it watches the article events again after the change stream failed
+const defaultRestartDelay = 5 * time.Second
+
+// Payload is the body of a delivery
+type Payload struct {
+	Id        string `json:"id"` // the same for every attempt, so receivers can drop duplicates
+	Type      string `json:"type"`
+	TenantId  string `json:"tenantId"`
+	ArticleId string `json:"articleId"`
+	Title     string `json:"title,omitempty"`
+	Time      string `json:"time"`
+}
+
+// Dispatcher turns the article events of all tenants into pending deliveries for the subscribed webhooks.
+// It remembers the last handled event, so events that happen while the service is down are delivered later.
+type Dispatcher struct {
+	ArticleDbHandler         db.ArticleDbHandlerInterface
+	WebhookDbHandler         db.WebhookDbHandlerInterface
+	WebhookDeliveryDbHandler db.WebhookDeliveryDbHandlerInterface
+	Logger                   *logrus.Logger
+	RestartDelay             time.Duration // defaults to 5 seconds
+}
+
+// Run dispatches the article events until the context is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	delay := d.RestartDelay
+	if delay <= 0 {
+		delay = defaultRestartDelay
+	}
+
+	for {
+		err := d.dispatchEvents(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logging.OrDefault(d.Logger).WithError(err).Error("failed to dispatch the article events to the webhooks")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchEvents(ctx context.Context) error {
+	resumeAfter, err := d.WebhookDbHandler.LoadResumeToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	events, err := d.ArticleDbHandler.WatchAllTenants(ctx, resumeAfter)
+	if errors.Is(err, db.ErrResumeFailed) {
+		logging.OrDefault(d.Logger).WithError(err).Warn("events since the last dispatched event are lost, dispatching from now on")
+		events, err = d.ArticleDbHandler.WatchAllTenants(ctx, "")
+	}
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		if err := d.Dispatch(ctx, event); err != nil {
+			return err
+		}
+		if err := d.WebhookDbHandler.SaveResumeToken(ctx, event.Id); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return errors.New("the article events stopped")
+}
+
+// Dispatch enqueues a delivery of the event for every webhook of its tenant that subscribed to its type
+func (d *Dispatcher) Dispatch(ctx context.Context, event db.ArticleEvent) error {
+	ctx = tenant.WithTenant(ctx, event.TenantId)
+
+	webhooks, err := d.WebhookDbHandler.FindByEvent(ctx, event.Type)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(Payload{
+		Id:        event.Id,
+		Type:      event.Type,
+		TenantId:  event.TenantId,
+		ArticleId: event.ArticleId.Hex(),
+		Title:     event.Title,
+		Time:      event.Time.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		delivery := db.WebhookDeliveryDb{
+			WebhookId: webhook.Id,
+			EventId:   event.Id,
+			EventType: event.Type,
+			Payload:   string(payload),
+		}
+		if err := d.WebhookDeliveryDbHandler.Enqueue(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
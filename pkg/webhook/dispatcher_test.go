@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"article-management-service/pkg/db"
+	"article-management-service/pkg/mocks"
+	"article-management-service/pkg/tenant"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	event := db.ArticleEvent{
+		Id:        "token",
+		TenantId:  "teamA",
+		Type:      db.ArticleEventCreated,
+		ArticleId: primitive.NewObjectID(),
+		Title:     "Title",
+		Time:      time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC),
+	}
+	webhooks := []db.WebhookDb{{Id: primitive.NewObjectID()}, {Id: primitive.NewObjectID()}}
+
+	var foundTenant, foundType string
+	var enqueued []db.WebhookDeliveryDb
+	d := &Dispatcher{
+		WebhookDbHandler: &mocks.MockWebhookDbHandler{FindByEventFunc: func(ctx context.Context, eventType string) ([]db.WebhookDb, error) {
+			foundTenant, foundType = tenant.FromContext(ctx), eventType
+			return webhooks, nil
+		}},
+		WebhookDeliveryDbHandler: &mocks.MockWebhookDeliveryDbHandler{EnqueueFunc: func(ctx context.Context, new db.WebhookDeliveryDb) error {
+			if tenant.FromContext(ctx) != "teamA" {
+				t.Errorf("Dispatcher.Dispatch() enqueued for tenant %v, want %v", tenant.FromContext(ctx), "teamA")
+			}
+			enqueued = append(enqueued, new)
+			return nil
+		}},
+	}
+
+	if err := d.Dispatch(context.Background(), event); err != nil {
+		t.Fatalf("Dispatcher.Dispatch() error = %v", err)
+	}
+	if foundTenant != "teamA" || foundType != db.ArticleEventCreated {
+		t.Errorf("Dispatcher.Dispatch() found webhooks of %v/%v, want %v/%v", foundTenant, foundType, "teamA", db.ArticleEventCreated)
+	}
+	if len(enqueued) != len(webhooks) {
+		t.Fatalf("Dispatcher.Dispatch() enqueued %v deliveries, want %v", len(enqueued), len(webhooks))
+	}
+
+	var payload Payload
+	if err := json.Unmarshal([]byte(enqueued[0].Payload), &payload); err != nil {
+		t.Fatalf("Dispatcher.Dispatch() payload error = %v", err)
+	}
+	expected := Payload{Id: "token", Type: db.ArticleEventCreated, TenantId: "teamA", ArticleId: event.ArticleId.Hex(), Title: "Title", Time: "2023-11-30T00:00:00Z"}
+	if payload != expected {
+		t.Errorf("Dispatcher.Dispatch() payload = %v, want %v", payload, expected)
+	}
+	if enqueued[1].WebhookId != webhooks[1].Id || enqueued[1].EventId != "token" {
+		t.Errorf("Dispatcher.Dispatch() delivery = %v", enqueued[1])
+	}
+}
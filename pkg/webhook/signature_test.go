@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"created"}`)
+	signature := Sign("secret", timestamp, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp time.Time
+		body      []byte
+		expected  bool
+	}{
+		{name: "Successfully verify the signature", secret: "secret", timestamp: timestamp, body: body, expected: true},
+		{name: "Prevent verifying with another secret", secret: "other", timestamp: timestamp, body: body, expected: false},
+		{name: "Prevent verifying a replayed timestamp", secret: "secret", timestamp: timestamp.Add(time.Second), body: body, expected: false},
+		{name: "Prevent verifying a changed body", secret: "secret", timestamp: timestamp, body: []byte(`{"type":"deleted"}`), expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, tt.body, signature); got != tt.expected {
+				t.Errorf("Verify() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  int
+		max      time.Duration
+		expected time.Duration
+	}{
+		{name: "Successfully wait the base after the first attempt", attempt: 1, expected: 10 * time.Second},
+		{name: "Successfully double the delay for each attempt", attempt: 4, expected: 80 * time.Second},
+		{name: "Successfully cap the delay", attempt: 10, max: time.Minute, expected: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Backoff(tt.attempt, 10*time.Second, tt.max); got != tt.expected {
+				t.Errorf("Backoff() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrInvalidTarget is a webhook url that is not an absolute http or https url
+	ErrInvalidTarget = errors.New("the url is not an absolute http or https url")
+	// ErrPrivateTarget is a webhook url that resolves to a private, loopback or link-local address; the service
+	// must not be used to reach its own network
+	ErrPrivateTarget = errors.New("the url resolves to a private, loopback or link-local address")
+	// ErrRedirect is a receiver that responds with a redirect; redirects are not followed
+	ErrRedirect = errors.New("the receiver responded with a redirect")
+)
+
+// Resolver resolves the host of a webhook url; implemented by net.Resolver
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// IsPublic reports whether the address may be the target of a webhook
+func IsPublic(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// CheckTarget rejects urls that are not absolute http or https urls and, unless private targets are allowed,
+// urls whose host resolves to an address that is not public. The deliveries check the address again when they
+// connect, as the host may resolve differently by then.
+func CheckTarget(ctx context.Context, resolver Resolver, rawUrl string, allowPrivate bool) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidTarget
+	}
+	if allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublic(ip) {
+			return ErrPrivateTarget
+		}
+		return nil
+	}
+
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !IsPublic(addr.IP) {
+			return ErrPrivateTarget
+		}
+	}
+	return nil
+}
+
+// NewClient returns the client the deliveries are sent with. It does not follow redirects and, unless private
+// targets are allowed, refuses to connect to addresses that are not public; proxies are not used, as they would
+// connect in its place.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer.Control = publicOnly
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return ErrRedirect
+		},
+	}
+}
+
+// Checks the resolved address right before connecting, so a host can not resolve to a private address after
+// it was checked
+func publicOnly(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublic(ip) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
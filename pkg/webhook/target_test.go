@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeResolver map[string]string
+
+func (r fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ip, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+}
+
+func TestCheckTarget(t *testing.T) {
+	resolver := fakeResolver{"example.com": "93.184.216.34", "internal.example.com": "10.0.0.7"}
+
+	tests := []struct {
+		name         string
+		url          string
+		allowPrivate bool
+		expectedErr  error
+	}{
+		{name: "Successfully check a public host", url: "https://example.com/hook"},
+		{name: "Successfully check a public address", url: "http://93.184.216.34:8080/hook"},
+		{name: "Successfully allow private targets", url: "http://localhost:8080/hook", allowPrivate: true},
+		{name: "Prevent other schemes", url: "ftp://example.com/hook", expectedErr: ErrInvalidTarget},
+		{name: "Prevent relative urls", url: "/hook", expectedErr: ErrInvalidTarget},
+		{name: "Prevent hosts resolving to private addresses", url: "https://internal.example.com/hook", expectedErr: ErrPrivateTarget},
+		{name: "Prevent loopback addresses", url: "http://127.0.0.1/hook", expectedErr: ErrPrivateTarget},
+		{name: "Prevent ipv6 loopback addresses", url: "http://[::1]/hook", expectedErr: ErrPrivateTarget},
+		{name: "Prevent link-local addresses", url: "http://169.254.169.254/latest/meta-data", expectedErr: ErrPrivateTarget},
+		{name: "Prevent unspecified addresses", url: "http://0.0.0.0/hook", expectedErr: ErrPrivateTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckTarget(context.Background(), resolver, tt.url, tt.allowPrivate); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("CheckTarget() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/hook", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		allowPrivate bool
+		expectedErr  error
+	}{
+		{name: "Successfully post to allowed private targets", path: "/hook", allowPrivate: true},
+		{name: "Prevent connecting to private addresses", path: "/hook", expectedErr: ErrPrivateTarget},
+		{name: "Prevent following redirects", path: "/redirect", allowPrivate: true, expectedErr: ErrRedirect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(time.Second, tt.allowPrivate)
+			response, err := client.Post(receiver.URL+tt.path, "application/json", bytes.NewReader([]byte("{}")))
+			if err == nil {
+				response.Body.Close()
+			}
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("NewClient().Post() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
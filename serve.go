@@ -0,0 +1,348 @@
+package main
+
+import (
+	"article-management-service/pkg/articlepb"
+	"article-management-service/pkg/auth"
+	"article-management-service/pkg/authz"
+	"article-management-service/pkg/controller"
+	db "article-management-service/pkg/db"
+	"article-management-service/pkg/env"
+	"article-management-service/pkg/jobs"
+	"article-management-service/pkg/middleware"
+	"article-management-service/pkg/openapi"
+	"article-management-service/pkg/ratelimit"
+	"article-management-service/pkg/router"
+	"article-management-service/pkg/server"
+	"article-management-service/pkg/tenant"
+	"article-management-service/pkg/tracing"
+	"article-management-service/pkg/webhook"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// Serves the REST, JSON-RPC, GraphQL and gRPC APIs and runs the background jobs until SIGINT or SIGTERM
+func serve(cfg *env.Config, logger *logrus.Logger, args []string) error {
+	if err := flag.NewFlagSet("serve", flag.ExitOnError).Parse(args); err != nil {
+		return err
+	}
+	if !db.IsValidExpiryPolicy(cfg.ExpiryPolicy) {
+		return fmt.Errorf("invalid expiry policy %q", cfg.ExpiryPolicy)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TraceExporter, cfg.TraceFile)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.WithError(err).Warn("failed to flush traces")
+		}
+	}()
+
+	uri := cfg.MongoUri
+	if uri == "" {
+		mm := db.MockMongo{Logger: logger, Replica: cfg.EventsEnabled}
+		uri, err = mm.HostMemoryDb(cfg.MongodPath)
+		if err != nil {
+			return fmt.Errorf("failed to host memory db: %w", err)
+		}
+		defer mm.Close()
+	}
+
+	conn := db.Connection{Logger: logger}
+	err = conn.Connect(uri)
+	if err != nil {
+		return fmt.Errorf("failed to connect to db: %w", err)
+	}
+	defer conn.Close()
+
+	// articles created before expiry policies get the one of the deployment
+	migrator := &db.Migrator{Logger: logger, Migrations: db.Migrations(cfg.ExpiryPolicy)}
+	if err := migrator.New(conn.Database); err != nil {
+		return fmt.Errorf("failed to set up the migrations: %w", err)
+	}
+	if cfg.MigrateOnStart {
+		if _, err := migrator.Up(context.Background(), 0); err != nil {
+			return fmt.Errorf("failed to migrate the db: %w", err)
+		}
+	} else if pending, err := migrator.Pending(context.Background()); err != nil {
+		return fmt.Errorf("failed to find the pending migrations: %w", err)
+	} else if len(pending) > 0 {
+		return fmt.Errorf("the db is not migrated, %d migrations are pending; run the migrate command", len(pending))
+	}
+
+	engine := gin.New()
+	engine.Use(middleware.RequestId(), middleware.Tracing(), middleware.Logger(logger), gin.Recovery())
+
+	engine.SetTrustedProxies(nil)
+
+	dbHandler := &db.ArticleDbHandler{Logger: logger}
+	err = dbHandler.New(conn.Database)
+	if err != nil {
+		return fmt.Errorf("failed to create the articles collection: %w", err)
+	}
+
+	auditDbHandler := &db.AuditDbHandler{Logger: logger}
+	if err := auditDbHandler.New(conn.Database); err != nil {
+		return fmt.Errorf("failed to create the audit collection: %w", err)
+	}
+
+	revisionDbHandler := &db.RevisionDbHandler{Logger: logger}
+	if err := revisionDbHandler.New(conn.Database); err != nil {
+		return fmt.Errorf("failed to create the revisions collection: %w", err)
+	}
+
+	quotas, err := tenant.ParseQuotas(tenant.Quota{MaxArticles: cfg.TenantMaxArticles, MaxImages: cfg.TenantMaxImages}, cfg.TenantQuotas)
+	if err != nil {
+		return fmt.Errorf("failed to parse the tenant quotas: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.EventsEnabled {
+		if err := dbHandler.EnableChangeEvents(ctx); err != nil {
+			logger.WithError(err).Warn("failed to record articles before deletion; expired articles are not announced")
+		}
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	articleController := &controller.ArticleController{
+		ArticleDbHandler:   dbHandler,
+		AuditDbHandler:     auditDbHandler,
+		RevisionDbHandler:  revisionDbHandler,
+		ImageDirectory:     cfg.ImageDirectory,
+		GenerateIdentifier: func() string { return uuid.New().String() },
+		Validate:           validate,
+		Logger:             logger,
+		Quotas:             quotas,
+		ExpiryPolicy:       cfg.ExpiryPolicy,
+		RenewMaxHorizon:    cfg.RenewMaxHorizon,
+		RenewMaxRenewals:   cfg.RenewMaxRenewals,
+		EventsDone:         ctx.Done(),
+	}
+	if cfg.EventsEnabled {
+		articleController.ArticleEvents = &db.ArticleEventHub{ArticleDbHandler: dbHandler, MaxResumeStreams: cfg.EventsMaxResumeStreams}
+	}
+
+	unversioned := &router.Deprecation{Date: cfg.UnversionedDeprecation, Sunset: cfg.UnversionedSunset}
+	router := router.NewRouter(articleController, engine)
+	router.Unversioned = unversioned
+	router.AuditCtrl = &controller.AuditController{AuditDbHandler: auditDbHandler, Logger: logger}
+	router.Events = cfg.EventsEnabled
+	rpcController := &controller.RpcController{ArticleController: articleController, Logger: logger, CredentialsRequired: cfg.AuthEnabled}
+	router.RpcCtrl = rpcController
+	graphqlController := &controller.GraphqlController{
+		ArticleController:   articleController,
+		Logger:              logger,
+		MaxDepth:            cfg.GraphqlMaxDepth,
+		MaxComplexity:       cfg.GraphqlMaxComplexity,
+		MaxMutations:        cfg.GraphqlMaxMutations,
+		CredentialsRequired: cfg.AuthEnabled,
+	}
+	router.GraphqlCtrl = graphqlController
+
+	// webhooks are delivered from the article events
+	var dispatcher *webhook.Dispatcher
+	var deliverer *webhook.Deliverer
+	if cfg.EventsEnabled && cfg.WebhooksEnabled {
+		webhookDbHandler := &db.WebhookDbHandler{Logger: logger}
+		if err := webhookDbHandler.New(conn.Database); err != nil {
+			return fmt.Errorf("failed to create the webhooks collection: %w", err)
+		}
+
+		webhookDeliveryDbHandler := &db.WebhookDeliveryDbHandler{Logger: logger}
+		if err := webhookDeliveryDbHandler.New(conn.Database); err != nil {
+			return fmt.Errorf("failed to create the webhook deliveries collection: %w", err)
+		}
+
+		router.WebhookCtrl = &controller.WebhookController{
+			WebhookDbHandler:         webhookDbHandler,
+			WebhookDeliveryDbHandler: webhookDeliveryDbHandler,
+			Validate:                 validate,
+			AllowPrivateTargets:      cfg.WebhookAllowPrivate,
+			Logger:                   logger,
+		}
+		dispatcher = &webhook.Dispatcher{
+			ArticleDbHandler:         dbHandler,
+			WebhookDbHandler:         webhookDbHandler,
+			WebhookDeliveryDbHandler: webhookDeliveryDbHandler,
+			Logger:                   logger,
+		}
+		deliverer = &webhook.Deliverer{
+			WebhookDbHandler:         webhookDbHandler,
+			WebhookDeliveryDbHandler: webhookDeliveryDbHandler,
+			Client:                   webhook.NewClient(cfg.WebhookTimeout, cfg.WebhookAllowPrivate),
+			MaxAttempts:              cfg.WebhookMaxAttempts,
+			Concurrency:              cfg.WebhookConcurrency,
+			BackoffBase:              cfg.WebhookBackoffBase,
+			BackoffMax:               cfg.WebhookBackoffMax,
+			Retention:                cfg.WebhookRetention,
+			Logger:                   logger,
+		}
+	}
+
+	var limiter *ratelimit.Limiter
+	readBudget := ratelimit.Budget{Name: "read", PerMinute: cfg.RateLimitReadPerMinute, Burst: cfg.RateLimitReadBurst}
+	createBudget := ratelimit.Budget{Name: "create", PerMinute: cfg.RateLimitCreatePerMinute, Burst: cfg.RateLimitCreateBurst}
+	uploadBudget := ratelimit.Budget{Name: "upload", PerMinute: cfg.RateLimitUploadPerMinute, Burst: cfg.RateLimitUploadBurst}
+	if cfg.RateLimitEnabled {
+		var store ratelimit.Store = &ratelimit.MemoryStore{}
+		if cfg.RateLimitStore == "mongo" {
+			rateLimitDbHandler := &db.RateLimitDbHandler{Logger: logger}
+			if err := rateLimitDbHandler.New(conn.Database); err != nil {
+				return fmt.Errorf("failed to create the rate limit collection: %w", err)
+			}
+			store = rateLimitDbHandler
+		}
+
+		limiter = &ratelimit.Limiter{Store: store, KeyBy: cfg.RateLimitKeyBy, Logger: logger}
+		router.Middleware.Read = append(router.Middleware.Read, limiter.Middleware(readBudget))
+		router.Middleware.Admin = append(router.Middleware.Admin, limiter.Middleware(readBudget))
+		router.Middleware.Create = append(router.Middleware.Create, limiter.Middleware(createBudget))
+		router.Middleware.Modify = append(router.Middleware.Modify, limiter.Middleware(createBudget))
+		router.Middleware.Upload = append(router.Middleware.Upload, limiter.Middleware(uploadBudget))
+
+		// the calls of /rpc and the mutations of /graphql are charged one by one
+		budgets := map[string]ratelimit.Budget{
+			controller.BudgetRead:   readBudget,
+			controller.BudgetCreate: createBudget,
+			controller.BudgetUpload: uploadBudget,
+		}
+		limitCall := func(ctx context.Context, budget string, clientIp string) (bool, int) {
+			return limiter.Allow(ctx, budgets[budget], limiter.ClientKey(ctx, clientIp))
+		}
+		rpcController.Limit = limitCall
+		articleController.Limit = limitCall
+		graphqlController.Limit = limitCall
+	}
+
+	// the authenticator is also needed without authentication, as the audit log and the webhooks require credentials
+	authenticator, err := newAuthenticator(cfg.AuthApiKeysEnabled, cfg.JWTHMACSecret, cfg.JWTRSAPublicKeyFile, cfg.JWTIssuer, cfg.JWTAudience, conn, logger)
+	if err != nil {
+		if cfg.AuthEnabled {
+			return fmt.Errorf("failed to set up authentication: %w", err)
+		}
+		logger.WithError(err).Warn("no credentials can be checked; the audit log and the webhooks reject every request")
+	}
+	if authenticator != nil {
+		for _, role := range cfg.AuthDefaultRoles {
+			if !authz.IsValidRole(role) {
+				return fmt.Errorf("invalid default role %q", role)
+			}
+		}
+		authenticator.DefaultRoles = cfg.AuthDefaultRoles
+		if limiter != nil {
+			// requests with invalid credentials are rejected before the limits of the routes
+			authenticator.OnInvalidCredentials = limiter.ChargeIp(readBudget)
+		}
+		engine.Use(authenticator.Authenticate())
+	}
+	router.Middleware.Admin = append(router.Middleware.Admin, auth.Required())
+
+	if cfg.AuthEnabled {
+		// reads stay public; changing articles requires credentials
+		router.Middleware.Create = append(router.Middleware.Create, auth.Required())
+		router.Middleware.Upload = append(router.Middleware.Upload, auth.Required())
+		router.Middleware.Modify = append(router.Middleware.Modify, auth.Required())
+	} else {
+		// anonymous callers may only act when authentication is disabled explicitly
+		engine.Use(auth.Disabled())
+	}
+
+	engine.Use(middleware.Tenant(cfg.TenantHeaderEnabled))
+
+	router.Secured = cfg.AuthEnabled
+	if cfg.OpenApiValidation {
+		router.Validator = &openapi.Validator{Logger: logger, ValidateResponses: cfg.OpenApiValidateResponses}
+	}
+	if err := router.Init(); err != nil {
+		return fmt.Errorf("failed to add the routes: %w", err)
+	}
+
+	srv := &server.Server{
+		Handler:       engine,
+		Addr:          cfg.ListenAddr,
+		PlainHTTPAddr: cfg.PlainHTTPListenAddr,
+		PlainHTTPMode: cfg.PlainHTTPMode,
+		Logger:        logger,
+	}
+	if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+		srv.TLS = &server.TLSConfig{
+			CertFile:       cfg.TLSCertFile,
+			KeyFile:        cfg.TLSKeyFile,
+			ClientCAFile:   cfg.TLSClientCAFile,
+			ClientAuth:     cfg.TLSClientAuth,
+			ReloadInterval: cfg.TLSReloadInterval,
+		}
+	}
+
+	purger := &jobs.Purger{ArticleDbHandler: dbHandler, RevisionDbHandler: revisionDbHandler, AuditDbHandler: auditDbHandler, Retention: cfg.TrashRetention, Logger: logger}
+	go purger.Run(ctx, cfg.TrashPurgeInterval)
+
+	archiver := &jobs.Archiver{ArticleDbHandler: dbHandler, AuditDbHandler: auditDbHandler, Logger: logger}
+	go archiver.Run(ctx, cfg.ExpiryArchiveInterval)
+
+	if dispatcher != nil {
+		go dispatcher.Run(ctx)
+		go deliverer.Run(ctx, cfg.WebhookPollInterval)
+	}
+
+	var grpcStopped sync.WaitGroup
+	if cfg.GrpcListenAddr != "" {
+		interceptors := &middleware.Grpc{Authenticator: authenticator, AuthDisabled: !cfg.AuthEnabled, TenantHeaderAllowed: cfg.TenantHeaderEnabled, Logger: logger}
+		if cfg.AuthEnabled {
+			// like the create and upload routes
+			interceptors.Required = map[string]bool{
+				articlepb.ArticleService_CreateArticle_FullMethodName: true,
+				articlepb.ArticleService_UploadImage_FullMethodName:   true,
+			}
+		}
+		if limiter != nil {
+			// the same budgets as the REST routes
+			interceptors.Limiter = limiter
+			interceptors.Budgets = map[string]ratelimit.Budget{
+				articlepb.ArticleService_GetArticle_FullMethodName:    readBudget,
+				articlepb.ArticleService_ListArticles_FullMethodName:  readBudget,
+				articlepb.ArticleService_CreateArticle_FullMethodName: createBudget,
+				articlepb.ArticleService_UploadImage_FullMethodName:   uploadBudget,
+			}
+		}
+		grpcServer := &server.GrpcServer{
+			Addr: cfg.GrpcListenAddr,
+			Register: func(s *grpc.Server) {
+				articlepb.RegisterArticleServiceServer(s, &controller.GrpcController{ArticleController: articleController, Logger: logger})
+			},
+			Options: []grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors.Unary()), grpc.ChainStreamInterceptor(interceptors.Stream())},
+			TLS:     srv.TLS,
+			Logger:  logger,
+		}
+		grpcStopped.Add(1)
+		go func() {
+			defer grpcStopped.Done()
+			if err := grpcServer.Run(ctx); err != nil {
+				logger.WithError(err).Error("gRPC server stopped")
+				stop()
+			}
+		}()
+	}
+
+	err = srv.Run(ctx)
+	stop()
+	grpcStopped.Wait()
+	if err != nil {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	return nil
+}
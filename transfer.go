@@ -0,0 +1,187 @@
+package main
+
+import (
+	"article-management-service/pkg/controller"
+	db "article-management-service/pkg/db"
+	"article-management-service/pkg/env"
+	"article-management-service/pkg/tenant"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Imports the articles of a file into a tenant and prints the report as JSON
+func importArticles(cfg *env.Config, logger *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	tenantId := flags.String("tenant", tenant.Default, "tenant to import the articles into")
+	dryRun := flags.Bool("dry-run", false, "validate the articles without creating them")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: import [flags] FILE.ndjson|FILE.jsonl|FILE.csv|FILE.zip")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected one file")
+	}
+	ctx, err := tenantContext(*tenantId)
+	if err != nil {
+		return err
+	}
+
+	conn, err := connect(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	articleController, err := newArticleController(cfg, logger, conn)
+	if err != nil {
+		return err
+	}
+	report, err := articleController.ImportFile(ctx, flags.Arg(0), *dryRun)
+	if report == nil {
+		return err
+	}
+
+	// the report tells the articles imported before a failure as well
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if report.Error != "" {
+		return errors.New(report.Error)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d articles failed", report.Failed)
+	}
+	return nil
+}
+
+// Exports the articles of a tenant to a file or stdout
+func exportArticles(cfg *env.Config, logger *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "file to write to instead of stdout; its extension is the default format")
+	format := flags.String("format", "", "ndjson, csv or zip (with images); defaults to ndjson")
+	tenantId := flags.String("tenant", tenant.Default, "tenant to export the articles of")
+	withImages := flags.String("with-images", "", "true to only export articles with images, false to only export articles without")
+	expiresFrom := flags.String("expires-from", "", "only export articles that expire at or after the RFC 3339 time")
+	expiresTo := flags.String("expires-to", "", "only export articles that expire before the RFC 3339 time")
+	includeExpired := flags.Bool("include-expired", false, "also export expired articles")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("unexpected arguments")
+	}
+	ctx, err := tenantContext(*tenantId)
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*output)), ".")
+		if *format == "" {
+			*format = controller.ExportFormatNDJSON
+		}
+	}
+	filter := db.ExportFilter{IncludeExpired: *includeExpired}
+	if *withImages != "" {
+		with := *withImages == "true"
+		if !with && *withImages != "false" {
+			return fmt.Errorf("invalid -with-images %q", *withImages)
+		}
+		filter.WithImage = &with
+	}
+	for _, bound := range []struct {
+		value string
+		into  *time.Time
+	}{{*expiresFrom, &filter.ExpiresFrom}, {*expiresTo, &filter.ExpiresTo}} {
+		if bound.value == "" {
+			continue
+		}
+		if *bound.into, err = time.Parse(time.RFC3339, bound.value); err != nil {
+			return err
+		}
+	}
+
+	conn, err := connect(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	articleController, err := newArticleController(cfg, logger, conn)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return articleController.ExportTo(ctx, os.Stdout, *format, filter)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := articleController.ExportTo(ctx, file, *format, filter); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// The context the commands act on a tenant with
+func tenantContext(tenantId string) (context.Context, error) {
+	if !tenant.IsValid(tenantId) {
+		return nil, fmt.Errorf("%w %q", tenant.ErrInvalid, tenantId)
+	}
+	return tenant.WithTenant(context.Background(), tenantId), nil
+}
+
+// The article controller of the import and export commands, configured like the one of the server
+func newArticleController(cfg *env.Config, logger *logrus.Logger, conn *db.Connection) (*controller.ArticleController, error) {
+	if !db.IsValidExpiryPolicy(cfg.ExpiryPolicy) {
+		return nil, fmt.Errorf("invalid expiry policy %q", cfg.ExpiryPolicy)
+	}
+	quotas, err := tenant.ParseQuotas(tenant.Quota{MaxArticles: cfg.TenantMaxArticles, MaxImages: cfg.TenantMaxImages}, cfg.TenantQuotas)
+	if err != nil {
+		return nil, err
+	}
+
+	dbHandler := &db.ArticleDbHandler{Logger: logger}
+	if err := dbHandler.New(conn.Database); err != nil {
+		return nil, err
+	}
+	auditDbHandler := &db.AuditDbHandler{Logger: logger}
+	if err := auditDbHandler.New(conn.Database); err != nil {
+		return nil, err
+	}
+
+	return &controller.ArticleController{
+		ArticleDbHandler:   dbHandler,
+		AuditDbHandler:     auditDbHandler,
+		ImageDirectory:     cfg.ImageDirectory,
+		GenerateIdentifier: func() string { return uuid.New().String() },
+		Validate:           validator.New(validator.WithRequiredStructEnabled()),
+		Logger:             logger,
+		Quotas:             quotas,
+		ExpiryPolicy:       cfg.ExpiryPolicy,
+	}, nil
+}